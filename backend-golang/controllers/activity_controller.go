@@ -5,9 +5,9 @@ import (
 	"log"
 	"net/http"
 	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
 	"santrikoding/backend-api/models"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -46,7 +46,7 @@ func GetRecentActivities(c *gin.Context) {
 		}
 	}
 
-	// Cek apakah user adalah Super Admin
+	// Ambil data user
 	var user models.User
 	if err := database.DB.Preload("Role").First(&user, userID).Error; err != nil {
 		log.Printf("Error fetching user: %v", err)
@@ -60,16 +60,11 @@ func GetRecentActivities(c *gin.Context) {
 
 	// Ambil semua activities, lalu filter berdasarkan:
 	// 1. Activity dari event yang user ikuti (sebagai panitia atau peserta) - UNTUK USER BIASA
-	// 2. Activity dari SEMUA event aktif (status != "done") - UNTUK SUPER ADMIN
+	// 2. Activity dari SEMUA event aktif (status != "done") - UNTUK PERMISSION activity.view_all
 	var activities []models.Activity
 
-	// Cek apakah user adalah Super Admin
-	// Berdasarkan tabel roles: code = "superadmin", name = "Super Administrator"
-	roleCodeLower := strings.ToLower(user.Role.Code)
-	roleNameUpper := strings.ToUpper(user.Role.Name)
-	isSuperAdmin := roleCodeLower == "superadmin" ||
-		strings.Contains(roleNameUpper, "SUPER ADMIN") ||
-		strings.Contains(roleNameUpper, "SUPERADMIN")
+	// User dengan permission activity.view_all melihat aktivitas semua event aktif
+	isSuperAdmin := helpers.HasPermission(userID, "activity.view_all")
 
 	if isSuperAdmin {
 		// SUPER ADMIN: Tampilkan semua activity dari event aktif (status != "done")
@@ -140,20 +135,8 @@ func GetRecentActivities(c *gin.Context) {
 	})
 }
 
-// GetAllActivities mengambil semua aktivitas (khusus superadmin)
+// GetAllActivities mengambil semua aktivitas (permission activity.view_all)
 func GetAllActivities(c *gin.Context) {
-	// Cek superadmin
-	userID, _ := c.Get("id")
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"message": "Akses ditolak. Hanya superadmin yang bisa mengakses.",
-		})
-		return
-	}
-
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset := (page - 1) * limit
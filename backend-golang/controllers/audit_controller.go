@@ -40,25 +40,19 @@ func CreateAuditLog(userID uint, action, entityType string, entityID uint, oldVa
 		Description: description,
 	}
 
-	if err := database.DB.Create(&auditLog).Error; err != nil {
+	// userID 0 = aksi tanpa user yang dikenal (misal login gagal), disimpan sebagai NULL
+	query := database.DB
+	if userID == 0 {
+		query = query.Omit("UserID")
+	}
+
+	if err := query.Create(&auditLog).Error; err != nil {
 		log.Printf("Error creating audit log: %v", err)
 	}
 }
 
 // GetAuditLogs mengambil semua audit logs dengan pagination
 func GetAuditLogs(c *gin.Context) {
-	// Cek superadmin
-	userID, _ := c.Get("id")
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak. Hanya superadmin yang bisa mengakses.",
-		})
-		return
-	}
-
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset := (page - 1) * limit
@@ -108,18 +102,6 @@ func GetAuditLogs(c *gin.Context) {
 
 // GetAuditLogStats mendapatkan statistik audit log
 func GetAuditLogStats(c *gin.Context) {
-	// Cek superadmin
-	userID, _ := c.Get("id")
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak",
-		})
-		return
-	}
-
 	// Statistik per action
 	var actionStats []struct {
 		Action string `json:"action"`
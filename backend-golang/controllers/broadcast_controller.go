@@ -15,18 +15,6 @@ import (
 
 // GetBroadcasts mengambil semua broadcast (untuk admin)
 func GetBroadcasts(c *gin.Context) {
-	// Cek superadmin
-	userID, _ := c.Get("id")
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak. Hanya superadmin yang bisa mengakses.",
-		})
-		return
-	}
-
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset := (page - 1) * limit
@@ -62,7 +50,7 @@ func GetBroadcasts(c *gin.Context) {
 
 // CreateBroadcast membuat broadcast baru
 func CreateBroadcast(c *gin.Context) {
-	// Cek superadmin
+	// Ambil user ID dari token
 	userIDInterface, _ := c.Get("id")
 	var userID uint
 	switch v := userIDInterface.(type) {
@@ -74,16 +62,6 @@ func CreateBroadcast(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak. Hanya superadmin yang bisa mengakses.",
-		})
-		return
-	}
-
 	var req struct {
 		Title      string `json:"title" binding:"required"`
 		Message    string `json:"message" binding:"required"`
@@ -286,18 +264,6 @@ func MarkBroadcastRead(c *gin.Context) {
 
 // DeleteBroadcast menghapus broadcast
 func DeleteBroadcast(c *gin.Context) {
-	// Cek superadmin
-	userID, _ := c.Get("id")
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak",
-		})
-		return
-	}
-
 	broadcastID := c.Param("id")
 
 	var broadcast models.Broadcast
@@ -325,18 +291,6 @@ func DeleteBroadcast(c *gin.Context) {
 
 // GetBroadcastStats mendapatkan statistik broadcast
 func GetBroadcastStats(c *gin.Context) {
-	// Cek superadmin
-	userID, _ := c.Get("id")
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak",
-		})
-		return
-	}
-
 	var totalBroadcasts int64
 	database.DB.Model(&models.Broadcast{}).Count(&totalBroadcasts)
 
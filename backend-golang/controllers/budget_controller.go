@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
 	"santrikoding/backend-api/models"
 	"santrikoding/backend-api/structs"
 	"time"
@@ -14,30 +15,6 @@ import (
 	"github.com/google/uuid"
 )
 
-// isBudgetManager checks if user can manage budget for a specific event
-// Returns true if:
-// 1. User is Super Admin (role_id 1) or Bendahara Umum (role_id 3), OR
-// 2. User is a committee member of the event
-func isBudgetManager(userID uint, eventID uint) bool {
-	var user models.User
-	if err := database.DB.Select("id", "role_id").First(&user, userID).Error; err != nil {
-		return false
-	}
-	
-	// Super Admin atau Bendahara Umum bisa manage semua budget
-	if user.RoleID == 1 || user.RoleID == 3 {
-		return true
-	}
-	
-	// Cek apakah user adalah panitia dari event ini
-	var committeeMember models.CommitteeMember
-	if err := database.DB.Where("event_id = ? AND user_id = ?", eventID, userID).First(&committeeMember).Error; err == nil {
-		return true
-	}
-	
-	return false
-}
-
 type CreateBudgetRequest struct {
 	Division   string  `form:"division" json:"division" binding:"required"`
 	ItemName   string  `form:"item_name" json:"item_name" binding:"required"`
@@ -118,10 +95,10 @@ func CreateBudget(c *gin.Context) {
 	}
 
 	// Cek permission setelah event ditemukan
-	if !isBudgetManager(userID, event.ID) {
+	if !helpers.HasEventPermission(userID, event.ID, "budget.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
-			Message: "Hanya Super Admin, Bendahara Umum, atau Panitia event yang dapat menambah anggaran",
+			Message: "Anda tidak memiliki izin untuk menambah anggaran event ini",
 			Errors:  map[string]string{"error": "forbidden"},
 		})
 		return
@@ -186,10 +163,10 @@ func UpdateBudget(c *gin.Context) {
 	}
 	
 	// Cek permission dengan eventID dari budget
-	if !isBudgetManager(userID, budget.EventID) {
+	if !helpers.HasEventPermission(userID, budget.EventID, "budget.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
-			Message: "Hanya Super Admin, Bendahara Umum, atau Panitia event yang dapat mengubah anggaran",
+			Message: "Anda tidak memiliki izin untuk mengubah anggaran event ini",
 			Errors:  map[string]string{"error": "forbidden"},
 		})
 		return
@@ -322,10 +299,11 @@ func DeleteBudget(c *gin.Context) {
 	}
 
 	// Cek permission dengan eventID dari budget
-	if !isBudgetManager(userID, budget.EventID) {
+	// Menolak anggaran butuh budget.approve (Bendahara Umum, atau Ketua/Bendahara panitia event)
+	if !helpers.HasEventPermission(userID, budget.EventID, "budget.approve") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
-			Message: "Hanya Super Admin, Bendahara Umum, atau Panitia event yang dapat menandai anggaran rejected",
+			Message: "Anda tidak memiliki izin untuk menolak anggaran event ini",
 			Errors:  map[string]string{"error": "forbidden"},
 		})
 		return
@@ -26,6 +26,12 @@ func UploadCertificate(c *gin.Context) {
 		return
 	}
 
+	// Syarat kehadiran sertifikat (certificate_min_attendance event)
+	if summary := helpers.GetAttendanceSummary(registration.Event, registration); !summary.CertificateEligible {
+		c.JSON(http.StatusBadRequest, gin.H{"message": certificateIneligibleMessage(summary)})
+		return
+	}
+
 	// 2. Handle Upload File
 	file, err := c.FormFile("certificate")
 	if err != nil {
@@ -55,7 +61,7 @@ func UploadCertificate(c *gin.Context) {
 	}
 
 	// 4. Generate nama file unik
-	filename := fmt.Sprintf("cert-%s-%d%s", registration.QRCode, time.Now().Unix(), ext)
+	filename := fmt.Sprintf("cert-%d-%d%s", registration.ID, time.Now().Unix(), ext)
 	savePath := filepath.Join(certDir, filename)
 
 	// 5. Simpan file
@@ -65,7 +71,7 @@ func UploadCertificate(c *gin.Context) {
 	}
 
 	// 6. Generate certificate code
-	certificateCode := fmt.Sprintf("CERT-%s-%s", registration.QRCode, uuid.New().String()[:8])
+	certificateCode := fmt.Sprintf("CERT-%d-%s", registration.ID, uuid.New().String()[:8])
 	certificateURL := fmt.Sprintf("http://localhost:8000/public/certificates/%s", filename)
 
 	// 7. Simpan atau update ke database
@@ -276,6 +282,8 @@ func UploadCertificatesBulk(c *gin.Context) {
 		return
 	}
 
+	attendance := helpers.GetAttendanceSummaries(event, participants)
+
 	// 3. Buat map email -> registration untuk lookup cepat
 	emailToRegistration := make(map[string]models.Registration)
 	for _, p := range participants {
@@ -355,6 +363,15 @@ func UploadCertificatesBulk(c *gin.Context) {
 			})
 			continue
 		}
+		if summary := attendance[registration.ID]; !summary.CertificateEligible {
+			results = append(results, Result{
+				Email:    email,
+				Filename: file.Filename,
+				Success:  false,
+				Message:  certificateIneligibleMessage(summary),
+			})
+			continue
+		}
 
 		// Validasi file type
 		ext := filepath.Ext(file.Filename)
@@ -391,7 +408,7 @@ func UploadCertificatesBulk(c *gin.Context) {
 		}
 
 		// Generate certificate code
-		certificateCode := fmt.Sprintf("CERT-%s-%s", registration.QRCode, uuid.New().String()[:8])
+		certificateCode := fmt.Sprintf("CERT-%d-%s", registration.ID, uuid.New().String()[:8])
 		certificateURL := fmt.Sprintf("http://localhost:8000/public/certificates/%s", saveFilename)
 
 		// Simpan atau update ke database
@@ -482,3 +499,9 @@ func UploadCertificatesBulk(c *gin.Context) {
 		},
 	})
 }
+
+// certificateIneligibleMessage menjelaskan kenapa peserta belum berhak menerima sertifikat
+func certificateIneligibleMessage(summary helpers.AttendanceSummary) string {
+	return fmt.Sprintf("Peserta belum memenuhi syarat sertifikat: hadir %d dari %d sesi (%d%%), minimal %d%%",
+		summary.AttendedSessions, summary.TotalSessions, summary.Percentage, summary.MinPercentage)
+}
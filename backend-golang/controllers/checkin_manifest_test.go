@@ -0,0 +1,55 @@
+package controllers_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tanda tangan manifest bisa diverifikasi scanner hanya dengan kunci publik yang dipublikasikan server
+func TestCheckInManifestSignatureVerifiesWithPublicKey(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	event := createEvent(t, organizer, 0, 10)
+	token := login(t, organizer)
+
+	code, body := doJSON(t, http.MethodGet, "/api/checkin-manifest/public-key", token, nil)
+	requireStatus(t, "kunci publik", code, http.StatusOK, body)
+	publicKey, err := base64.StdEncoding.DecodeString(body["data"].(map[string]interface{})["public_key"].(string))
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		t.Fatalf("kunci publik bukan Ed25519 base64: %v", body["data"])
+	}
+
+	// Manifest dibaca sebagai byte mentah: tanda tangan berlaku untuk JSON persis seperti yang dikirim
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/events/%d/checkin-manifest", event.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var response struct {
+		Data struct {
+			Manifest  json.RawMessage `json:"manifest"`
+			Signature string          `json:"signature"`
+		} `json:"data"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &response) != nil {
+		t.Fatalf("manifest: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	payload := []byte(response.Data.Manifest)
+	signature, err := base64.RawURLEncoding.DecodeString(response.Data.Signature)
+	if err != nil {
+		t.Fatalf("signature bukan base64url: %v", err)
+	}
+
+	if !ed25519.Verify(publicKey, payload, signature) {
+		t.Fatalf("tanda tangan manifest tidak valid untuk kunci publik server")
+	}
+	payload[len(payload)-2] ^= 1
+	if ed25519.Verify(publicKey, payload, signature) {
+		t.Fatalf("manifest yang diubah tetap lolos verifikasi")
+	}
+}
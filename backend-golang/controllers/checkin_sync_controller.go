@@ -0,0 +1,409 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxOfflineScanBatch membatasi jumlah scan per request sinkronisasi
+const maxOfflineScanBatch = 500
+
+type OfflineScanItem struct {
+	ClientScanID string    `json:"client_scan_id" binding:"required,max=100"`
+	QRCode       string    `json:"qr_code" binding:"required"`
+	ScannedAt    time.Time `json:"scanned_at" binding:"required"`
+	SessionID    *uint     `json:"session_id"` // Event multi-sesi: kosong = sesi yang berlangsung saat scan
+}
+
+type OfflineScanSyncRequest struct {
+	EventID  uint              `json:"event_id" binding:"required"`
+	DeviceID string            `json:"device_id" binding:"required,max=100"`
+	Scans    []OfflineScanItem `json:"scans" binding:"required,min=1,dive"`
+}
+
+type OfflineScanResult struct {
+	ClientScanID   string     `json:"client_scan_id"`
+	Result         string     `json:"result"`
+	Message        string     `json:"message"`
+	RegistrationID *uint      `json:"registration_id"`
+	AttendeeName   string     `json:"attendee_name,omitempty"`
+	CheckedInAt    *time.Time `json:"checked_in_at,omitempty"`
+	FirstDeviceID  string     `json:"first_device_id,omitempty"` // Perangkat yang lebih dulu meng-check-in (scan ganda)
+	Replayed       bool       `json:"replayed"`                  // true jika scan ini sudah pernah disinkronkan
+}
+
+// GET /api/events/:id/checkin-manifest
+// Manifest peserta untuk scanner offline: hash QR, nama peserta dan status registrasi, ditandatangani server
+// dengan Ed25519 (verifikasi memakai public_key, sama dengan GET /api/checkin-manifest/public-key)
+func GetCheckInManifest(c *gin.Context) {
+	event, ok := loadManagedEvent(c, c.Param("id"), "Hanya pembuat event atau panitia yang dapat mengunduh manifest check-in")
+	if !ok {
+		return
+	}
+	if event.EventType == "online" {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Event online tidak menggunakan scan QR untuk check-in",
+		})
+		return
+	}
+
+	var registrations []models.Registration
+	if err := database.DB.Preload("User").Preload("TicketType").
+		Where("event_id = ? AND status NOT IN ?", event.ID, models.ReleasedSeatStatuses).
+		Order("id ASC").Find(&registrations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengambil data peserta",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	var records []models.AttendanceRecord
+	database.DB.Select("registration_id", "event_session_id").Where("event_id = ?", event.ID).Find(&records)
+	attendedSessions := map[uint][]uint{}
+	for _, record := range records {
+		attendedSessions[record.RegistrationID] = append(attendedSessions[record.RegistrationID], record.EventSessionID)
+	}
+
+	manifest := helpers.CheckInManifest{
+		EventID:     event.ID,
+		EventTitle:  event.Title,
+		GeneratedAt: time.Now(),
+		ValidUntil:  helpers.TicketQRExpiry(*event),
+		Sessions:    helpers.GetEventSessions(event.ID),
+		Entries:     make([]helpers.CheckInManifestEntry, 0, len(registrations)),
+	}
+	for _, reg := range registrations {
+		entry := helpers.CheckInManifestEntry{
+			RegistrationID: reg.ID,
+			QRHash:         helpers.TicketQRHash(reg.QRCode),
+			AttendeeName:   reg.AttendeeName(),
+			Status:         reg.Status,
+			CheckedInAt:    reg.CheckedInAt,
+
+			AttendedSessionIDs: attendedSessions[reg.ID],
+		}
+		if reg.TicketType != nil {
+			entry.TicketType = reg.TicketType.Name
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+
+	// Tanda tangan dihitung dari byte JSON yang sama persis dengan yang dikirim ke scanner
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menyusun manifest check-in",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	keyID, signature := helpers.SignCheckInManifest(payload)
+	_, publicKey := helpers.CheckInManifestPublicKey()
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Manifest check-in event",
+		Data: gin.H{
+			"manifest":   json.RawMessage(payload),
+			"algorithm":  "Ed25519",
+			"key_id":     keyID,
+			"public_key": publicKey,
+			"signature":  signature,
+		},
+	})
+}
+
+// GET /api/checkin-manifest/public-key
+// Kunci publik Ed25519 untuk memverifikasi tanda tangan manifest check-in di aplikasi scanner
+func GetCheckInManifestPublicKey(c *gin.Context) {
+	keyID, publicKey := helpers.CheckInManifestPublicKey()
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Kunci publik manifest check-in",
+		Data: gin.H{
+			"algorithm":  "Ed25519",
+			"key_id":     keyID,
+			"public_key": publicKey,
+		},
+	})
+}
+
+// POST /api/scan/sync
+// Sinkronisasi batch scan QR yang dilakukan scanner saat offline. Setiap scan diproses terpisah dan
+// hasilnya dikembalikan per item (urutan sama dengan request). Scan ganda dari beberapa perangkat
+// diselesaikan dengan prinsip scan paling awal yang menang.
+func SyncOfflineScans(c *gin.Context) {
+	var req OfflineScanSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+	if len(req.Scans) > maxOfflineScanBatch {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: fmt.Sprintf("Maksimal %d scan per sinkronisasi", maxOfflineScanBatch),
+		})
+		return
+	}
+
+	event, ok := loadManagedEvent(c, req.EventID, "Hanya pembuat event atau panitia yang dapat melakukan check-in")
+	if !ok {
+		return
+	}
+	if event.EventType == "online" {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Event online tidak menggunakan scan QR untuk check-in",
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+
+	// Proses dari scan paling awal agar scan ganda dalam satu batch dimenangkan scan pertama
+	order := make([]int, len(req.Scans))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return req.Scans[order[a]].ScannedAt.Before(req.Scans[order[b]].ScannedAt)
+	})
+
+	hasSessions := helpers.EventHasSessions(event.ID)
+	results := make([]OfflineScanResult, len(req.Scans))
+	summary := map[string]int{}
+	for _, i := range order {
+		results[i] = applyOfflineScan(event, hasSessions, req.DeviceID, userID, req.Scans[i])
+		summary[results[i].Result]++
+	}
+
+	if summary[models.ScanResultCheckedIn] > 0 {
+		CreateActivity(userID, "check_in_sync", "event", event.ID,
+			fmt.Sprintf("Sinkronisasi %d scan offline dari perangkat %s di event %s (%d check-in baru)",
+				len(req.Scans), req.DeviceID, event.Title, summary[models.ScanResultCheckedIn]))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Sinkronisasi scan selesai",
+		Data: gin.H{
+			"event_id":  event.ID,
+			"device_id": req.DeviceID,
+			"summary":   summary,
+			"results":   results,
+		},
+	})
+}
+
+// applyOfflineScan memproses satu scan offline dalam transaksinya sendiri dan mencatatnya di check_in_scans.
+// Scan yang sudah pernah disinkronkan (device_id + client_scan_id sama) mengembalikan hasil tersimpan.
+// Pada event multi-sesi kehadiran dicatat per sesi; scan ganda dinilai per sesi.
+func applyOfflineScan(event *models.Event, hasSessions bool, deviceID string, scannedBy uint, item OfflineScanItem) OfflineScanResult {
+	if result, ok := previousOfflineScan(deviceID, item.ClientScanID); ok {
+		return result
+	}
+
+	// Jam perangkat bisa melenceng; scan "dari masa depan" dianggap terjadi saat diterima server.
+	// Dipotong ke milidetik (presisi kolom datetime(3)) agar bisa dicocokkan ulang dengan checked_in_at.
+	scannedAt := item.ScannedAt.Truncate(time.Millisecond)
+	if now := time.Now(); scannedAt.After(now) {
+		scannedAt = now
+	}
+
+	scan := models.CheckInScan{
+		EventID:      event.ID,
+		DeviceID:     deviceID,
+		ClientScanID: item.ClientScanID,
+		QRHash:       helpers.TicketQRHash(item.QRCode),
+		ScannedAt:    scannedAt,
+		ScannedByID:  scannedBy,
+	}
+	result := OfflineScanResult{ClientScanID: item.ClientScanID}
+
+	found, _, message := findRegistrationByTicketAt(item.QRCode, scannedAt)
+	if found == nil {
+		scan.Result, scan.Message = models.ScanResultInvalid, message
+	} else if found.EventID != event.ID {
+		scan.Result, scan.Message = models.ScanResultWrongEvent, "QR Code milik event lain"
+	}
+
+	var session *models.EventSession
+	if scan.Result == "" && hasSessions {
+		resolved, err := helpers.ResolveEventSession(event.ID, item.SessionID, scannedAt)
+		if err != nil {
+			scan.RegistrationID = &found.ID
+			scan.Result, scan.Message = models.ScanResultNotEligible, sessionErrorMessage(err)
+		} else {
+			session = resolved
+			scan.EventSessionID = &session.ID
+		}
+	}
+	if scan.Result != "" {
+		if err := database.DB.Create(&scan).Error; helpers.IsDuplicateEntryError(err) {
+			if previous, ok := previousOfflineScan(deviceID, item.ClientScanID); ok {
+				return previous
+			}
+		}
+		result.Result, result.Message = scan.Result, scan.Message
+		result.RegistrationID = scan.RegistrationID
+		return result
+	}
+
+	scan.RegistrationID = &found.ID
+	result.RegistrationID = &found.ID
+	result.AttendeeName = found.AttendeeName()
+
+	var registration models.Registration
+	var checkedInAt *time.Time
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&registration, found.ID).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case session != nil && (registration.Status == "confirmed" || registration.Status == "checked_in"):
+			record, err := helpers.RecordSessionCheckIn(tx, &registration, session, models.AttendanceMethodOfflineSync, scannedAt, &scannedBy, "")
+			if err == helpers.ErrSessionAlreadyCheckedIn {
+				scan.Result, scan.Message = models.ScanResultDuplicate, "Peserta sudah check-in di sesi "+session.Title
+				// Simpan waktu scan paling awal sebagai waktu check-in sesi
+				if scannedAt.Before(record.CheckInAt) {
+					if err := tx.Model(record).Update("check_in_at", scannedAt).Error; err != nil {
+						return err
+					}
+					record.CheckInAt = scannedAt
+				}
+				if registration.CheckedInAt == nil || scannedAt.Before(*registration.CheckedInAt) {
+					if err := tx.Model(&registration).Update("checked_in_at", scannedAt).Error; err != nil {
+						return err
+					}
+					registration.CheckedInAt = &scannedAt
+				}
+			} else if err != nil {
+				return err
+			} else {
+				scan.Result, scan.Message = models.ScanResultCheckedIn, "Check-in sesi "+session.Title+" berhasil"
+			}
+			checkedInAt = &record.CheckInAt
+		case registration.Status == "checked_in":
+			scan.Result, scan.Message = models.ScanResultDuplicate, "Peserta sudah check-in sebelumnya"
+			// Scan offline bisa tiba setelah scan yang terjadi lebih belakangan; simpan waktu paling awal
+			if registration.CheckedInAt == nil || scannedAt.Before(*registration.CheckedInAt) {
+				if err := tx.Model(&registration).Update("checked_in_at", scannedAt).Error; err != nil {
+					return err
+				}
+				registration.CheckedInAt = &scannedAt
+			}
+			checkedInAt = registration.CheckedInAt
+		case registration.Status == "confirmed":
+			registration.Status = "checked_in"
+			registration.Attendance = true
+			registration.AttendanceType = "offline"
+			registration.CheckedInAt = &scannedAt
+			if err := tx.Model(&registration).Updates(map[string]interface{}{
+				"status":          registration.Status,
+				"attendance":      true,
+				"attendance_type": registration.AttendanceType,
+				"checked_in_at":   scannedAt,
+			}).Error; err != nil {
+				return err
+			}
+			scan.Result, scan.Message = models.ScanResultCheckedIn, "Check-in berhasil"
+			checkedInAt = registration.CheckedInAt
+		case registration.Status == "pending":
+			scan.Result, scan.Message = models.ScanResultNotEligible, "Peserta belum melunasi pembayaran (Status: Pending)"
+		default:
+			scan.Result, scan.Message = models.ScanResultNotEligible, "Registrasi tidak aktif (Status: "+registration.Status+")"
+		}
+
+		return tx.Create(&scan).Error
+	})
+	if err != nil {
+		if helpers.IsDuplicateEntryError(err) {
+			if previous, ok := previousOfflineScan(deviceID, item.ClientScanID); ok {
+				return previous
+			}
+		}
+		result.Result, result.Message = models.ScanResultInvalid, "Gagal menyimpan scan, silakan sinkronkan ulang"
+		return result
+	}
+
+	result.Result, result.Message = scan.Result, scan.Message
+	result.CheckedInAt = checkedInAt
+	if scan.Result == models.ScanResultDuplicate {
+		result.FirstDeviceID = firstCheckInDevice(registration.ID, scan.EventSessionID, checkedInAt)
+	}
+	return result
+}
+
+// previousOfflineScan mengambil hasil scan yang sudah pernah disinkronkan perangkat yang sama
+func previousOfflineScan(deviceID, clientScanID string) (OfflineScanResult, bool) {
+	var scan models.CheckInScan
+	if err := database.DB.Where("device_id = ? AND client_scan_id = ?", deviceID, clientScanID).First(&scan).Error; err != nil {
+		return OfflineScanResult{}, false
+	}
+
+	result := OfflineScanResult{
+		ClientScanID:   scan.ClientScanID,
+		Result:         scan.Result,
+		Message:        scan.Message,
+		RegistrationID: scan.RegistrationID,
+		Replayed:       true,
+	}
+	if scan.RegistrationID != nil {
+		var registration models.Registration
+		if err := database.DB.Preload("User").First(&registration, *scan.RegistrationID).Error; err == nil {
+			result.AttendeeName = registration.AttendeeName()
+			if scan.EventSessionID != nil {
+				var record models.AttendanceRecord
+				if database.DB.Where("registration_id = ? AND event_session_id = ?", registration.ID, *scan.EventSessionID).
+					First(&record).Error == nil {
+					result.CheckedInAt = &record.CheckInAt
+				}
+			} else if registration.Status == "checked_in" {
+				result.CheckedInAt = registration.CheckedInAt
+			}
+			if scan.Result == models.ScanResultDuplicate {
+				result.FirstDeviceID = firstCheckInDevice(registration.ID, scan.EventSessionID, result.CheckedInAt)
+			}
+		}
+	}
+	return result, true
+}
+
+// firstCheckInDevice mengembalikan perangkat yang scan-nya menjadi waktu check-in registrasi/sesi (scan paling awal).
+// Jika tidak ada scan offline yang cocok, check-in terjadi lewat scan online atau panitia dan dilaporkan "online".
+func firstCheckInDevice(registrationID uint, sessionID *uint, checkedInAt *time.Time) string {
+	if checkedInAt == nil {
+		return "online"
+	}
+	query := database.DB.Where("registration_id = ? AND scanned_at = ?", registrationID, *checkedInAt)
+	if sessionID != nil {
+		query = query.Where("event_session_id = ?", *sessionID)
+	}
+	var scan models.CheckInScan
+	if err := query.
+		Where("result IN ?", []string{models.ScanResultCheckedIn, models.ScanResultDuplicate}).
+		Order("id ASC").First(&scan).Error; err != nil {
+		return "online"
+	}
+	return scan.DeviceID
+}
@@ -9,6 +9,7 @@ import (
 	"santrikoding/backend-api/database"
 	"santrikoding/backend-api/helpers"
 	"santrikoding/backend-api/models"
+	"strconv"
 	"strings"
 	"time"
 
@@ -89,6 +90,30 @@ func CreateEvent(c *gin.Context) {
 	// Parse speakers (JSON string)
 	speakers := c.PostForm("speakers")
 
+	// Kebijakan refund (optional): batas waktu dan persentase dana yang dikembalikan
+	refundDeadline, _ := parseDeadlineInput(c.PostForm("refund_deadline"))
+	transferDeadline, _ := parseDeadlineInput(c.PostForm("transfer_deadline"))
+	refundPercentage, ok := parsePercentage(c.PostForm("refund_percentage"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "refund_percentage harus antara 0 dan 100"})
+		return
+	}
+	certificateMinAttendance, ok := parsePercentage(c.PostForm("certificate_min_attendance"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "certificate_min_attendance harus antara 0 dan 100"})
+		return
+	}
+
+	// Metode pembayaran (optional, default gateway) dan rekening tujuan untuk transfer manual
+	paymentMethod := c.PostForm("payment_method")
+	if paymentMethod == "" {
+		paymentMethod = models.EventPaymentGateway
+	}
+	if paymentMethod != models.EventPaymentGateway && paymentMethod != models.EventPaymentManualTransfer {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "payment_method harus 'gateway' atau 'manual_transfer'"})
+		return
+	}
+
 	fmt.Println("Data text diterima:", title) // Debug Log 2
 
 	// 2. Handle Upload
@@ -173,22 +198,32 @@ func CreateEvent(c *gin.Context) {
 	slug := strings.ToLower(strings.ReplaceAll(title, " ", "-")) + "-" + uuid.New().String()[:8]
 
 	event := models.Event{
-		Title:                title,
-		Slug:                 slug,
-		Description:          description,
-		Location:             location,
-		Status:               status,
-		Category:             category,
-		Quota:                quota,
-		Price:                price,
-		EventType:            eventType, // "offline" atau "online"
-		Speakers:             speakers,  // JSON string of speakers
-		StartDate:            startDate,
-		EndDate:              endDate,
-		RegistrationDeadline: registrationDeadline,
-		Banner:               bannerPath,
-		CreatedByID:          finalUserID,
-	}
+		Title:                 title,
+		Slug:                  slug,
+		Description:           description,
+		Location:              location,
+		Status:                status,
+		Category:              category,
+		Quota:                 quota,
+		WaitlistEnabled:       c.PostForm("waitlist_enabled") == "true",
+		TransferEnabled:       c.PostForm("transfer_enabled") == "true",
+		TransferDeadline:      transferDeadline,
+		Price:                 price,
+		EventType:             eventType, // "offline" atau "online"
+		Speakers:              speakers,  // JSON string of speakers
+		StartDate:             startDate,
+		EndDate:               endDate,
+		RegistrationDeadline:  registrationDeadline,
+		RefundDeadline:        refundDeadline,
+		RefundPercentage:      refundPercentage,
+		PaymentMethod:         paymentMethod,
+		TransferBankName:      c.PostForm("transfer_bank_name"),
+		TransferAccountNumber: c.PostForm("transfer_account_number"),
+		TransferAccountName:   c.PostForm("transfer_account_name"),
+		Banner:                bannerPath,
+		CreatedByID:           finalUserID,
+	}
+	event.CertificateMinAttendance = certificateMinAttendance
 
 	// Mulai transaksi untuk event dan committee members
 	tx := database.DB.Begin()
@@ -269,37 +304,56 @@ func FindEventBySlug(c *gin.Context) {
 		return
 	}
 
-	// Hitung jumlah peserta yang sudah terdaftar
+	// Hitung jumlah peserta yang sudah terdaftar (registrasi yang ditolak/dibatalkan tidak dihitung)
 	var registeredCount int64
-	database.DB.Model(&models.Registration{}).Where("event_id = ?", event.ID).Count(&registeredCount)
+	database.DB.Model(&models.Registration{}).
+		Where("event_id = ? AND status NOT IN ?", event.ID, models.ReleasedSeatStatuses).
+		Count(&registeredCount)
 
-	// Hitung kuota tersisa
-	availableQuota := event.Quota - int(registeredCount)
+	// Hitung kuota tersisa (kursi yang sedang ditawarkan ke waitlist ikut terpakai)
+	availableQuota := event.Quota - int(helpers.SeatsTaken(database.DB, event.ID, 0))
+	if availableQuota < 0 {
+		availableQuota = 0
+	}
 	if event.Quota == 0 {
 		availableQuota = -1 // -1 berarti unlimited
 	}
 
 	// Buat response dengan informasi tambahan
 	responseData := gin.H{
-		"id":               event.ID,
-		"title":            event.Title,
-		"slug":             event.Slug,
-		"description":      event.Description,
-		"banner":           event.Banner,
-		"location":         event.Location,
-		"start_date":       event.StartDate,
-		"end_date":         event.EndDate,
-		"status":           event.Status,
-		"category":         event.Category,
-		"quota":            event.Quota,
-		"price":            event.Price,
+		"id":                event.ID,
+		"title":             event.Title,
+		"slug":              event.Slug,
+		"description":       event.Description,
+		"banner":            event.Banner,
+		"location":          event.Location,
+		"start_date":        event.StartDate,
+		"end_date":          event.EndDate,
+		"status":            event.Status,
+		"category":          event.Category,
+		"quota":             event.Quota,
+		"price":             event.Price,
+		"refund_deadline":   event.RefundDeadline,
+		"refund_percentage": event.RefundPercentage,
+		"payment_method":    event.PaymentMethod,
+		"transfer_account": gin.H{
+			"bank_name":      event.TransferBankName,
+			"account_number": event.TransferAccountNumber,
+			"account_name":   event.TransferAccountName,
+		},
 		"event_type":       event.EventType,
+		"ticket_types":     summarizeTicketTypes(event.ID, true), // Hanya tiket public; kosong = pakai price event
 		"registered_count": registeredCount,
 		"available_quota":  availableQuota,
+		"waitlist_enabled": event.WaitlistEnabled,
+		"waitlist_count":   helpers.CountWaitlistWaiting(event.ID),
 		"created_by":       event.CreatedBy,
 		"created_at":       event.CreatedAt,
 		"updated_at":       event.UpdatedAt,
 	}
+	// Jadwal sesi (event multi-sesi) dan syarat kehadiran sertifikat
+	responseData["sessions"] = helpers.GetEventSessions(event.ID)
+	responseData["certificate_min_attendance"] = event.CertificateMinAttendance
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -419,6 +473,39 @@ func UpdateEvent(c *gin.Context) {
 		event.RegistrationDeadline = nil
 	}
 
+	// Kebijakan refund (optional)
+	if deadline, ok := parseDeadlineInput(c.PostForm("refund_deadline")); ok {
+		event.RefundDeadline = deadline
+	} else if c.PostForm("clear_refund_deadline") == "true" {
+		event.RefundDeadline = nil
+	}
+	if percentageStr := c.PostForm("refund_percentage"); percentageStr != "" {
+		percentage, ok := parsePercentage(percentageStr)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "refund_percentage harus antara 0 dan 100"})
+			return
+		}
+		event.RefundPercentage = percentage
+	}
+
+	// Metode pembayaran dan rekening tujuan transfer manual (optional)
+	if paymentMethod := c.PostForm("payment_method"); paymentMethod != "" {
+		if paymentMethod != models.EventPaymentGateway && paymentMethod != models.EventPaymentManualTransfer {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "payment_method harus 'gateway' atau 'manual_transfer'"})
+			return
+		}
+		event.PaymentMethod = paymentMethod
+	}
+	if bankName, ok := c.GetPostForm("transfer_bank_name"); ok {
+		event.TransferBankName = bankName
+	}
+	if accountNumber, ok := c.GetPostForm("transfer_account_number"); ok {
+		event.TransferAccountNumber = accountNumber
+	}
+	if accountName, ok := c.GetPostForm("transfer_account_name"); ok {
+		event.TransferAccountName = accountName
+	}
+
 	// 4. Handle Ganti Banner (Opsional)
 	file, err := c.FormFile("banner")
 	if err == nil {
@@ -438,9 +525,37 @@ func UpdateEvent(c *gin.Context) {
 		event.Banner = fmt.Sprintf("%s/public/images/%s", baseURL, filename)
 	}
 
+	// Waitlist (optional)
+	if waitlist, ok := c.GetPostForm("waitlist_enabled"); ok {
+		event.WaitlistEnabled = waitlist == "true"
+	}
+
+	// Transfer tiket (optional)
+	if transfer, ok := c.GetPostForm("transfer_enabled"); ok {
+		event.TransferEnabled = transfer == "true"
+	}
+	if deadline, ok := parseDeadlineInput(c.PostForm("transfer_deadline")); ok {
+		event.TransferDeadline = deadline
+	} else if c.PostForm("clear_transfer_deadline") == "true" {
+		event.TransferDeadline = nil
+	}
+
+	// Syarat kehadiran sertifikat (optional)
+	if minAttendance, ok := c.GetPostForm("certificate_min_attendance"); ok {
+		percentage, valid := parsePercentage(minAttendance)
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "certificate_min_attendance harus antara 0 dan 100"})
+			return
+		}
+		event.CertificateMinAttendance = percentage
+	}
+
 	// 5. Simpan Perubahan
 	database.DB.Save(&event)
 
+	// Kuota yang ditambah membuka kursi untuk antrian waitlist
+	go helpers.PromoteWaitlist(event.ID)
+
 	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Event berhasil diupdate", "data": event})
 }
 
@@ -718,9 +833,58 @@ func GetEventReport(c *gin.Context) {
 	database.DB.Model(&models.Task{}).Where("event_id = ?", eventID).Count(&totalTasks)
 	database.DB.Model(&models.Task{}).Where("event_id = ? AND status = ?", eventID, "done").Count(&completedTasks)
 
+	// Penjualan per tipe tiket (registrasi aktif) dan pendapatan dari payment lunas
+	type TicketSales struct {
+		TicketTypeID *uint  `json:"ticket_type_id"`
+		Name         string `json:"name"`
+		Price        int64  `json:"price"`
+		Quota        int    `json:"quota"`
+		Registered   int64  `json:"registered"`
+		Confirmed    int64  `json:"confirmed"`
+		Revenue      int64  `json:"revenue"`
+	}
+	var salesRows []TicketSales
+	database.DB.Model(&models.Registration{}).
+		Select("ticket_type_id, COUNT(*) AS registered, SUM(CASE WHEN status IN ? THEN 1 ELSE 0 END) AS confirmed",
+			[]string{"confirmed", "checked_in"}).
+		Where("event_id = ? AND status NOT IN ?", eventID, models.ReleasedSeatStatuses).
+		Group("ticket_type_id").
+		Scan(&salesRows)
+	var revenueRows []TicketSales
+	database.DB.Model(&models.Payment{}).
+		Select("registrations.ticket_type_id, COALESCE(SUM(payments.amount), 0) AS revenue").
+		Joins("INNER JOIN registrations ON registrations.id = payments.registration_id").
+		Where("registrations.event_id = ? AND payments.status = ?", eventID, models.PaymentStatusPaid).
+		Group("registrations.ticket_type_id").
+		Scan(&revenueRows)
+
+	ticketSales := []TicketSales{}
+	for _, t := range helpers.GetEventTicketTypes(event.ID, false) {
+		id := t.ID
+		ticketSales = append(ticketSales, TicketSales{TicketTypeID: &id, Name: t.Name, Price: t.Price, Quota: t.Quota})
+	}
+	if len(ticketSales) == 0 {
+		ticketSales = append(ticketSales, TicketSales{Name: "Reguler", Price: event.Price, Quota: event.Quota})
+	}
+	sameType := func(a, b *uint) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+	for i := range ticketSales {
+		for _, row := range salesRows {
+			if sameType(ticketSales[i].TicketTypeID, row.TicketTypeID) {
+				ticketSales[i].Registered, ticketSales[i].Confirmed = row.Registered, row.Confirmed
+			}
+		}
+		for _, row := range revenueRows {
+			if sameType(ticketSales[i].TicketTypeID, row.TicketTypeID) {
+				ticketSales[i].Revenue = row.Revenue
+			}
+		}
+	}
+
 	// Ambil daftar peserta
 	var participants []models.Registration
-	database.DB.Preload("User").Where("event_id = ?", eventID).Find(&participants)
+	database.DB.Preload("User").Preload("TicketType").Where("event_id = ?", eventID).Find(&participants)
 
 	// Buat response
 	report := gin.H{
@@ -738,6 +902,7 @@ func GetEventReport(c *gin.Context) {
 			"completed_tasks":      completedTasks,
 			"task_completion_rate": 0.0,
 		},
+		"ticket_sales": ticketSales,
 		"participants": participants,
 	}
 
@@ -933,15 +1098,81 @@ func GetEventPerformance(c *gin.Context) {
 }
 
 // Background job untuk auto-update status event menjadi "done" berdasarkan end_date
-func AutoCompleteEvents() {
-	// Ambil semua event yang end_date sudah lewat dan status bukan "done"
-	now := time.Now()
+// Dijalankan oleh scheduler (lihat main.go)
+func AutoCompleteEvents() error {
+	// end_date disimpan tanpa jam (00:00), jadi event baru dianggap selesai setelah hari terakhirnya lewat
+	cutoff := time.Now().AddDate(0, 0, -1)
 	var events []models.Event
-	database.DB.Where("end_date < ? AND status != ?", now, "done").Find(&events)
+	if err := database.DB.Where("end_date < ? AND status != ?", cutoff, "done").Find(&events).Error; err != nil {
+		return err
+	}
 
 	for _, event := range events {
 		event.Status = "done"
-		database.DB.Save(&event)
+		if err := database.DB.Save(&event).Error; err != nil {
+			return fmt.Errorf("gagal auto-complete event %d: %v", event.ID, err)
+		}
 		fmt.Printf("Auto-completed event: %s (ID: %d)\n", event.Title, event.ID)
 	}
+	return nil
+}
+
+// SendEventReminders mengirim reminder H-1 ke peserta event yang dimulai besok
+// Dijalankan oleh scheduler (lihat main.go)
+func SendEventReminders() error {
+	now := time.Now()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+
+	var events []models.Event
+	if err := database.DB.Where("status = ? AND start_date >= ? AND start_date < ? AND reminder_sent_at IS NULL",
+		"published", tomorrow, tomorrow.AddDate(0, 0, 1)).Find(&events).Error; err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		var registrations []models.Registration
+		if err := database.DB.Where("event_id = ? AND status = ?", event.ID, "confirmed").Find(&registrations).Error; err != nil {
+			return err
+		}
+
+		eventDate := event.StartDate.Format("02 Jan 2006")
+		for _, reg := range registrations {
+			helpers.NotifyEventReminder(reg.UserID, event.Title, event.Slug, eventDate)
+		}
+
+		if err := database.DB.Model(&event).Update("reminder_sent_at", now).Error; err != nil {
+			return err
+		}
+		fmt.Printf("Reminder H-1 dikirim untuk event %s (%d peserta)\n", event.Title, len(registrations))
+	}
+	return nil
+}
+
+// parseDeadlineInput membaca input deadline format "YYYY-MM-DD HH:mm" atau "YYYY-MM-DD"
+// (tanggal saja berarti sampai akhir hari). ok bernilai false jika kosong atau tidak valid.
+func parseDeadlineInput(value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, false
+	}
+	parsed, err := time.Parse("2006-01-02 15:04", value)
+	if err != nil {
+		parsed, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, false
+		}
+		parsed = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 23, 59, 59, 0, parsed.Location())
+	}
+	return &parsed, true
+}
+
+// parsePercentage membaca persentase (refund, syarat kehadiran sertifikat; kosong = 0). ok bernilai false di luar 0-100.
+func parsePercentage(value string) (int, bool) {
+	if value == "" {
+		return 0, true
+	}
+	percentage, err := strconv.Atoi(value)
+	if err != nil || percentage < 0 || percentage > 100 {
+		return 0, false
+	}
+	return percentage, true
 }
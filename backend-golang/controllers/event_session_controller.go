@@ -0,0 +1,412 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type EventSessionRequest struct {
+	Title       string `json:"title" binding:"required,max=255"`
+	Description string `json:"description"`
+	Location    string `json:"location" binding:"max=255"`
+	StartTime   string `json:"start_time" binding:"required"` // "2006-01-02 15:04"
+	EndTime     string `json:"end_time" binding:"required"`   // "2006-01-02 15:04"
+	SortOrder   int    `json:"sort_order"`
+}
+
+// EventSessionSummary adalah sesi event beserta jumlah peserta yang check-in dan check-out
+type EventSessionSummary struct {
+	models.EventSession
+	CheckedIn  int64 `json:"checked_in"`
+	CheckedOut int64 `json:"checked_out"`
+}
+
+// GET /api/events/:id/sessions
+// Daftar sesi event beserta rekap kehadirannya untuk pembuat event dan panitia
+func GetEventSessions(c *gin.Context) {
+	event, ok := loadManagedEvent(c, c.Param("id"), "Hanya pembuat event atau panitia yang dapat melihat sesi event")
+	if !ok {
+		return
+	}
+
+	sessions := helpers.GetEventSessions(event.ID)
+	summaries := make([]EventSessionSummary, 0, len(sessions))
+	for _, s := range sessions {
+		summary := EventSessionSummary{EventSession: s}
+		database.DB.Model(&models.AttendanceRecord{}).Where("event_session_id = ?", s.ID).Count(&summary.CheckedIn)
+		database.DB.Model(&models.AttendanceRecord{}).Where("event_session_id = ? AND check_out_at IS NOT NULL", s.ID).Count(&summary.CheckedOut)
+		summaries = append(summaries, summary)
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar sesi event",
+		Data:    summaries,
+	})
+}
+
+// POST /api/events/:id/sessions
+func CreateEventSession(c *gin.Context) {
+	event, ok := loadManagedEvent(c, c.Param("id"), "Hanya pembuat event atau panitia yang dapat mengelola sesi event")
+	if !ok {
+		return
+	}
+
+	var req EventSessionRequest
+	if !bindEventSessionRequest(c, &req) {
+		return
+	}
+
+	session := models.EventSession{EventID: event.ID}
+	if !applyEventSessionRequest(c, &session, req) {
+		return
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat sesi event",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	CreateAuditLog(userID, "create", "event_session", session.ID, nil, session,
+		"Membuat sesi "+session.Title+" untuk event "+event.Title, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Sesi event berhasil dibuat",
+		Data:    session,
+	})
+}
+
+// PUT /api/event-sessions/:id
+func UpdateEventSession(c *gin.Context) {
+	var session models.EventSession
+	if err := database.DB.First(&session, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Sesi event tidak ditemukan",
+		})
+		return
+	}
+	event, ok := loadManagedEvent(c, session.EventID, "Hanya pembuat event atau panitia yang dapat mengelola sesi event")
+	if !ok {
+		return
+	}
+
+	var req EventSessionRequest
+	if !bindEventSessionRequest(c, &req) {
+		return
+	}
+
+	oldSession := session
+	if !applyEventSessionRequest(c, &session, req) {
+		return
+	}
+	if err := database.DB.Save(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal memperbarui sesi event",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	CreateAuditLog(userID, "update", "event_session", session.ID, oldSession, session,
+		"Memperbarui sesi "+session.Title+" untuk event "+event.Title, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Sesi event berhasil diperbarui",
+		Data:    session,
+	})
+}
+
+// DELETE /api/event-sessions/:id
+// Sesi yang sudah punya data kehadiran tidak bisa dihapus agar persentase kehadiran peserta tidak berubah
+func DeleteEventSession(c *gin.Context) {
+	var session models.EventSession
+	if err := database.DB.First(&session, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Sesi event tidak ditemukan",
+		})
+		return
+	}
+	event, ok := loadManagedEvent(c, session.EventID, "Hanya pembuat event atau panitia yang dapat mengelola sesi event")
+	if !ok {
+		return
+	}
+
+	var used int64
+	database.DB.Model(&models.AttendanceRecord{}).Where("event_session_id = ?", session.ID).Count(&used)
+	if used > 0 {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Sesi ini sudah memiliki data kehadiran dan tidak bisa dihapus",
+		})
+		return
+	}
+
+	if err := database.DB.Delete(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menghapus sesi event",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	CreateAuditLog(userID, "delete", "event_session", session.ID, session, nil,
+		"Menghapus sesi "+session.Title+" dari event "+event.Title, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Sesi event berhasil dihapus",
+	})
+}
+
+// POST /api/scan/check-out
+// Scan QR saat peserta meninggalkan sesi (hanya event multi-sesi). session_id kosong = sesi yang sedang berlangsung.
+func VerifyCheckOut(c *gin.Context) {
+	var req ScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "QR Code tidak terbaca"})
+		return
+	}
+
+	found, status, message := findRegistrationByTicket(req.QRCode)
+	if found == nil {
+		c.JSON(status, gin.H{"success": false, "message": message})
+		return
+	}
+	registration := *found
+
+	if !helpers.EventHasSessions(registration.EventID) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Check-out hanya tersedia untuk event yang memiliki sesi"})
+		return
+	}
+	session, err := helpers.ResolveEventSession(registration.EventID, req.SessionID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": sessionErrorMessage(err)})
+		return
+	}
+
+	record, err := helpers.RecordSessionCheckOut(database.DB, registration.ID, session.ID, time.Now())
+	if err == helpers.ErrSessionAlreadyOut {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"message": "Peserta SUDAH Check-out dari sesi ini!",
+			"data": gin.H{
+				"user_name":      registration.AttendeeName(),
+				"session":        session.Title,
+				"check_out_time": record.CheckOutAt,
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": sessionErrorMessage(err)})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	CreateActivity(userID, "check_out", "registration", registration.ID,
+		fmt.Sprintf("Panitia melakukan check-out untuk %s di sesi %s event %s", registration.AttendeeName(), session.Title, registration.Event.Title))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Check-out Berhasil!",
+		"data": gin.H{
+			"user_name":      registration.AttendeeName(),
+			"event":          registration.Event.Title,
+			"session":        session.Title,
+			"check_in_time":  record.CheckInAt,
+			"check_out_time": record.CheckOutAt,
+		},
+	})
+}
+
+// GET /api/events/:id/attendance
+// Rekap kehadiran per peserta (jumlah sesi dihadiri, persentase, kelayakan sertifikat)
+func GetEventAttendance(c *gin.Context) {
+	event, ok := loadManagedEvent(c, c.Param("id"), "Hanya pembuat event atau panitia yang dapat melihat rekap kehadiran")
+	if !ok {
+		return
+	}
+
+	var registrations []models.Registration
+	if err := database.DB.Preload("User").
+		Where("event_id = ? AND status NOT IN ?", event.ID, models.ReleasedSeatStatuses).
+		Order("id ASC").Find(&registrations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengambil data peserta",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	summaries := helpers.GetAttendanceSummaries(*event, registrations)
+	participants := make([]gin.H, 0, len(registrations))
+	eligible := 0
+	for _, reg := range registrations {
+		summary := summaries[reg.ID]
+		if summary.CertificateEligible {
+			eligible++
+		}
+		participants = append(participants, gin.H{
+			"registration_id": reg.ID,
+			"name":            reg.AttendeeName(),
+			"email":           reg.AttendeeEmail(),
+			"status":          reg.Status,
+			"attendance":      summary,
+		})
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Rekap kehadiran event",
+		Data: gin.H{
+			"certificate_min_attendance": event.CertificateMinAttendance,
+			"sessions":                   helpers.GetEventSessions(event.ID),
+			"eligible_count":             eligible,
+			"participants":               participants,
+		},
+	})
+}
+
+// GET /api/registrations/:id/attendance
+// Riwayat kehadiran per sesi untuk pemilik tiket, pembuat event, atau panitia
+func GetRegistrationAttendance(c *gin.Context) {
+	var registration models.Registration
+	if err := database.DB.Preload("Event").First(&registration, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Registrasi tidak ditemukan",
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	if registration.UserID != userID {
+		if _, ok := loadManagedEvent(c, registration.EventID, "Anda tidak memiliki akses ke data kehadiran ini"); !ok {
+			return
+		}
+	}
+
+	var records []models.AttendanceRecord
+	database.DB.Preload("EventSession").Where("registration_id = ?", registration.ID).
+		Order("check_in_at ASC").Find(&records)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Riwayat kehadiran",
+		Data: gin.H{
+			"sessions": helpers.GetEventSessions(registration.EventID),
+			"records":  records,
+			"summary":  helpers.GetAttendanceSummary(registration.Event, registration),
+		},
+	})
+}
+
+// checkInToSession mencatat check-in registrasi pada sesi event multi-sesi (scan QR maupun self check-in).
+// Registrasi harus confirmed atau sudah checked_in di sesi lain. Mengembalikan status HTTP dan pesan jika gagal.
+func checkInToSession(registration *models.Registration, sessionID *uint, method string, recordedByID *uint, proofURL string) (*models.AttendanceRecord, *models.EventSession, int, string) {
+	if message := checkInNotAllowedMessage(registration.Status); message != "" {
+		return nil, nil, http.StatusBadRequest, message
+	}
+
+	now := time.Now()
+	session, err := helpers.ResolveEventSession(registration.EventID, sessionID, now)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, sessionErrorMessage(err)
+	}
+
+	var record *models.AttendanceRecord
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var txErr error
+		record, txErr = helpers.RecordSessionCheckIn(tx, registration, session, method, now, recordedByID, proofURL)
+		return txErr
+	})
+	if err == helpers.ErrSessionAlreadyCheckedIn {
+		return record, session, http.StatusConflict, sessionErrorMessage(err)
+	}
+	if err != nil {
+		return nil, session, http.StatusInternalServerError, "Gagal menyimpan check-in"
+	}
+	return record, session, 0, ""
+}
+
+func sessionErrorMessage(err error) string {
+	switch err {
+	case helpers.ErrSessionNotFound:
+		return "Sesi tidak ditemukan di event ini"
+	case helpers.ErrNoActiveSession:
+		return "Tidak ada sesi yang sedang berlangsung. Pilih sesi terlebih dahulu."
+	case helpers.ErrSessionAlreadyCheckedIn:
+		return "Peserta SUDAH Check-in di sesi ini!"
+	case helpers.ErrSessionNotCheckedIn:
+		return "Peserta belum check-in di sesi ini"
+	case helpers.ErrSessionAlreadyOut:
+		return "Peserta sudah check-out dari sesi ini"
+	}
+	return "Gagal memproses kehadiran sesi"
+}
+
+func bindEventSessionRequest(c *gin.Context, req *EventSessionRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return false
+	}
+	return true
+}
+
+// applyEventSessionRequest mengisi sesi dari request dan memvalidasi jadwalnya
+func applyEventSessionRequest(c *gin.Context, session *models.EventSession, req EventSessionRequest) bool {
+	fieldErrors := map[string]string{}
+
+	startTime, err := time.Parse("2006-01-02 15:04", req.StartTime)
+	if err != nil {
+		fieldErrors["start_time"] = "Format harus YYYY-MM-DD HH:mm"
+	}
+	endTime, err := time.Parse("2006-01-02 15:04", req.EndTime)
+	if err != nil {
+		fieldErrors["end_time"] = "Format harus YYYY-MM-DD HH:mm"
+	}
+	if len(fieldErrors) == 0 && !endTime.After(startTime) {
+		fieldErrors["end_time"] = "Harus setelah waktu mulai sesi"
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Jadwal sesi tidak valid",
+			Errors:  fieldErrors,
+		})
+		return false
+	}
+
+	session.Title = req.Title
+	session.Description = req.Description
+	session.Location = req.Location
+	session.StartTime = startTime
+	session.EndTime = endTime
+	session.SortOrder = req.SortOrder
+	return true
+}
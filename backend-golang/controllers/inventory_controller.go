@@ -15,25 +15,6 @@ import (
 	"github.com/google/uuid"
 )
 
-// Helper function to check if user is logistik or super admin
-func isLogistikOrAdmin(userID uint) bool {
-	var user models.User
-	if err := database.DB.Preload("Role").First(&user, userID).Error; err != nil {
-		return false
-	}
-	// Super Admin (role_id 1) atau role dengan code "logistik"
-	return user.RoleID == 1 || user.Role.Code == "logistik"
-}
-
-// Helper function to check if user is committee member of event
-func isEventCommittee(userID uint, eventID uint) bool {
-	var committeeMember models.CommitteeMember
-	if err := database.DB.Where("event_id = ? AND user_id = ?", eventID, userID).First(&committeeMember).Error; err == nil {
-		return true
-	}
-	return false
-}
-
 // ==================== INVENTORY CRUD ====================
 
 type CreateInventoryRequest struct {
@@ -75,7 +56,7 @@ func CreateItem(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	if !isLogistikOrAdmin(userID) {
+	if !helpers.HasPermission(userID, "inventory.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
 			Message: "Hanya Admin atau Logistik yang dapat menambah barang",
@@ -169,7 +150,7 @@ func UpdateItem(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	if !isLogistikOrAdmin(userID) {
+	if !helpers.HasPermission(userID, "inventory.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
 			Message: "Hanya Admin atau Logistik yang dapat mengubah barang",
@@ -312,7 +293,7 @@ func DeleteItem(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	if !isLogistikOrAdmin(userID) {
+	if !helpers.HasPermission(userID, "inventory.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
 			Message: "Hanya Admin atau Logistik yang dapat menghapus barang",
@@ -415,7 +396,7 @@ func RequestLoan(c *gin.Context) {
 	}
 
 	// Cek apakah user adalah panitia dari event ini atau super admin
-	if !isEventCommittee(userID, event.ID) && !isLogistikOrAdmin(userID) {
+	if !helpers.HasEventPermission(userID, event.ID, "loan.request") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
 			Message: "Hanya panitia event atau admin yang dapat mengajukan peminjaman",
@@ -588,7 +569,7 @@ func ApproveLoan(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	if !isLogistikOrAdmin(userID) {
+	if !helpers.HasPermission(userID, "inventory.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
 			Message: "Hanya Admin atau Logistik yang dapat menyetujui peminjaman",
@@ -664,7 +645,7 @@ func RejectLoan(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	if !isLogistikOrAdmin(userID) {
+	if !helpers.HasPermission(userID, "inventory.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
 			Message: "Hanya Admin atau Logistik yang dapat menolak peminjaman",
@@ -739,7 +720,7 @@ func ReturnLoan(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	if !isLogistikOrAdmin(userID) {
+	if !helpers.HasPermission(userID, "inventory.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
 			Message: "Hanya Admin atau Logistik yang dapat menandai pengembalian",
@@ -810,7 +791,7 @@ func GetAllLoans(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	if !isLogistikOrAdmin(userID) {
+	if !helpers.HasPermission(userID, "inventory.manage") {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
 			Message: "Hanya Admin atau Logistik yang dapat melihat semua peminjaman",
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /api/registrations/:id/receipt
+// Mengunduh invoice PDF registrasi berbayar. Bisa diakses pemilik registrasi,
+// panitia event dengan payment.refund, atau bendahara (payment.report).
+func DownloadReceipt(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var registration models.Registration
+	if err := database.DB.First(&registration, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran tidak ditemukan",
+		})
+		return
+	}
+
+	if registration.UserID != userID &&
+		!helpers.HasEventPermission(userID, registration.EventID, "payment.refund") &&
+		!helpers.HasPermission(userID, "payment.report") {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses ke invoice ini",
+		})
+		return
+	}
+
+	invoice, err := helpers.EnsureInvoice(registration.ID)
+	if err == helpers.ErrInvoiceNotAvailable {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Invoice belum tersedia karena pembayaran belum lunas",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating invoice for registration %d: %v", registration.ID, err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat invoice",
+		})
+		return
+	}
+
+	pdf, err := helpers.RenderInvoicePDF(invoice)
+	if err != nil {
+		log.Printf("Error rendering invoice %s: %v", invoice.Number, err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat file invoice",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+helpers.InvoiceFilename(invoice))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// sendPaymentReceipt membuat invoice untuk registrasi yang baru lunas lalu mengirimkannya lewat email
+func sendPaymentReceipt(registrationID uint) {
+	invoice, err := helpers.EnsureInvoice(registrationID)
+	if err != nil {
+		log.Printf("Gagal membuat invoice registrasi %d: %v", registrationID, err)
+		return
+	}
+	if invoice.EmailSentAt != nil || invoice.BilledEmail == "" {
+		return
+	}
+
+	pdf, err := helpers.RenderInvoicePDF(invoice)
+	if err != nil {
+		log.Printf("Gagal membuat PDF invoice %s: %v", invoice.Number, err)
+		return
+	}
+	if err := helpers.SendPaymentReceiptEmail(invoice.BilledEmail, invoice.BilledName, invoice.EventTitle,
+		invoice.Number, invoice.Amount, helpers.InvoiceFilename(invoice), pdf); err != nil {
+		return
+	}
+	helpers.MarkInvoiceEmailed(invoice.ID)
+}
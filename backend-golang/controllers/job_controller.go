@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/scheduler"
+	"santrikoding/backend-api/structs"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJobs mengambil daftar background job beserta jadwal dan run terakhir (permission jobs.manage)
+func GetJobs(c *gin.Context) {
+	type JobResponse struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Schedule    string          `json:"schedule"`
+		PerInstance bool            `json:"per_instance"`
+		NextRunAt   time.Time       `json:"next_run_at"`
+		LastRun     *models.JobRun  `json:"last_run"`
+		Lock        *models.JobLock `json:"lock"`
+	}
+
+	var response []JobResponse
+	for _, job := range scheduler.Jobs() {
+		item := JobResponse{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Spec,
+			PerInstance: job.PerInstance,
+			NextRunAt:   job.NextRun(),
+		}
+
+		var lastRun models.JobRun
+		if err := database.DB.Where("job_name = ?", job.Name).Order("id DESC").First(&lastRun).Error; err == nil {
+			item.LastRun = &lastRun
+		}
+
+		var lock models.JobLock
+		if err := database.DB.Where("name = ?", job.Name).First(&lock).Error; err == nil {
+			item.Lock = &lock
+		}
+
+		response = append(response, item)
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar background job",
+		Data:    response,
+	})
+}
+
+// GetJobRuns mengambil riwayat eksekusi sebuah job dengan pagination (permission jobs.manage)
+func GetJobRuns(c *gin.Context) {
+	name := c.Param("name")
+	if _, ok := scheduler.Get(name); !ok {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Job tidak ditemukan",
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.JobRun{}).Where("job_name = ?", name)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var runs []models.JobRun
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengambil riwayat job",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Riwayat eksekusi job",
+		Data: gin.H{
+			"runs":        runs,
+			"total":       total,
+			"page":        page,
+			"limit":       limit,
+			"total_pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// TriggerJob menjalankan job secara manual di luar jadwal (permission jobs.manage)
+func TriggerJob(c *gin.Context) {
+	// Ambil user ID dari token
+	userIDInterface, _ := c.Get("id")
+	var userID uint
+	switch v := userIDInterface.(type) {
+	case float64:
+		userID = uint(v)
+	case uint:
+		userID = v
+	case int:
+		userID = uint(v)
+	}
+
+	name := c.Param("name")
+	run, err := scheduler.Trigger(name, userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case scheduler.ErrJobNotFound:
+			status = http.StatusNotFound
+		case scheduler.ErrJobRunning:
+			status = http.StatusConflict
+		}
+		c.JSON(status, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menjalankan job: " + err.Error(),
+		})
+		return
+	}
+
+	// Catat audit log
+	CreateAuditLog(
+		userID,
+		"trigger",
+		"job",
+		run.ID,
+		nil,
+		gin.H{"job": name},
+		"Menjalankan job "+name+" secara manual",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusAccepted, structs.SuccessResponse{
+		Success: true,
+		Message: "Job " + name + " sedang dijalankan",
+		Data:    run,
+	})
+}
@@ -1,11 +1,15 @@
 package controllers
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 	"santrikoding/backend-api/database"
 	"santrikoding/backend-api/helpers"
 	"santrikoding/backend-api/models"
 	"santrikoding/backend-api/structs"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -26,27 +30,248 @@ func Login(c *gin.Context) {
 		})
 		return
 	}
-	if err := database.DB.Preload("Role").Where("email = ? OR username = ?", req.Username, req.Username).First(&user).Error; err != nil {
+	ip := c.ClientIP()
+	maxAttempts := helpers.GetSettingInt("max_login_attempts", 5)
+	maxAttemptsPerIP := helpers.GetSettingInt("max_login_attempts_per_ip", 20)
+	lockoutDuration := time.Duration(helpers.GetSettingInt("lockout_duration_minutes", 30)) * time.Minute
+
+	// Cari user (tidak langsung ditolak jika tidak ada, supaya respons seragam)
+	userFound := database.DB.Preload("Role").Where("email = ? OR username = ?", req.Username, req.Username).First(&user).Error == nil
+	accountKey := helpers.AccountThrottleKey(user.ID, req.Username)
+
+	// Cek lockout per IP dan per akun sebelum memeriksa password
+	if until, locked := helpers.GetLoginLock(helpers.ThrottleScopeIP, ip); locked {
+		respondLoginLocked(c, until)
+		return
+	}
+	if until, locked := helpers.GetLoginLock(helpers.ThrottleScopeAccount, accountKey); locked {
+		respondLoginLocked(c, until)
+		return
+	}
+
+	// Cek Password
+	passwordValid := false
+	if userFound {
+		passwordValid = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) == nil
+	} else {
+		helpers.CompareDummyPassword(req.Password)
+	}
+
+	if !passwordValid {
+		var userID *uint
+		reason := "user_not_found"
+		if userFound {
+			userID = &user.ID
+			reason = "invalid_password"
+		}
+
+		accountLockedUntil, err := helpers.RegisterLoginFailure(helpers.ThrottleScopeAccount, accountKey, userID, maxAttempts, lockoutDuration)
+		if err != nil {
+			log.Printf("Login: gagal mencatat percobaan login akun: %v", err)
+		}
+		ipLockedUntil, err := helpers.RegisterLoginFailure(helpers.ThrottleScopeIP, ip, nil, maxAttemptsPerIP, lockoutDuration)
+		if err != nil {
+			log.Printf("Login: gagal mencatat percobaan login IP: %v", err)
+		}
+
+		CreateAuditLog(
+			user.ID,
+			"login_failed",
+			"user",
+			user.ID,
+			nil,
+			gin.H{"identifier": req.Username, "reason": reason},
+			"Percobaan login gagal",
+			ip,
+			c.Request.UserAgent(),
+		)
+
+		if accountLockedUntil != nil || ipLockedUntil != nil {
+			lockedUntil := accountLockedUntil
+			scope := helpers.ThrottleScopeAccount
+			if lockedUntil == nil {
+				lockedUntil = ipLockedUntil
+				scope = helpers.ThrottleScopeIP
+			}
+
+			CreateAuditLog(
+				user.ID,
+				"login_locked",
+				"user",
+				user.ID,
+				nil,
+				gin.H{"identifier": req.Username, "scope": scope, "locked_until": lockedUntil},
+				"Login dikunci sementara karena terlalu banyak percobaan gagal",
+				ip,
+				c.Request.UserAgent(),
+			)
+
+			respondLoginLocked(c, *lockedUntil)
+			return
+		}
+
 		c.JSON(http.StatusUnauthorized, structs.ErrorResponse{
 			Success: false,
-			Message: "User Not Found (Email/Username salah)",
+			Message: "Username/email atau password salah",
+		})
+		return
+	}
+
+	// Password benar tetapi 2FA aktif: minta kode di tahap kedua.
+	// Hitungan gagal belum direset supaya tebakan kode tetap dibatasi lockout akun.
+	if helpers.IsTwoFactorEnabled(user.ID) {
+		ticket, expiresAt, err := helpers.GenerateTwoFactorTicket(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+				Success: false,
+				Message: "Gagal memulai verifikasi dua faktor",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, structs.SuccessResponse{
+			Success: true,
+			Message: "Masukkan kode dari aplikasi authenticator",
+			Data: gin.H{
+				"two_factor_required":   true,
+				"two_factor_token":      ticket,
+				"two_factor_expires_at": expiresAt.Format(time.RFC3339),
+			},
+		})
+		return
+	}
+
+	completeLogin(c, user, req.DeviceName)
+}
+
+// LoginTwoFactor menyelesaikan login untuk user dengan 2FA aktif
+// memakai kode TOTP atau salah satu recovery code
+func LoginTwoFactor(c *gin.Context) {
+	var req structs.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
 			Errors:  helpers.TranslateErrorMessage(err),
 		})
 		return
 	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  map[string]string{"code": "Kode authenticator atau recovery code wajib diisi"},
+		})
+		return
+	}
 
-	// Cek Password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	userID, err := helpers.ParseTwoFactorTicket(req.TwoFactorToken)
+	if err != nil {
 		c.JSON(http.StatusUnauthorized, structs.ErrorResponse{
 			Success: false,
-			Message: "Invalid Password",
-			Errors:  helpers.TranslateErrorMessage(err),
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// Generate Token
-	token := helpers.GenerateToken(user.ID)
+	var user models.User
+	if err := database.DB.Preload("Role").First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, structs.ErrorResponse{
+			Success: false,
+			Message: helpers.ErrInvalidTwoFactorTicket.Error(),
+		})
+		return
+	}
+
+	ip := c.ClientIP()
+	accountKey := helpers.AccountThrottleKey(user.ID, user.Username)
+	if until, locked := helpers.GetLoginLock(helpers.ThrottleScopeIP, ip); locked {
+		respondLoginLocked(c, until)
+		return
+	}
+	if until, locked := helpers.GetLoginLock(helpers.ThrottleScopeAccount, accountKey); locked {
+		respondLoginLocked(c, until)
+		return
+	}
+
+	method := "totp"
+	if req.Code != "" {
+		err = helpers.VerifyTwoFactorCode(user.ID, req.Code)
+	} else {
+		method = "recovery_code"
+		err = helpers.UseRecoveryCode(user.ID, req.RecoveryCode)
+	}
+
+	if err != nil {
+		if err != helpers.ErrInvalidTwoFactorCode && err != helpers.ErrTwoFactorNotEnabled {
+			log.Printf("LoginTwoFactor: gagal memverifikasi kode: %v", err)
+		}
+
+		// Kode salah dihitung sebagai login gagal (lockout yang sama dengan password)
+		lockoutDuration := time.Duration(helpers.GetSettingInt("lockout_duration_minutes", 30)) * time.Minute
+		lockedUntil, lockErr := helpers.RegisterLoginFailure(helpers.ThrottleScopeAccount, accountKey, &user.ID, helpers.GetSettingInt("max_login_attempts", 5), lockoutDuration)
+		if lockErr != nil {
+			log.Printf("LoginTwoFactor: gagal mencatat percobaan login akun: %v", lockErr)
+		}
+
+		CreateAuditLog(
+			user.ID,
+			"login_2fa_failed",
+			"user",
+			user.ID,
+			nil,
+			gin.H{"method": method},
+			"Kode autentikasi dua faktor salah",
+			ip,
+			c.Request.UserAgent(),
+		)
+
+		if lockedUntil != nil {
+			respondLoginLocked(c, *lockedUntil)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, structs.ErrorResponse{
+			Success: false,
+			Message: helpers.ErrInvalidTwoFactorCode.Error(),
+		})
+		return
+	}
+
+	if method == "recovery_code" {
+		CreateAuditLog(
+			user.ID,
+			"2fa_recovery_used",
+			"user",
+			user.ID,
+			nil,
+			gin.H{"remaining": helpers.CountRecoveryCodes(user.ID)},
+			"Login memakai recovery code 2FA",
+			ip,
+			c.Request.UserAgent(),
+		)
+	}
+
+	completeLogin(c, user, req.DeviceName)
+}
+
+// completeLogin mereset hitungan gagal, membuat sesi baru, dan mengirim respons login
+func completeLogin(c *gin.Context, user models.User, deviceName string) {
+	// Login berhasil, reset hitungan gagal akun
+	helpers.ClearLoginFailures(helpers.ThrottleScopeAccount, helpers.AccountThrottleKey(user.ID, ""))
+
+	// Buat sesi baru + pasangan access/refresh token
+	if deviceName == "" {
+		deviceName = c.Request.UserAgent()
+	}
+	tokens, err := helpers.CreateSession(user.ID, deviceName, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat sesi login",
+		})
+		return
+	}
+	tokenExpiresAt := tokens.AccessExpiresAt.Format(time.RFC3339)
+	refreshExpiresAt := tokens.RefreshExpiresAt.Format(time.RFC3339)
 
 	c.JSON(http.StatusOK, structs.SuccessResponse{
 		Success: true,
@@ -61,7 +286,23 @@ func Login(c *gin.Context) {
 			Avatar:    user.Avatar,
 			CreatedAt: user.CreatedAt.String(),
 			UpdatedAt: user.UpdatedAt.String(),
-			Token:     &token,
+			Token:     &tokens.AccessToken,
+
+			TokenExpiresAt:         &tokenExpiresAt,
+			RefreshToken:           &tokens.RefreshToken,
+			RefreshExpiresAt:       &refreshExpiresAt,
+			Permissions:            helpers.GetUserPermissions(user.ID),
+			TwoFactorSetupRequired: helpers.TwoFactorSetupPending(user.ID),
 		},
 	})
 }
+
+// respondLoginLocked mengirim respons seragam saat login sedang dikunci
+func respondLoginLocked(c *gin.Context, until time.Time) {
+	minutes := int(time.Until(until).Minutes()) + 1
+	c.Header("Retry-After", strconv.Itoa(int(time.Until(until).Seconds())+1))
+	c.JSON(http.StatusTooManyRequests, structs.ErrorResponse{
+		Success: false,
+		Message: fmt.Sprintf("Terlalu banyak percobaan login gagal. Silakan coba lagi dalam %d menit.", minutes),
+	})
+}
@@ -0,0 +1,135 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"os"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/routes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	hasDB   bool
+	router  *gin.Engine
+	fixture atomic.Int64
+)
+
+// Test alur API memakai database dari TEST_DB_DSN dan fake payment provider
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("PAYMENT_PROVIDER", "fake")
+
+	ok, err := database.ConnectTest()
+	if err != nil {
+		log.Fatalf("Gagal menyiapkan database test: %v", err)
+	}
+	hasDB = ok
+	if hasDB {
+		router = routes.SetupRouter()
+	}
+	os.Exit(m.Run())
+}
+
+func requireDB(t *testing.T) {
+	t.Helper()
+	if !hasDB {
+		t.Skip("TEST_DB_DSN tidak di-set, test database dilewati")
+	}
+}
+
+// uniqueSuffix membuat akhiran unik untuk data test (email, username, slug)
+func uniqueSuffix() string {
+	return fmt.Sprintf("%d-%d", time.Now().UnixNano(), fixture.Add(1))
+}
+
+func createUser(t *testing.T) models.User {
+	t.Helper()
+	suffix := uniqueSuffix()
+	role := models.Role{Name: "Test", Code: "test-" + suffix}
+	if err := database.DB.Create(&role).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	user := models.User{
+		Name:     "User " + suffix,
+		Username: "user-" + suffix,
+		Email:    "user-" + suffix + "@example.test",
+		RoleID:   role.ID,
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func createEvent(t *testing.T, organizer models.User, price int64, quota int) models.Event {
+	t.Helper()
+	event := models.Event{
+		Title:         "Event Test",
+		Slug:          "event-test-" + uniqueSuffix(),
+		Status:        "published",
+		EventType:     "offline",
+		StartDate:     time.Now().Add(7 * 24 * time.Hour),
+		EndDate:       time.Now().Add(7*24*time.Hour + 3*time.Hour),
+		Price:         price,
+		Quota:         quota,
+		PaymentMethod: models.EventPaymentGateway,
+		CreatedByID:   organizer.ID,
+	}
+	if err := database.DB.Create(&event).Error; err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	return event
+}
+
+// login membuat sesi untuk user dan mengembalikan access token-nya
+func login(t *testing.T, user models.User) string {
+	t.Helper()
+	pair, err := helpers.CreateSession(user.ID, "test", "127.0.0.1", "go-test")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return pair.AccessToken
+}
+
+// doJSON mengirim request JSON ke router dan men-decode response-nya ke map
+func doJSON(t *testing.T, method, path, token string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var result map[string]interface{}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("%s %s: response bukan JSON (%d): %s", method, path, rec.Code, rec.Body.String())
+		}
+	}
+	return rec.Code, result
+}
+
+func requireStatus(t *testing.T, label string, got, want int, body map[string]interface{}) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("%s: status %d, want %d (body %v)", label, got, want, body)
+	}
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batas permintaan reset password per email dalam satu jam
+const passwordResetMaxPerHour = 3
+
+// ForgotPassword mengirim link reset password ke email user.
+// Respons selalu sama, baik email terdaftar maupun tidak, supaya tidak bisa dipakai untuk menebak akun.
+func ForgotPassword(c *gin.Context) {
+	var req structs.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	response := structs.SuccessResponse{
+		Success: true,
+		Message: "Jika email terdaftar, link reset password telah dikirim. Silakan cek inbox Anda.",
+	}
+
+	var user models.User
+	if err := database.DB.Where("email = ?", strings.TrimSpace(req.Email)).First(&user).Error; err != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Rate limit per email
+	var recent int64
+	database.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-time.Hour)).
+		Count(&recent)
+	if recent >= passwordResetMaxPerHour {
+		log.Printf("ForgotPassword: rate limit tercapai untuk user %d", user.ID)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	token, err := helpers.GenerateRandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat token reset password",
+		})
+		return
+	}
+
+	expiryMinutes := helpers.GetSettingInt("password_reset_expiry_minutes", 60)
+	now := time.Now()
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Token lama yang belum dipakai dibatalkan, hanya link terbaru yang berlaku
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: helpers.HashToken(token),
+			ExpiresAt: now.Add(time.Duration(expiryMinutes) * time.Minute),
+			IPAddress: c.ClientIP(),
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat token reset password",
+		})
+		return
+	}
+
+	resetURL := frontendURL() + "/reset-password?token=" + url.QueryEscape(token)
+	go func() {
+		if err := helpers.SendPasswordResetEmail(user.Email, user.Name, resetURL, expiryMinutes); err != nil {
+			log.Printf("ForgotPassword: gagal mengirim email ke user %d: %v", user.ID, err)
+		}
+	}()
+
+	CreateAuditLog(
+		user.ID,
+		"password_reset_requested",
+		"user",
+		user.ID,
+		nil,
+		nil,
+		"Permintaan reset password",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword mengganti password menggunakan token dari email, lalu mengeluarkan semua sesi
+func ResetPassword(c *gin.Context) {
+	var req structs.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	invalid := structs.ErrorResponse{
+		Success: false,
+		Message: "Link reset password tidak valid atau sudah kadaluarsa",
+	}
+
+	var resetToken models.PasswordResetToken
+	if err := database.DB.Where("token_hash = ?", helpers.HashToken(req.Token)).First(&resetToken).Error; err != nil {
+		c.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+
+	now := time.Now()
+	if resetToken.UsedAt != nil || now.After(resetToken.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Tandai terpakai secara bersyarat, supaya token tidak bisa dipakai dua kali bersamaan
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ?", resetToken.UserID).
+			Update("password", helpers.HashPassword(req.Password)).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengganti password",
+		})
+		return
+	}
+
+	// Keluarkan semua sesi dan buka lockout login akun
+	revoked := helpers.RevokeAllSessions(resetToken.UserID, 0, "password_reset")
+	helpers.ClearLoginFailures(helpers.ThrottleScopeAccount, helpers.AccountThrottleKey(resetToken.UserID, ""))
+
+	CreateAuditLog(
+		resetToken.UserID,
+		"password_reset",
+		"user",
+		resetToken.UserID,
+		nil,
+		gin.H{"revoked_sessions": revoked},
+		"Password direset melalui link email",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Password berhasil direset. Silakan login dengan password baru.",
+	})
+}
+
+// frontendURL mengambil base URL frontend untuk link di email
+func frontendURL() string {
+	base := os.Getenv("FRONTEND_URL")
+	if base == "" {
+		base = "http://localhost:3000"
+	}
+	return strings.TrimRight(base, "/")
+}
+
+// CleanupPasswordResetTokens menghapus token reset password yang sudah kadaluarsa lebih dari 1 hari
+// Dijalankan oleh scheduler (lihat main.go)
+func CleanupPasswordResetTokens() error {
+	return database.DB.
+		Where("expires_at < ?", time.Now().AddDate(0, 0, -1)).
+		Delete(&models.PasswordResetToken{}).Error
+}
@@ -1,7 +1,8 @@
 package controllers
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"santrikoding/backend-api/database"
@@ -12,10 +13,16 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// InitiatePayment memulai proses pembayaran dengan Midtrans
+// snapTokenLifetime adalah masa berlaku token Snap Midtrans (default 24 jam)
+const snapTokenLifetime = 24 * time.Hour
+
+// InitiatePayment memulai proses pembayaran dengan payment provider aktif
 func InitiatePayment(c *gin.Context) {
+	userID, _ := currentSession(c)
 	registrationID := c.Param("id")
 	var registration models.Registration
 
@@ -29,30 +36,57 @@ func InitiatePayment(c *gin.Context) {
 		return
 	}
 
-	// Cek apakah sudah dibayar
-	if registration.PaidAt.Unix() > 0 {
-		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+	if !canAccessRegistrationPayment(userID, registration) {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
 			Success: false,
-			Message: "Pembayaran sudah dilakukan",
-			Errors:  map[string]string{"error": "Payment already completed"},
+			Message: "Anda tidak memiliki akses ke pembayaran registrasi ini",
 		})
 		return
 	}
 
-	// Cek apakah status sudah confirmed
-	if registration.Status == "confirmed" {
+	// Baris registrasi dikunci sampai tagihan tersimpan, sehingga permintaan bersamaan tidak membuat
+	// dua tagihan terbuka: permintaan berikutnya memakai ulang tagihan yang baru dibuat
+	var payment *models.Payment
+	var blocked string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.Registration
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, registration.ID).Error; err != nil {
+			return err
+		}
+		if blocked = paymentBlockedMessage(locked); blocked != "" {
+			return nil
+		}
+
+		// Pakai ulang payment pending yang masih berlaku (token Snap belum kadaluarsa, atau tagihan
+		// transfer manual yang belum diverifikasi) supaya user tidak membayar dua kali
+		var latest models.Payment
+		if err := tx.Where("registration_id = ?", locked.ID).Order("id DESC").First(&latest).Error; err == nil &&
+			latest.Status == models.PaymentStatusPending {
+			reusable := latest.Provider == models.PaymentProviderManual &&
+				registration.Event.PaymentMethod == models.EventPaymentManualTransfer
+			if latest.SnapToken != "" && latest.Provider == services.DefaultPaymentProviderName() &&
+				time.Since(latest.CreatedAt) < snapTokenLifetime {
+				reusable = true
+			}
+			if reusable {
+				payment = &latest
+				return nil
+			}
+		}
+
+		var err error
+		payment, err = createPayment(tx, locked, registration.User, registration.Event)
+		return err
+	})
+	if blocked != "" {
 		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
 			Success: false,
-			Message: "Registrasi sudah dikonfirmasi",
-			Errors:  map[string]string{"error": "Registration already confirmed"},
+			Message: blocked,
 		})
 		return
 	}
-
-	// Generate snap token (akan return order ID juga)
-	token, redirectURL, orderID, err := services.GetSnapToken(registration, registration.User, registration.Event)
 	if err != nil {
-		log.Printf("Error generating snap token: %v", err)
+		log.Printf("Error creating payment: %v", err)
 		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
 			Success: false,
 			Message: "Gagal membuat token pembayaran",
@@ -61,154 +95,412 @@ func InitiatePayment(c *gin.Context) {
 		return
 	}
 
-	// Simpan token dan order ID ke database
-	registration.PaymentToken = token
-	registration.PaymentURL = redirectURL
-	registration.OrderID = orderID
-	if err := database.DB.Save(&registration).Error; err != nil {
-		log.Printf("Error saving payment token: %v", err)
-		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
-			Success: false,
-			Message: "Gagal menyimpan token pembayaran",
-			Errors:  map[string]string{"error": err.Error()},
-		})
-		return
-	}
-
 	// Return token ke frontend
 	c.JSON(http.StatusOK, structs.SuccessResponse{
 		Success: true,
 		Message: "Token pembayaran berhasil dibuat",
-		Data: gin.H{
-			"token":        token,
-			"redirect_url": redirectURL,
-		},
+		Data:    paymentInstructions(payment, registration.Event),
 	})
 }
 
+// paymentBlockedMessage mengembalikan alasan registrasi tidak bisa dibuatkan tagihan baru
+// (kosong jika boleh): sudah dibayar, sudah dikonfirmasi atau sudah dibatalkan
+func paymentBlockedMessage(registration models.Registration) string {
+	switch {
+	case registration.PaidAt.Unix() > 0:
+		return "Pembayaran sudah dilakukan"
+	case registration.Status == "confirmed" || registration.Status == "checked_in":
+		return "Registrasi sudah dikonfirmasi"
+	case registration.Status == "cancelled" || registration.Status == "refunded":
+		return "Registrasi sudah dibatalkan"
+	}
+	return ""
+}
+
+// canAccessRegistrationPayment mengecek akses ke pembayaran sebuah registrasi:
+// pemilik registrasi (ketua untuk pendaftaran grup) atau bendahara event (payment.verify)
+func canAccessRegistrationPayment(userID uint, registration models.Registration) bool {
+	return registration.UserID == userID || helpers.HasEventPermission(userID, registration.EventID, "payment.verify")
+}
+
+// paymentInstructions menyusun data yang dibutuhkan frontend untuk membayar sebuah payment:
+// token Snap untuk payment gateway, atau rekening tujuan untuk transfer manual
+func paymentInstructions(payment *models.Payment, event models.Event) gin.H {
+	if payment.Provider == models.PaymentProviderManual {
+		return gin.H{
+			"method":         models.EventPaymentManualTransfer,
+			"order_id":       payment.OrderID,
+			"amount":         payment.Amount,
+			"bank_name":      event.TransferBankName,
+			"account_number": event.TransferAccountNumber,
+			"account_name":   event.TransferAccountName,
+		}
+	}
+	return gin.H{
+		"method":       models.EventPaymentGateway,
+		"token":        payment.SnapToken,
+		"redirect_url": payment.RedirectURL,
+		"order_id":     payment.OrderID,
+	}
+}
+
+// createPayment membuat tagihan di payment provider aktif dan mencatatnya sebagai payment pending.
+// tx adalah transaksi pemanggil, misal yang memegang lock baris registrasinya.
+func createPayment(tx *gorm.DB, registration models.Registration, user models.User, event models.Event) (*models.Payment, error) {
+	// Harga harus > 0, jika tidak berarti event/tiket gratis dan tidak perlu payment
+	if registration.Price <= 0 {
+		return nil, fmt.Errorf("event ini gratis, tidak memerlukan pembayaran")
+	}
+
+	// Transfer manual: tagihan hanya dicatat, lunas setelah bukti transfer diverifikasi bendahara
+	if event.PaymentMethod == models.EventPaymentManualTransfer {
+		payment := models.Payment{
+			RegistrationID: registration.ID,
+			Provider:       models.PaymentProviderManual,
+			OrderID:        services.NewOrderID(registration.ID),
+			Amount:         registration.Price,
+			Status:         models.PaymentStatusPending,
+			PaymentType:    models.EventPaymentManualTransfer,
+		}
+		if err := helpers.CreatePayment(tx, &payment, "initiate"); err != nil {
+			return nil, err
+		}
+		return &payment, nil
+	}
+
+	provider, err := services.DefaultPaymentProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	orderID := services.NewOrderID(registration.ID)
+	charge, err := provider.CreateCharge(services.ChargeRequest{
+		OrderID:       orderID,
+		Amount:        registration.Price,
+		CustomerName:  user.Name,
+		CustomerEmail: user.Email,
+		ItemID:        fmt.Sprintf("EVENT-%d", event.ID),
+		ItemName:      event.Title,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payment := models.Payment{
+		RegistrationID: registration.ID,
+		Provider:       provider.Name(),
+		OrderID:        orderID,
+		Amount:         registration.Price,
+		Status:         models.PaymentStatusPending,
+		SnapToken:      charge.Token,
+		RedirectURL:    charge.RedirectURL,
+		RawResponse:    string(charge.Raw),
+	}
+	if err := helpers.CreatePayment(tx, &payment, "initiate"); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// onPaymentTransition menjalankan efek samping setelah status payment berubah
+// (aktivitas dan notifikasi ke peserta). Dipanggil dari webhook maupun cek status manual.
+func onPaymentTransition(registrationID uint, transition *helpers.PaymentTransition) {
+	if transition.Refund != nil {
+		refundLateSettlement(transition.Refund)
+	}
+	if !transition.RegistrationConfirmed && !transition.RegistrationRejected && !transition.RegistrationRefunded {
+		return
+	}
+
+	var registration models.Registration
+	if err := database.DB.Preload("Event").First(&registration, registrationID).Error; err != nil {
+		return
+	}
+
+	if transition.RegistrationConfirmed {
+		log.Printf("Registration %d confirmed - Payment successful", registration.ID)
+
+		// Catat aktivitas pembayaran berhasil
+		CreateActivity(registration.UserID, "payment_completed", "event", registration.Event.ID,
+			"menyelesaikan pembayaran event "+registration.Event.Title)
+
+		// Kirim notifikasi payment success ke peserta
+		go helpers.NotifyPaymentSuccess(registration.UserID, registration.Event.Title, registration.Event.Slug)
+
+		// Kirim invoice PDF sebagai bukti pembayaran
+		go sendPaymentReceipt(registration.ID)
+
+		// Pendaftaran grup: tiket peserta lainnya ikut terkonfirmasi
+		for _, member := range helpers.GetGroupMembers(database.DB, &registration) {
+			if member.Status == "confirmed" {
+				sendTicketConfirmation(member)
+			}
+		}
+	}
+	if transition.RegistrationRejected {
+		log.Printf("Registration %d rejected - Payment %s", registration.ID, transition.To)
+
+		// Kirim notifikasi payment gagal, kursinya ditawarkan ke waitlist
+		go helpers.NotifyRegistrationRejected(registration.UserID, registration.Event.Title, "Pembayaran dibatalkan atau expired")
+		go helpers.PromoteWaitlist(registration.EventID)
+	}
+	if transition.RegistrationRefunded {
+		// Refund dari luar endpoint pembatalan (misal lewat dashboard provider)
+		log.Printf("Registration %d refunded - Payment %s", registration.ID, transition.To)
+
+		go helpers.NotifyRefundProcessed(registration.UserID, registration.Event.Title, 0)
+	}
+}
+
+// refundLateSettlement mengembalikan dana payment yang lunas setelah registrasinya dibatalkan, atau
+// setelah kursi registrasi yang ditolak sudah habis terpakai. Refund transfer manual tetap pending sampai bendahara mentransfer balik; refund gateway yang
+// gagal bisa dicoba ulang lewat endpoint pembatalan registrasi.
+func refundLateSettlement(refund *models.Refund) {
+	var payment models.Payment
+	if err := database.DB.First(&payment, refund.PaymentID).Error; err != nil {
+		return
+	}
+
+	if err := executeRefund(&payment, refund); err != nil {
+		log.Printf("Refund %s for late payment %d failed: %v", refund.RefundKey, payment.ID, err)
+	}
+
+	action := "refund_succeeded"
+	switch refund.Status {
+	case models.RefundStatusPending:
+		action = "refund_requested"
+	case models.RefundStatusFailed:
+		action = "refund_failed"
+	}
+	CreateAuditLog(
+		0,
+		action,
+		"payment",
+		payment.ID,
+		nil,
+		gin.H{"refund_id": refund.ID, "refund_key": refund.RefundKey, "amount": refund.Amount, "status": refund.Status},
+		fmt.Sprintf("Refund otomatis Rp %d untuk registrasi #%d: %s", refund.Amount, refund.RegistrationID, refund.Reason),
+		"",
+		"",
+	)
+
+	if refund.Status == models.RefundStatusSucceeded {
+		var registration models.Registration
+		if err := database.DB.Preload("Event").First(&registration, refund.RegistrationID).Error; err == nil {
+			go helpers.NotifyRefundProcessed(registration.UserID, registration.Event.Title, refund.Amount)
+		}
+	}
+}
+
 // HandleNotification menangani webhook notifikasi dari Midtrans
 func HandleNotification(c *gin.Context) {
-	var notificationPayload map[string]interface{}
+	provider, err := services.GetPaymentProvider("midtrans")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Bind notification payload
-	if err := c.ShouldBindJSON(&notificationPayload); err != nil {
-		log.Printf("Error binding notification payload: %v", err)
+	rawBody, err := c.GetRawData()
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification payload"})
 		return
 	}
 
-	// Log notification untuk debugging
-	notificationJSON, _ := json.MarshalIndent(notificationPayload, "", "  ")
-	log.Printf("Received Midtrans notification: %s", string(notificationJSON))
+	processNotification(c, provider, rawBody)
+}
 
-	// Extract order ID
-	orderID, exists := notificationPayload["order_id"]
-	if !exists {
-		log.Println("Order ID tidak ditemukan dalam notification")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID not found"})
+// SimulatePaymentNotification mensimulasikan webhook dari fake provider, supaya alur
+// registrasi sampai konfirmasi bisa diuji tanpa payment gateway. Hanya aktif jika PAYMENT_PROVIDER=fake.
+func SimulatePaymentNotification(c *gin.Context) {
+	if services.DefaultPaymentProviderName() != "fake" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
 		return
 	}
 
-	orderIDStr, ok := orderID.(string)
-	if !ok {
-		log.Println("Order ID bukan string")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID type"})
+	var req struct {
+		OrderID           string `json:"order_id" binding:"required"`
+		TransactionStatus string `json:"transaction_status" binding:"required"` // settlement, pending, expire, cancel, deny
+		FraudStatus       string `json:"fraud_status"`
+		GrossAmount       string `json:"gross_amount"` // Kosong = sesuai tagihan
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
 		return
 	}
 
-	// Parse registration ID dari order ID
-	registrationID, err := services.ParseOrderID(orderIDStr)
+	provider, _ := services.GetPaymentProvider("fake")
+	rawBody, err := provider.(*services.FakeProvider).BuildNotification(req.OrderID, req.TransactionStatus, req.FraudStatus, req.GrossAmount)
 	if err != nil {
-		log.Printf("Error parsing order ID: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID format"})
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Ambil data registration
-	var registration models.Registration
-	if err := database.DB.First(&registration, registrationID).Error; err != nil {
-		log.Printf("Registration not found: %v", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Registration not found"})
+	processNotification(c, provider, rawBody)
+}
+
+// processNotification memproses payload webhook dari sebuah provider.
+// Setiap payload disimpan ke payment_notifications; hanya payload dengan signature valid
+// yang diproses, dan tiap kombinasi (order_id, transaction_status) hanya diproses sekali.
+func processNotification(c *gin.Context, provider services.PaymentProvider, rawBody []byte) {
+	record := models.PaymentNotification{
+		Provider:   provider.Name(),
+		Result:     "received",
+		RawPayload: string(rawBody),
+		IPAddress:  c.ClientIP(),
+	}
+
+	// Verifikasi signature sebelum mempercayai isi payload
+	notification, err := provider.ParseNotification(rawBody)
+	if notification == nil {
+		log.Printf("Error parsing %s notification: %v", provider.Name(), err)
+		record.Result = "invalid_payload"
+		record.Message = truncateString(err.Error(), 255)
+		database.DB.Create(&record)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification payload"})
 		return
 	}
 
-	// Cek status transaksi
-	transactionStatus, exists := notificationPayload["transaction_status"]
-	if !exists {
-		log.Println("Transaction status tidak ditemukan")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Transaction status not found"})
+	record.OrderID = notification.OrderID
+	record.TransactionID = notification.TransactionID
+	record.TransactionStatus = notification.TransactionStatus
+	record.FraudStatus = notification.FraudStatus
+	record.StatusCode = notification.StatusCode
+	record.GrossAmount = notification.GrossAmount
+	record.SignatureValid = err == nil
+	if err := database.DB.Create(&record).Error; err != nil {
+		log.Printf("Error saving payment notification: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store notification"})
 		return
 	}
 
-	statusStr, ok := transactionStatus.(string)
-	if !ok {
-		log.Println("Transaction status bukan string")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction status type"})
+	log.Printf("Received %s notification #%d - Order ID: %s, Status: %s, Fraud: %s", provider.Name(),
+		record.ID, notification.OrderID, notification.TransactionStatus, notification.FraudStatus)
+
+	if err == services.ErrInvalidSignature {
+		log.Printf("Payment notification #%d ditolak: signature tidak valid", record.ID)
+		finishNotification(&record, "invalid_signature", "signature_key tidak cocok")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid signature"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error verifying notification signature: %v", err)
+		finishNotification(&record, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Signature verification unavailable"})
 		return
 	}
 
-	var fraudStatus string
-	if fraudStatusVal, exists := notificationPayload["fraud_status"]; exists {
-		if fraudStr, ok := fraudStatusVal.(string); ok {
-			fraudStatus = fraudStr
-		}
+	if notification.OrderID == "" || notification.TransactionStatus == "" {
+		finishNotification(&record, "invalid_payload", "order_id atau transaction_status kosong")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID or transaction status not found"})
+		return
 	}
 
-	log.Printf("Payment notification - Order ID: %s, Status: %s, Fraud: %s", orderIDStr, statusStr, fraudStatus)
+	// Cari payment berdasarkan order ID (harus dari provider yang sama)
+	var payment models.Payment
+	if err := database.DB.Where("order_id = ? AND provider = ?", notification.OrderID, provider.Name()).First(&payment).Error; err != nil {
+		log.Printf("Payment not found for order %s: %v", notification.OrderID, err)
+		finishNotification(&record, "invalid_payload", "Payment tidak ditemukan")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return
+	}
+	database.DB.Model(&record).Update("payment_id", payment.ID)
+
+	// Klaim dedupe key: notifikasi ulang untuk status yang sama tidak diproses dua kali
+	dedupeKey := notification.OrderID + ":" + notification.TransactionStatus
+	if err := database.DB.Model(&record).Update("dedupe_key", dedupeKey).Error; err != nil {
+		if helpers.IsDuplicateEntryError(err) {
+			log.Printf("Payment notification #%d duplikat (%s), dilewati", record.ID, dedupeKey)
+			finishNotification(&record, "duplicate", "Sudah diproses sebelumnya")
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			return
+		}
+		finishNotification(&record, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store notification"})
+		return
+	}
 
-	// Update status berdasarkan transaction status
-	// Preload Event untuk notifikasi
-	database.DB.Preload("Event").First(&registration, registration.ID)
+	// Nominal harus sama dengan tagihan payment, selain itu jangan konfirmasi
+	if notification.Status == models.PaymentStatusPaid && !services.GrossAmountMatches(notification.GrossAmount, payment.Amount) {
+		log.Printf("Payment %d - gross_amount %s tidak sesuai tagihan %d", payment.ID, notification.GrossAmount, payment.Amount)
+		finishNotification(&record, "amount_mismatch",
+			fmt.Sprintf("gross_amount %s, tagihan %d", notification.GrossAmount, payment.Amount))
+		CreateAuditLog(
+			0,
+			"payment_amount_mismatch",
+			"payment",
+			payment.ID,
+			nil,
+			gin.H{"order_id": notification.OrderID, "gross_amount": notification.GrossAmount, "expected": payment.Amount},
+			"Notifikasi pembayaran dengan nominal tidak sesuai tagihan",
+			c.ClientIP(),
+			c.Request.UserAgent(),
+		)
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		return
+	}
 
-	switch statusStr {
-	case "settlement", "capture":
-		// Pembayaran sukses
-		// Di sandbox, biasanya langsung settlement tanpa perlu cek fraud_status
-		if fraudStatus == "" || fraudStatus == "accept" {
-			registration.Status = "confirmed"
-			registration.PaidAt = time.Now()
-			if err := database.DB.Save(&registration).Error; err != nil {
-				log.Printf("Error updating registration: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update registration"})
-				return
-			}
-			log.Printf("Registration %d confirmed - Payment successful", registrationID)
+	transition, err := helpers.ApplyPaymentStatus(payment.ID, helpers.PaymentUpdate{
+		Status:         notification.Status,
+		ProviderStatus: notification.TransactionStatus,
+		PaymentType:    notification.PaymentType,
+		TransactionID:  notification.TransactionID,
+		Source:         "notification",
+		Note:           "fraud_status: " + notification.FraudStatus,
+		Raw:            string(rawBody),
+	})
+	if err != nil {
+		// Lepas dedupe key supaya retry dari provider bisa diproses ulang
+		log.Printf("Error updating payment: %v", err)
+		database.DB.Model(&record).Update("dedupe_key", nil)
+		finishNotification(&record, "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment"})
+		return
+	}
 
-			// Catat aktivitas pembayaran berhasil
-			CreateActivity(registration.UserID, "payment_completed", "event", registration.Event.ID,
-				"menyelesaikan pembayaran event "+registration.Event.Title)
+	log.Printf("Payment %d (registration %d): %s -> %s", payment.ID, payment.RegistrationID, transition.From, transition.To)
+	onPaymentTransition(payment.RegistrationID, transition)
 
-			// Kirim notifikasi payment success ke peserta
-			go helpers.NotifyPaymentSuccess(registration.UserID, registration.Event.Title, registration.Event.Slug)
-		} else if fraudStatus == "challenge" {
-			// Jika fraud status challenge, biarkan pending (butuh verifikasi manual)
-			log.Printf("Registration %d - Payment challenged (fraud check)", registrationID)
+	// Kirim notifikasi payment pending (sekali per order berkat dedupe key)
+	if notification.TransactionStatus == "pending" {
+		var registration models.Registration
+		if err := database.DB.Preload("Event").First(&registration, payment.RegistrationID).Error; err == nil {
+			go helpers.NotifyPaymentPending(registration.UserID, registration.Event.Title, registration.Event.Slug)
 		}
-	case "deny", "expire", "cancel":
-		// Pembayaran ditolak/dibatalkan
-		registration.Status = "rejected"
-		if err := database.DB.Save(&registration).Error; err != nil {
-			log.Printf("Error updating registration: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update registration"})
-			return
-		}
-		log.Printf("Registration %d rejected - Payment %s", registrationID, statusStr)
-
-		// Kirim notifikasi payment gagal
-		go helpers.NotifyRegistrationRejected(registration.UserID, registration.Event.Title, "Pembayaran dibatalkan atau expired")
-	case "pending":
-		// Pembayaran masih pending (belum ada perubahan)
-		log.Printf("Registration %d - Payment still pending", registrationID)
+	}
 
-		// Kirim notifikasi payment pending
-		go helpers.NotifyPaymentPending(registration.UserID, registration.Event.Title, registration.Event.Slug)
+	message := ""
+	if !transition.Changed {
+		message = "Status payment tidak berubah (" + transition.From + ")"
 	}
+	finishNotification(&record, "processed", message)
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "payment_status": transition.To})
+}
+
+// finishNotification mencatat hasil pemrosesan sebuah notifikasi pembayaran
+func finishNotification(record *models.PaymentNotification, result, message string) {
+	now := time.Now()
+	database.DB.Model(record).Updates(map[string]interface{}{
+		"result":       result,
+		"message":      truncateString(message, 255),
+		"processed_at": now,
+	})
+}
 
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+// truncateString memotong string agar muat di kolom database
+func truncateString(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
 }
 
-// CheckPaymentStatus mengecek status pembayaran dari Midtrans dan update jika perlu
+// CheckPaymentStatus mengecek status pembayaran ke payment provider dan update jika perlu
 func CheckPaymentStatus(c *gin.Context) {
+	userID, _ := currentSession(c)
 	registrationID := c.Param("id")
 	var registration models.Registration
 
@@ -222,6 +514,14 @@ func CheckPaymentStatus(c *gin.Context) {
 		return
 	}
 
+	if !canAccessRegistrationPayment(userID, registration) {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses ke pembayaran registrasi ini",
+		})
+		return
+	}
+
 	// Jika sudah confirmed, langsung return
 	if registration.Status == "confirmed" && registration.PaidAt.Unix() > 0 {
 		c.JSON(http.StatusOK, structs.SuccessResponse{
@@ -235,8 +535,9 @@ func CheckPaymentStatus(c *gin.Context) {
 		return
 	}
 
-	// Cek apakah sudah ada order ID
-	if registration.OrderID == "" {
+	// Cek apakah sudah ada payment
+	payment, ok := helpers.GetLatestPayment(registration.ID)
+	if !ok {
 		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
 			Success: false,
 			Message: "Pembayaran belum diinisiasi",
@@ -245,8 +546,35 @@ func CheckPaymentStatus(c *gin.Context) {
 		return
 	}
 
-	// Check status dari Midtrans API
-	transactionStatus, err := services.CheckPaymentStatus(registration.OrderID)
+	// Transfer manual tidak punya provider untuk dicek, status mengikuti verifikasi bendahara
+	if payment.Provider == models.PaymentProviderManual {
+		database.DB.Preload("StatusHistory").First(payment, payment.ID)
+		var proofs []models.PaymentProof
+		database.DB.Where("payment_id = ?", payment.ID).Order("id DESC").Find(&proofs)
+		c.JSON(http.StatusOK, structs.SuccessResponse{
+			Success: true,
+			Message: "Status pembayaran: " + payment.Status,
+			Data: gin.H{
+				"status":  registration.Status,
+				"paid_at": registration.PaidAt,
+				"updated": false,
+				"payment": payment,
+				"proofs":  proofs,
+			},
+		})
+		return
+	}
+
+	// Check status langsung dari provider lalu terapkan transisi yang sama seperti webhook
+	detail, transition, err := syncPaymentStatus(payment, "status_check")
+	if err == errAmountMismatch {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Nominal pembayaran tidak sesuai tagihan, silakan hubungi panitia",
+			Errors:  map[string]string{"error": "Amount mismatch"},
+		})
+		return
+	}
 	if err != nil {
 		log.Printf("Error checking payment status: %v", err)
 		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
@@ -256,49 +584,12 @@ func CheckPaymentStatus(c *gin.Context) {
 		})
 		return
 	}
+	transactionStatus := detail.TransactionStatus
 
-	log.Printf("Payment status check - Order ID: %s, Status: %s", registration.OrderID, transactionStatus)
-
-	// Update status berdasarkan transaction status
-	updated := false
-	switch transactionStatus {
-	case "settlement", "capture":
-		// Pembayaran sukses - langsung update tanpa perlu cek fraud di check status
-		if registration.Status != "confirmed" {
-			registration.Status = "confirmed"
-			registration.PaidAt = time.Now()
-			if err := database.DB.Save(&registration).Error; err != nil {
-				log.Printf("Error updating registration: %v", err)
-				c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
-					Success: false,
-					Message: "Gagal mengupdate status registrasi",
-					Errors:  map[string]string{"error": err.Error()},
-				})
-				return
-			}
-			updated = true
-			log.Printf("Registration %d confirmed - Payment successful", registrationID)
-		}
-	case "deny", "expire", "cancel":
-		// Pembayaran ditolak/dibatalkan
-		if registration.Status != "rejected" {
-			registration.Status = "rejected"
-			if err := database.DB.Save(&registration).Error; err != nil {
-				log.Printf("Error updating registration: %v", err)
-				c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
-					Success: false,
-					Message: "Gagal mengupdate status registrasi",
-					Errors:  map[string]string{"error": err.Error()},
-				})
-				return
-			}
-			updated = true
-			log.Printf("Registration %d rejected - Payment %s", registrationID, transactionStatus)
-		}
-	case "pending":
-		// Pembayaran masih pending
-		log.Printf("Registration %d - Payment still pending", registrationID)
-	}
+	// Ambil ulang data terbaru untuk response
+	database.DB.First(&registration, registration.ID)
+	database.DB.Preload("StatusHistory").First(payment, payment.ID)
+	updated := transition.RegistrationConfirmed || transition.RegistrationRejected || transition.RegistrationRefunded
 
 	c.JSON(http.StatusOK, structs.SuccessResponse{
 		Success: true,
@@ -313,6 +604,43 @@ func CheckPaymentStatus(c *gin.Context) {
 			"transaction_status": transactionStatus,
 			"paid_at":            registration.PaidAt,
 			"updated":            updated,
+			"payment":            payment,
 		},
 	})
 }
+
+// errAmountMismatch dikembalikan syncPaymentStatus jika nominal lunas dari provider tidak sesuai tagihan
+var errAmountMismatch = errors.New("nominal pembayaran tidak sesuai tagihan")
+
+// syncPaymentStatus mengambil status payment langsung dari provider lalu menerapkan transisi
+// yang sama seperti webhook. Dipakai cek status manual dan job rekonsiliasi pembayaran.
+func syncPaymentStatus(payment *models.Payment, source string) (*services.PaymentStatus, *helpers.PaymentTransition, error) {
+	provider, err := services.GetPaymentProvider(payment.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	detail, err := provider.GetStatus(payment.OrderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Payment status check - Order ID: %s, Status: %s", payment.OrderID, detail.TransactionStatus)
+
+	if detail.Status == models.PaymentStatusPaid && detail.GrossAmount != "" && !services.GrossAmountMatches(detail.GrossAmount, payment.Amount) {
+		return detail, nil, errAmountMismatch
+	}
+
+	transition, err := helpers.ApplyPaymentStatus(payment.ID, helpers.PaymentUpdate{
+		Status:         detail.Status,
+		ProviderStatus: detail.TransactionStatus,
+		PaymentType:    detail.PaymentType,
+		TransactionID:  detail.TransactionID,
+		Source:         source,
+		Raw:            string(detail.Raw),
+	})
+	if err != nil {
+		return detail, nil, err
+	}
+	onPaymentTransition(payment.RegistrationID, transition)
+	return detail, transition, nil
+}
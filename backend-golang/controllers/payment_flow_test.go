@@ -0,0 +1,225 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"testing"
+)
+
+// Alur lengkap event berbayar dengan fake provider: daftar -> tagihan dibuat -> webhook settlement
+// -> registrasi terkonfirmasi. Webhook yang sama dikirim ulang tidak diproses dua kali.
+func TestPaidRegistrationConfirmedBySettlement(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	participant := createUser(t)
+	event := createEvent(t, organizer, 75000, 10)
+	token := login(t, participant)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/participant/event/%d/register", event.ID), token,
+		map[string]interface{}{"answers": []interface{}{}})
+	requireStatus(t, "register", code, http.StatusCreated, body)
+
+	paymentData, ok := body["payment"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("register: response tanpa data payment: %v", body)
+	}
+	orderID, _ := paymentData["order_id"].(string)
+	registrationID := uint(body["data"].(map[string]interface{})["id"].(float64))
+
+	var registration models.Registration
+	database.DB.First(&registration, registrationID)
+	if registration.Status != "pending" || registration.Price != 75000 {
+		t.Fatalf("registrasi baru: status %q harga %d, want pending 75000", registration.Status, registration.Price)
+	}
+
+	settle := map[string]interface{}{"order_id": orderID, "transaction_status": "settlement"}
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "", settle)
+	requireStatus(t, "settlement", code, http.StatusOK, body)
+	if body["payment_status"] != models.PaymentStatusPaid {
+		t.Fatalf("settlement: payment_status %v, want paid", body["payment_status"])
+	}
+
+	database.DB.First(&registration, registrationID)
+	if registration.Status != "confirmed" || registration.PaidAt.IsZero() {
+		t.Fatalf("setelah settlement: status %q paid_at %v, want confirmed dengan paid_at", registration.Status, registration.PaidAt)
+	}
+
+	var payment models.Payment
+	database.DB.Preload("StatusHistory").Where("order_id = ?", orderID).First(&payment)
+	if payment.Status != models.PaymentStatusPaid || len(payment.StatusHistory) != 2 {
+		t.Fatalf("payment: status %q dengan %d riwayat, want paid dengan 2 riwayat", payment.Status, len(payment.StatusHistory))
+	}
+
+	// Notifikasi ulang dari provider hanya dicatat sebagai duplikat
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "", settle)
+	requireStatus(t, "settlement ulang", code, http.StatusOK, body)
+	var history int64
+	database.DB.Model(&models.PaymentStatusHistory{}).Where("payment_id = ?", payment.ID).Count(&history)
+	if history != 2 {
+		t.Fatalf("settlement ulang menambah riwayat status: %d, want 2", history)
+	}
+
+	code, body = doJSON(t, http.MethodGet, fmt.Sprintf("/api/payment/status/%d", registrationID), token, nil)
+	requireStatus(t, "cek status", code, http.StatusOK, body)
+}
+
+// Tagihan yang expired menolak registrasi dan melepas kursinya
+func TestPaidRegistrationRejectedWhenPaymentExpires(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	participant := createUser(t)
+	event := createEvent(t, organizer, 50000, 10)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/participant/event/%d/register", event.ID), login(t, participant),
+		map[string]interface{}{"answers": []interface{}{}})
+	requireStatus(t, "register", code, http.StatusCreated, body)
+	orderID := body["payment"].(map[string]interface{})["order_id"].(string)
+	registrationID := uint(body["data"].(map[string]interface{})["id"].(float64))
+
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "",
+		map[string]interface{}{"order_id": orderID, "transaction_status": "expire"})
+	requireStatus(t, "expire", code, http.StatusOK, body)
+
+	var registration models.Registration
+	database.DB.First(&registration, registrationID)
+	if registration.Status != "rejected" {
+		t.Fatalf("setelah expire: status %q, want rejected", registration.Status)
+	}
+}
+
+// Pelunasan yang masuk setelah tagihan expired mengonfirmasi ulang registrasi jika kursinya masih ada
+func TestLateSettlementReclaimsReleasedSeat(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	participant := createUser(t)
+	event := createEvent(t, organizer, 50000, 10)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/participant/event/%d/register", event.ID), login(t, participant),
+		map[string]interface{}{"answers": []interface{}{}})
+	requireStatus(t, "register", code, http.StatusCreated, body)
+	orderID := body["payment"].(map[string]interface{})["order_id"].(string)
+	registrationID := uint(body["data"].(map[string]interface{})["id"].(float64))
+
+	for _, status := range []string{"expire", "settlement"} {
+		code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "",
+			map[string]interface{}{"order_id": orderID, "transaction_status": status})
+		requireStatus(t, status, code, http.StatusOK, body)
+	}
+
+	var registration models.Registration
+	database.DB.First(&registration, registrationID)
+	if registration.Status != "confirmed" {
+		t.Fatalf("settlement terlambat dengan kursi kosong: status %q, want confirmed", registration.Status)
+	}
+}
+
+// Pelunasan terlambat saat kursinya sudah diambil peserta lain: registrasi tetap ditolak dan dana dikembalikan
+func TestLateSettlementRefundedWhenEventFull(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	late := createUser(t)
+	other := createUser(t)
+	event := createEvent(t, organizer, 50000, 1)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/participant/event/%d/register", event.ID), login(t, late),
+		map[string]interface{}{"answers": []interface{}{}})
+	requireStatus(t, "register", code, http.StatusCreated, body)
+	orderID := body["payment"].(map[string]interface{})["order_id"].(string)
+	registrationID := uint(body["data"].(map[string]interface{})["id"].(float64))
+
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "",
+		map[string]interface{}{"order_id": orderID, "transaction_status": "expire"})
+	requireStatus(t, "expire", code, http.StatusOK, body)
+
+	code, body = doJSON(t, http.MethodPost, fmt.Sprintf("/api/participant/event/%d/register", event.ID), login(t, other),
+		map[string]interface{}{"answers": []interface{}{}})
+	requireStatus(t, "register peserta lain", code, http.StatusCreated, body)
+
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "",
+		map[string]interface{}{"order_id": orderID, "transaction_status": "settlement"})
+	requireStatus(t, "settlement terlambat", code, http.StatusOK, body)
+
+	var registration models.Registration
+	database.DB.First(&registration, registrationID)
+	if registration.Status != "rejected" {
+		t.Fatalf("settlement terlambat saat kuota penuh: status %q, want rejected", registration.Status)
+	}
+
+	var taken int64
+	database.DB.Model(&models.Registration{}).
+		Where("event_id = ? AND status NOT IN ?", event.ID, models.ReleasedSeatStatuses).
+		Count(&taken)
+	if taken != 1 {
+		t.Fatalf("kursi terpakai %d, want 1 (kuota event)", taken)
+	}
+
+	var refund models.Refund
+	if err := database.DB.Where("registration_id = ?", registrationID).First(&refund).Error; err != nil {
+		t.Fatalf("settlement terlambat tidak membuat refund: %v", err)
+	}
+	if refund.Amount != 50000 || refund.Status != models.RefundStatusSucceeded {
+		t.Fatalf("refund: nominal %d status %q, want 50000 succeeded", refund.Amount, refund.Status)
+	}
+}
+
+// Percobaan pembayaran kedua yang ikut lunas direfund penuh, dan peserta yang sudah check-in tidak diturunkan statusnya
+func TestSecondSettlementRefundedWithoutDowngradingCheckIn(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	participant := createUser(t)
+	event := createEvent(t, organizer, 50000, 10)
+	token := login(t, participant)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/participant/event/%d/register", event.ID), token,
+		map[string]interface{}{"answers": []interface{}{}})
+	requireStatus(t, "register", code, http.StatusCreated, body)
+	firstOrder := body["payment"].(map[string]interface{})["order_id"].(string)
+	registrationID := uint(body["data"].(map[string]interface{})["id"].(float64))
+
+	// Tagihan pertama expired, peserta membuat tagihan baru, lalu tagihan pertama ternyata tetap lunas
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "",
+		map[string]interface{}{"order_id": firstOrder, "transaction_status": "expire"})
+	requireStatus(t, "expire", code, http.StatusOK, body)
+	code, body = doJSON(t, http.MethodPost, fmt.Sprintf("/api/payment/initiate/%d", registrationID), token, nil)
+	requireStatus(t, "tagihan kedua", code, http.StatusOK, body)
+	secondOrder := body["data"].(map[string]interface{})["order_id"].(string)
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "",
+		map[string]interface{}{"order_id": firstOrder, "transaction_status": "settlement"})
+	requireStatus(t, "settlement pertama", code, http.StatusOK, body)
+
+	var registration models.Registration
+	database.DB.First(&registration, registrationID)
+	if registration.Status != "confirmed" {
+		t.Fatalf("setelah settlement pertama: status %q, want confirmed", registration.Status)
+	}
+	database.DB.Model(&registration).Update("status", "checked_in")
+	paidAt := registration.PaidAt
+
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "",
+		map[string]interface{}{"order_id": secondOrder, "transaction_status": "settlement"})
+	requireStatus(t, "settlement kedua", code, http.StatusOK, body)
+
+	database.DB.First(&registration, registrationID)
+	if registration.Status != "checked_in" || !registration.PaidAt.Equal(paidAt) {
+		t.Fatalf("setelah settlement kedua: status %q paid_at %v, want checked_in dengan paid_at %v",
+			registration.Status, registration.PaidAt, paidAt)
+	}
+
+	var second models.Payment
+	database.DB.Where("order_id = ?", secondOrder).First(&second)
+	var refund models.Refund
+	if err := database.DB.Where("payment_id = ?", second.ID).First(&refund).Error; err != nil {
+		t.Fatalf("settlement kedua tidak direfund: %v", err)
+	}
+	if refund.Amount != 50000 || refund.Status != models.RefundStatusSucceeded || second.Status != models.PaymentStatusRefunded {
+		t.Fatalf("refund tagihan ganda: nominal %d status %q, payment %q, want 50000 succeeded refunded",
+			refund.Amount, refund.Status, second.Status)
+	}
+}
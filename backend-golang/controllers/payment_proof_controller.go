@@ -0,0 +1,425 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// paymentProofDir menyimpan bukti transfer di luar folder public karena berisi data rekening peserta
+const paymentProofDir = "storage/payment_proofs"
+
+// POST /api/registrations/:id/payment-proof
+// Peserta mengupload bukti transfer untuk event dengan metode transfer manual (multipart: proof,
+// sender_name, sender_bank, amount, note). Bukti baru bisa diupload lagi setelah bukti sebelumnya ditolak.
+func UploadPaymentProof(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var registration models.Registration
+	if err := database.DB.Preload("Event").Preload("User").First(&registration, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran tidak ditemukan",
+		})
+		return
+	}
+	if registration.UserID != userID {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses ke pendaftaran ini",
+		})
+		return
+	}
+	if registration.Event.PaymentMethod != models.EventPaymentManualTransfer {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Event ini tidak menggunakan pembayaran transfer manual",
+		})
+		return
+	}
+	if registration.Status != "pending" {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran ini tidak sedang menunggu pembayaran",
+		})
+		return
+	}
+
+	payment, ok := helpers.GetLatestPayment(registration.ID)
+	if !ok || payment.Provider != models.PaymentProviderManual || payment.Status != models.PaymentStatusPending {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Tagihan transfer belum dibuat, silakan lakukan pembayaran terlebih dahulu",
+		})
+		return
+	}
+
+	var pendingCount int64
+	database.DB.Model(&models.PaymentProof{}).
+		Where("payment_id = ? AND status = ?", payment.ID, models.PaymentProofPending).
+		Count(&pendingCount)
+	if pendingCount > 0 {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Bukti transfer sebelumnya masih menunggu verifikasi bendahara",
+		})
+		return
+	}
+
+	var amount int64
+	if raw := c.PostForm("amount"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Nominal transfer tidak valid",
+				Errors:  map[string]string{"amount": "Harus berupa angka lebih dari 0"},
+			})
+			return
+		}
+		amount = parsed
+	}
+
+	// Handle Upload File
+	file, err := c.FormFile("proof")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "File bukti transfer wajib diupload",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validasi file type (hanya gambar dan PDF)
+	allowedExts := []string{".jpg", ".jpeg", ".png", ".webp", ".pdf"}
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	allowed := false
+	for _, allowedExt := range allowedExts {
+		if ext == allowedExt {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Format file tidak didukung. Hanya gambar (jpg, png, webp) dan PDF",
+			Errors:  map[string]string{"error": "Invalid file format"},
+		})
+		return
+	}
+
+	if _, err := os.Stat(paymentProofDir); os.IsNotExist(err) {
+		os.MkdirAll(paymentProofDir, 0755)
+	}
+
+	filename := fmt.Sprintf("proof-payment-%d-%d-%s%s", payment.ID, time.Now().Unix(), uuid.New().String()[:8], ext)
+	savePath := filepath.Join(paymentProofDir, filename)
+	if err := c.SaveUploadedFile(file, savePath); err != nil {
+		log.Printf("Error saving payment proof: %v", err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menyimpan bukti transfer",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	proof := models.PaymentProof{
+		PaymentID:      payment.ID,
+		RegistrationID: registration.ID,
+		FilePath:       savePath,
+		OriginalName:   truncateString(file.Filename, 255),
+		SenderName:     truncateString(c.PostForm("sender_name"), 255),
+		SenderBank:     truncateString(c.PostForm("sender_bank"), 100),
+		Amount:         amount,
+		Note:           truncateString(c.PostForm("note"), 255),
+		Status:         models.PaymentProofPending,
+	}
+	if err := database.DB.Create(&proof).Error; err != nil {
+		os.Remove(savePath)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menyimpan bukti transfer",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	CreateActivity(userID, "payment_proof_uploaded", "event", registration.EventID,
+		"mengupload bukti transfer untuk event "+registration.Event.Title)
+	go helpers.NotifyPaymentProofUploaded(registration.EventID, registration.Event.Title, registration.User.Name, proof.ID)
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Bukti transfer berhasil diupload dan menunggu verifikasi bendahara",
+		Data:    proof,
+	})
+}
+
+// GET /api/payments/proofs
+// Antrian verifikasi bukti transfer (default status=pending, filter ?event_id=). Tanpa event_id
+// membutuhkan permission payment.verify global, dengan event_id cukup jabatan bendahara di event tersebut.
+func GetPaymentProofs(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.PaymentProof{}).
+		Joins("INNER JOIN registrations ON registrations.id = payment_proofs.registration_id")
+
+	eventID, _ := strconv.Atoi(c.Query("event_id"))
+	if eventID > 0 {
+		if !helpers.HasEventPermission(userID, uint(eventID), "payment.verify") {
+			c.JSON(http.StatusForbidden, structs.ErrorResponse{
+				Success: false,
+				Message: "Anda tidak memiliki akses untuk memverifikasi pembayaran event ini",
+			})
+			return
+		}
+		query = query.Where("registrations.event_id = ?", eventID)
+	} else if !helpers.HasPermission(userID, "payment.verify") {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses untuk memverifikasi pembayaran",
+		})
+		return
+	}
+
+	status := c.DefaultQuery("status", models.PaymentProofPending)
+	if status != "all" {
+		query = query.Where("payment_proofs.status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var proofs []models.PaymentProof
+	if err := query.
+		Preload("Registration.User").
+		Preload("Registration.Event").
+		Order("payment_proofs.created_at ASC").
+		Offset(offset).Limit(limit).
+		Find(&proofs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengambil bukti transfer",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar bukti transfer",
+		Data: gin.H{
+			"proofs":      proofs,
+			"total":       total,
+			"page":        page,
+			"limit":       limit,
+			"total_pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// GET /api/payments/proofs/:id/file
+// Mengunduh file bukti transfer (peserta pemilik atau bendahara event)
+func DownloadPaymentProof(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var proof models.PaymentProof
+	if err := database.DB.Preload("Registration").First(&proof, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Bukti transfer tidak ditemukan",
+		})
+		return
+	}
+	if proof.Registration.UserID != userID &&
+		!helpers.HasEventPermission(userID, proof.Registration.EventID, "payment.verify") {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses ke bukti transfer ini",
+		})
+		return
+	}
+
+	if _, err := os.Stat(proof.FilePath); err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "File bukti transfer tidak ditemukan",
+		})
+		return
+	}
+	c.FileAttachment(proof.FilePath, proof.OriginalName)
+}
+
+// POST /api/payments/proofs/:id/approve
+// Bendahara menyetujui bukti transfer: payment menjadi paid dan registrasi terkonfirmasi
+func ApprovePaymentProof(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	proof, ok := loadProofForReview(c, userID)
+	if !ok {
+		return
+	}
+
+	var payment models.Payment
+	if err := database.DB.First(&payment, proof.PaymentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Payment tidak ditemukan",
+		})
+		return
+	}
+
+	transition, err := helpers.ApplyPaymentStatus(payment.ID, helpers.PaymentUpdate{
+		Status:         models.PaymentStatusPaid,
+		ProviderStatus: "manual_verified",
+		PaymentType:    models.EventPaymentManualTransfer,
+		Source:         "manual_review",
+		Note:           fmt.Sprintf("Bukti transfer #%d disetujui", proof.ID),
+	})
+	if err != nil {
+		log.Printf("Error approving payment proof %d: %v", proof.ID, err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal memperbarui status pembayaran",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	if !transition.Changed || transition.To != models.PaymentStatusPaid {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Pembayaran ini sudah tidak menunggu verifikasi",
+		})
+		return
+	}
+
+	finishProofReview(proof, models.PaymentProofApproved, "", userID)
+	onPaymentTransition(payment.RegistrationID, transition)
+
+	CreateAuditLog(
+		userID,
+		"payment_proof_approved",
+		"payment",
+		payment.ID,
+		gin.H{"status": transition.From},
+		gin.H{"status": transition.To, "proof_id": proof.ID},
+		fmt.Sprintf("Menyetujui bukti transfer Rp %d untuk registrasi #%d", payment.Amount, payment.RegistrationID),
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Bukti transfer disetujui, pendaftaran terkonfirmasi",
+		Data:    proof,
+	})
+}
+
+type RejectPaymentProofRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// POST /api/payments/proofs/:id/reject
+// Bendahara menolak bukti transfer dengan alasan; payment tetap pending sehingga peserta bisa upload ulang
+func RejectPaymentProof(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var req RejectPaymentProofRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	proof, ok := loadProofForReview(c, userID)
+	if !ok {
+		return
+	}
+
+	finishProofReview(proof, models.PaymentProofRejected, req.Reason, userID)
+
+	CreateAuditLog(
+		userID,
+		"payment_proof_rejected",
+		"payment",
+		proof.PaymentID,
+		gin.H{"status": models.PaymentProofPending},
+		gin.H{"status": models.PaymentProofRejected, "proof_id": proof.ID},
+		req.Reason,
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+	go helpers.NotifyPaymentProofRejected(proof.Registration.UserID, proof.Registration.Event.Title, req.Reason)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Bukti transfer ditolak",
+		Data:    proof,
+	})
+}
+
+// loadProofForReview mengambil bukti transfer pending dan memastikan user boleh memverifikasinya.
+// Response error sudah ditulis jika mengembalikan false.
+func loadProofForReview(c *gin.Context, userID uint) (*models.PaymentProof, bool) {
+	var proof models.PaymentProof
+	if err := database.DB.Preload("Registration.Event").First(&proof, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Bukti transfer tidak ditemukan",
+		})
+		return nil, false
+	}
+	if !helpers.HasEventPermission(userID, proof.Registration.EventID, "payment.verify") {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses untuk memverifikasi pembayaran event ini",
+		})
+		return nil, false
+	}
+	if proof.Status != models.PaymentProofPending {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Bukti transfer ini sudah diverifikasi",
+		})
+		return nil, false
+	}
+	return &proof, true
+}
+
+// finishProofReview mencatat hasil verifikasi bukti transfer
+func finishProofReview(proof *models.PaymentProof, status, reason string, reviewerID uint) {
+	now := time.Now()
+	database.DB.Model(proof).Updates(map[string]interface{}{
+		"status":         status,
+		"reject_reason":  reason,
+		"reviewed_by_id": reviewerID,
+		"reviewed_at":    now,
+	})
+}
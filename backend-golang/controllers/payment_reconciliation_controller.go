@@ -0,0 +1,318 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/scheduler"
+	"santrikoding/backend-api/structs"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentReconciliationJob adalah nama job rekonsiliasi pembayaran di scheduler
+const PaymentReconciliationJob = "payment_reconciliation"
+
+// reconcileBatchSize membatasi jumlah order yang dicek ke provider dalam satu run
+const reconcileBatchSize = 200
+
+// ReconcilePayments mengecek ulang order pending ke payment provider (untuk webhook yang hilang),
+// mengexpire payment dan registrasi yang tidak dibayar melewati payment_expiry_hours,
+// lalu mencatat hasilnya sebagai laporan rekonsiliasi. Transfer manual tidak ikut dicek karena
+// statusnya ditentukan verifikasi bendahara. Dijalankan oleh scheduler.
+func ReconcilePayments() error {
+	run := models.PaymentReconciliationRun{StartedAt: time.Now()}
+	if err := database.DB.Create(&run).Error; err != nil {
+		return err
+	}
+
+	checkAfter := time.Duration(helpers.GetSettingInt("payment_reconcile_after_minutes", 15)) * time.Minute
+	expiryHours := helpers.GetSettingInt("payment_expiry_hours", 24)
+	expireBefore := run.StartedAt.Add(-time.Duration(expiryHours) * time.Hour)
+
+	var payments []models.Payment
+	if err := database.DB.
+		Where("status IN ? AND created_at <= ? AND provider <> ?",
+			[]string{models.PaymentStatusPending, models.PaymentStatusChallenge}, run.StartedAt.Add(-checkAfter),
+			models.PaymentProviderManual).
+		Order("id").Limit(reconcileBatchSize).
+		Find(&payments).Error; err != nil {
+		return err
+	}
+
+	for i := range payments {
+		item := reconcilePayment(&payments[i], expireBefore, expiryHours)
+		item.RunID = run.ID
+		database.DB.Create(&item)
+
+		run.Checked++
+		switch item.Action {
+		case "updated":
+			run.Updated++
+			if item.ToStatus == models.PaymentStatusPaid {
+				run.Confirmed++
+			}
+		case "expired":
+			run.Updated++
+			run.Expired++
+		case "amount_mismatch":
+			run.Mismatched++
+		case "error":
+			run.Failed++
+		}
+	}
+
+	// Registrasi berbayar yang tidak pernah berhasil membuat tagihan
+	run.Expired += expireUnpaidRegistrations(run.ID, expireBefore, expiryHours)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if err := database.DB.Save(&run).Error; err != nil {
+		return err
+	}
+
+	log.Printf("Rekonsiliasi pembayaran #%d: %d dicek, %d berubah, %d terkonfirmasi, %d kadaluarsa, %d gagal",
+		run.ID, run.Checked, run.Updated, run.Confirmed, run.Expired, run.Failed)
+	if run.Failed > 0 {
+		return fmt.Errorf("%d payment gagal direkonsiliasi, lihat laporan rekonsiliasi #%d", run.Failed, run.ID)
+	}
+	return nil
+}
+
+// reconcilePayment menyinkronkan satu payment pending dengan provider. Payment yang masih
+// pending (atau tidak bisa dicek) melewati batas waktu pembayaran ditandai expired.
+func reconcilePayment(payment *models.Payment, expireBefore time.Time, expiryHours int) models.PaymentReconciliationItem {
+	item := models.PaymentReconciliationItem{
+		PaymentID:      &payment.ID,
+		RegistrationID: payment.RegistrationID,
+		OrderID:        payment.OrderID,
+		FromStatus:     payment.Status,
+		ToStatus:       payment.Status,
+		Action:         "unchanged",
+	}
+
+	detail, transition, err := syncPaymentStatus(payment, "reconciliation")
+	if detail != nil {
+		item.ProviderStatus = detail.TransactionStatus
+	}
+	switch {
+	case err == errAmountMismatch:
+		item.Action = "amount_mismatch"
+		item.Message = fmt.Sprintf("gross_amount %s, tagihan %d", detail.GrossAmount, payment.Amount)
+		return item
+	case err != nil && !payment.CreatedAt.Before(expireBefore):
+		item.Action = "error"
+		item.Message = truncateString(err.Error(), 255)
+		return item
+	case err == nil && transition.Changed:
+		item.Action = "updated"
+		item.ToStatus = transition.To
+		return item
+	}
+
+	// Masih pending (atau order tidak ditemukan di provider) setelah batas waktu pembayaran
+	if payment.Status != models.PaymentStatusPending || !payment.CreatedAt.Before(expireBefore) {
+		return item
+	}
+	note := fmt.Sprintf("Tidak dibayar dalam %d jam", expiryHours)
+	if err != nil {
+		note += " (cek provider gagal: " + err.Error() + ")"
+	}
+	transition, err = helpers.ApplyPaymentStatus(payment.ID, helpers.PaymentUpdate{
+		Status:         models.PaymentStatusExpired,
+		ProviderStatus: item.ProviderStatus,
+		Source:         "reconciliation",
+		Note:           note,
+	})
+	if err != nil {
+		item.Action = "error"
+		item.Message = truncateString(err.Error(), 255)
+		return item
+	}
+	onPaymentTransition(payment.RegistrationID, transition)
+
+	if transition.Changed {
+		item.Action = "expired"
+		item.ToStatus = transition.To
+	}
+	item.Message = truncateString(note, 255)
+	return item
+}
+
+// expireUnpaidRegistrations menolak registrasi pending berbayar yang tidak punya
+// payment sama sekali melewati batas waktu pembayaran. Mengembalikan jumlah registrasi yang diexpire.
+func expireUnpaidRegistrations(runID uint, expireBefore time.Time, expiryHours int) int {
+	var registrations []models.Registration
+	database.DB.Preload("Event").
+		Where("registrations.status = ? AND registrations.price > 0 AND registrations.created_at <= ?", "pending", expireBefore).
+		Where("NOT EXISTS (SELECT 1 FROM payments WHERE payments.registration_id = registrations.id)").
+		Limit(reconcileBatchSize).
+		Find(&registrations)
+
+	expired := 0
+	for _, registration := range registrations {
+		result := database.DB.Model(&models.Registration{}).
+			Where("id = ? AND status = ?", registration.ID, "pending").
+			Update("status", "rejected")
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		expired++
+		if err := helpers.SyncGroupMembers(database.DB, &registration, map[string]interface{}{"status": "rejected"}); err != nil {
+			log.Printf("Error expiring group members of registration %d: %v", registration.ID, err)
+		}
+
+		database.DB.Create(&models.PaymentReconciliationItem{
+			RunID:          runID,
+			RegistrationID: registration.ID,
+			Action:         "registration_expired",
+			FromStatus:     "pending",
+			ToStatus:       "rejected",
+			Message:        fmt.Sprintf("Tidak ada pembayaran dalam %d jam", expiryHours),
+		})
+		go helpers.NotifyRegistrationRejected(registration.UserID, registration.Event.Title, "Pembayaran tidak diselesaikan")
+		go helpers.PromoteWaitlist(registration.EventID)
+	}
+	return expired
+}
+
+// GET /api/payments/reconciliation
+// Laporan rekonsiliasi untuk bendahara: ringkasan payment per status, order yang masih tertahan,
+// notifikasi bermasalah, refund gagal, dan riwayat run rekonsiliasi (permission payment.report)
+func GetPaymentReconciliationReport(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	type StatusSummary struct {
+		Status string `json:"status"`
+		Count  int64  `json:"count"`
+		Amount int64  `json:"amount"`
+	}
+	var byStatus []StatusSummary
+	database.DB.Model(&models.Payment{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount").
+		Group("status").Order("status").
+		Scan(&byStatus)
+
+	checkAfter := time.Duration(helpers.GetSettingInt("payment_reconcile_after_minutes", 15)) * time.Minute
+	var stuckPending int64
+	database.DB.Model(&models.Payment{}).
+		Where("status IN ? AND created_at <= ?",
+			[]string{models.PaymentStatusPending, models.PaymentStatusChallenge}, time.Now().Add(-checkAfter)).
+		Count(&stuckPending)
+
+	since := time.Now().AddDate(0, 0, -30)
+	var mismatchedNotifications, invalidSignatures int64
+	database.DB.Model(&models.PaymentNotification{}).
+		Where("result = ? AND created_at >= ?", "amount_mismatch", since).Count(&mismatchedNotifications)
+	database.DB.Model(&models.PaymentNotification{}).
+		Where("result = ? AND created_at >= ?", "invalid_signature", since).Count(&invalidSignatures)
+
+	var failedRefunds int64
+	database.DB.Model(&models.Refund{}).Where("status = ?", models.RefundStatusFailed).Count(&failedRefunds)
+
+	var total int64
+	database.DB.Model(&models.PaymentReconciliationRun{}).Count(&total)
+
+	var runs []models.PaymentReconciliationRun
+	if err := database.DB.Order("id DESC").Offset(offset).Limit(limit).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengambil laporan rekonsiliasi",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Laporan rekonsiliasi pembayaran",
+		Data: gin.H{
+			"summary": gin.H{
+				"payments_by_status":              byStatus,
+				"stuck_pending":                   stuckPending,
+				"amount_mismatch_notifications":   mismatchedNotifications,
+				"invalid_signature_notifications": invalidSignatures,
+				"failed_refunds":                  failedRefunds,
+			},
+			"runs":        runs,
+			"total":       total,
+			"page":        page,
+			"limit":       limit,
+			"total_pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// GET /api/payments/reconciliation/runs/:id
+// Detail satu run rekonsiliasi; filter ?action=expired|updated|amount_mismatch|error (permission payment.report)
+func GetPaymentReconciliationRun(c *gin.Context) {
+	var run models.PaymentReconciliationRun
+	if err := database.DB.First(&run, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Run rekonsiliasi tidak ditemukan",
+		})
+		return
+	}
+
+	query := database.DB.Where("run_id = ?", run.ID)
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	query.Order("id").Find(&run.Items)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Detail rekonsiliasi pembayaran",
+		Data:    run,
+	})
+}
+
+// POST /api/payments/reconciliation/run
+// Menjalankan rekonsiliasi di luar jadwal (permission payment.report)
+func TriggerPaymentReconciliation(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	jobRun, err := scheduler.Trigger(PaymentReconciliationJob, userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == scheduler.ErrJobRunning {
+			status = http.StatusConflict
+		}
+		c.JSON(status, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menjalankan rekonsiliasi: " + err.Error(),
+		})
+		return
+	}
+
+	CreateAuditLog(
+		userID,
+		"trigger",
+		"job",
+		jobRun.ID,
+		nil,
+		gin.H{"job": PaymentReconciliationJob},
+		"Menjalankan rekonsiliasi pembayaran secara manual",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusAccepted, structs.SuccessResponse{
+		Success: true,
+		Message: "Rekonsiliasi pembayaran sedang dijalankan",
+		Data:    jobRun,
+	})
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPermissions mengambil semua permission yang tersedia (permission roles.manage)
+func GetPermissions(c *gin.Context) {
+	var permissions []models.Permission
+	if err := database.DB.Order("category ASC, code ASC").Find(&permissions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengambil data permission",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar permission",
+		Data:    permissions,
+	})
+}
+
+// GetRolePermissions mengambil permission yang dimiliki sebuah role (permission roles.manage)
+func GetRolePermissions(c *gin.Context) {
+	var role models.Role
+	if err := database.DB.First(&role, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Role tidak ditemukan",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Permission role " + role.Name,
+		Data: gin.H{
+			"role":        role,
+			"permissions": rolePermissionCodes(role.ID),
+		},
+	})
+}
+
+// UpdateRolePermissions mengganti seluruh permission sebuah role (permission roles.manage)
+func UpdateRolePermissions(c *gin.Context) {
+	userIDInterface, _ := c.Get("id")
+	userID, _ := userIDInterface.(uint)
+
+	var role models.Role
+	if err := database.DB.First(&role, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Role tidak ditemukan",
+		})
+		return
+	}
+
+	var req struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	var permissions []models.Permission
+	if len(req.Permissions) > 0 {
+		database.DB.Where("code IN ?", req.Permissions).Find(&permissions)
+	}
+	if len(permissions) != len(uniqueStrings(req.Permissions)) {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Terdapat kode permission yang tidak dikenal",
+		})
+		return
+	}
+
+	// Cegah admin mengunci dirinya sendiri dari halaman pengaturan hak akses
+	var currentUser models.User
+	database.DB.First(&currentUser, userID)
+	if currentUser.RoleID == role.ID && !containsString(req.Permissions, "roles.manage") {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Tidak bisa menghapus permission roles.manage dari role Anda sendiri",
+		})
+		return
+	}
+
+	oldCodes := rolePermissionCodes(role.ID)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, p := range permissions {
+			grant := models.RolePermission{RoleID: role.ID, PermissionID: p.ID, CreatedAt: now}
+			if err := tx.Create(&grant).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menyimpan permission role",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	newCodes := rolePermissionCodes(role.ID)
+
+	CreateAuditLog(
+		userID,
+		"update",
+		"role_permission",
+		role.ID,
+		gin.H{"permissions": oldCodes},
+		gin.H{"permissions": newCodes},
+		"Mengubah hak akses role "+role.Name,
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Permission role berhasil diperbarui",
+		Data: gin.H{
+			"role":        role,
+			"permissions": newCodes,
+		},
+	})
+}
+
+// GetMyPermissions mengambil permission user yang login.
+// Jika query event_id diisi, permission dari jabatan panitia di event tersebut ikut dihitung.
+func GetMyPermissions(c *gin.Context) {
+	userIDInterface, _ := c.Get("id")
+	userID, _ := userIDInterface.(uint)
+
+	permissions := helpers.GetUserPermissions(userID)
+	if eventIDParam := c.Query("event_id"); eventIDParam != "" {
+		eventID, err := strconv.Atoi(eventIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "event_id tidak valid",
+			})
+			return
+		}
+		permissions = helpers.GetEventPermissions(userID, uint(eventID))
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Permission user",
+		Data:    permissions,
+	})
+}
+
+func rolePermissionCodes(roleID uint) []string {
+	codes := []string{}
+	database.DB.Table("permissions").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Where("role_permissions.role_id = ?", roleID).
+		Order("permissions.code").
+		Pluck("permissions.code", &codes)
+	return codes
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,463 @@
+package controllers
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoCodeRequest struct {
+	Code          string `json:"code" binding:"required,max=50"`
+	Description   string `json:"description" binding:"max=255"`
+	DiscountType  string `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	DiscountValue int64  `json:"discount_value" binding:"required,min=1"`
+	MaxDiscount   int64  `json:"max_discount" binding:"min=0"`
+	MaxUses       int    `json:"max_uses" binding:"min=0"`
+	PerUserLimit  *int   `json:"per_user_limit" binding:"omitempty,min=0"` // Default 1
+	ValidFrom     string `json:"valid_from"`                               // "2006-01-02 15:04" atau "2006-01-02"
+	ValidUntil    string `json:"valid_until"`                              // "2006-01-02 15:04" atau "2006-01-02" (sampai 23:59)
+	EventID       *uint  `json:"event_id"`                                 // Kosong = semua event (butuh promo.manage global)
+	TicketTypeIDs []uint `json:"ticket_type_ids"`                          // Harus milik event_id
+	IsActive      *bool  `json:"is_active"`                                // Default aktif
+}
+
+type CheckPromoCodeRequest struct {
+	Code         string `json:"code" binding:"required"`
+	EventID      uint   `json:"event_id" binding:"required"`
+	TicketTypeID *uint  `json:"ticket_type_id"`
+}
+
+// PromoCodeSummary adalah kode promo beserta ringkasan pemakaiannya
+type PromoCodeSummary struct {
+	models.PromoCode
+	UsedCount     int64 `json:"used_count"`
+	TotalDiscount int64 `json:"total_discount"`
+}
+
+// GET /api/promo-codes
+// Daftar kode promo beserta jumlah pemakaian dan total potongan. Dengan ?event_id= cukup
+// permission promo.manage pada event tersebut, tanpa event_id butuh promo.manage global.
+func GetPromoCodes(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	query := database.DB.Preload("TicketTypes").Preload("Event")
+	if eventID, _ := strconv.Atoi(c.Query("event_id")); eventID > 0 {
+		if !helpers.HasEventPermission(userID, uint(eventID), "promo.manage") {
+			c.JSON(http.StatusForbidden, structs.ErrorResponse{
+				Success: false,
+				Message: "Anda tidak memiliki akses untuk mengelola kode promo event ini",
+			})
+			return
+		}
+		query = query.Where("event_id = ?", eventID)
+	} else if !helpers.HasPermission(userID, "promo.manage") {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses untuk mengelola kode promo",
+		})
+		return
+	}
+
+	var promos []models.PromoCode
+	if err := query.Order("id DESC").Find(&promos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengambil kode promo",
+		})
+		return
+	}
+
+	type usageRow struct {
+		PromoCodeID   uint
+		UsedCount     int64
+		TotalDiscount int64
+	}
+	var rows []usageRow
+	database.DB.Model(&models.PromoCodeUsage{}).
+		Select("promo_code_usages.promo_code_id, COUNT(*) AS used_count, COALESCE(SUM(promo_code_usages.discount_amount), 0) AS total_discount").
+		Joins("INNER JOIN registrations ON registrations.id = promo_code_usages.registration_id").
+		Where("registrations.status NOT IN ?", models.ReleasedSeatStatuses).
+		Group("promo_code_usages.promo_code_id").
+		Scan(&rows)
+	usage := make(map[uint]usageRow, len(rows))
+	for _, r := range rows {
+		usage[r.PromoCodeID] = r
+	}
+
+	summaries := make([]PromoCodeSummary, 0, len(promos))
+	for _, p := range promos {
+		summaries = append(summaries, PromoCodeSummary{
+			PromoCode:     p,
+			UsedCount:     usage[p.ID].UsedCount,
+			TotalDiscount: usage[p.ID].TotalDiscount,
+		})
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar kode promo",
+		Data:    summaries,
+	})
+}
+
+// POST /api/promo-codes
+func CreatePromoCode(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var req PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+	if !canManagePromo(c, userID, req.EventID) {
+		return
+	}
+
+	promo := models.PromoCode{CreatedByID: &userID}
+	if !applyPromoCodeRequest(c, &promo, req) {
+		return
+	}
+	if err := database.DB.Create(&promo).Error; err != nil {
+		status, message := http.StatusInternalServerError, "Gagal membuat kode promo"
+		if helpers.IsDuplicateEntryError(err) {
+			status, message = http.StatusConflict, "Kode promo sudah dipakai"
+		}
+		c.JSON(status, structs.ErrorResponse{
+			Success: false,
+			Message: message,
+			Errors:  map[string]string{"code": message},
+		})
+		return
+	}
+
+	CreateAuditLog(userID, "create", "promo_code", promo.ID, nil, promo,
+		"Membuat kode promo "+promo.Code, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Kode promo berhasil dibuat",
+		Data:    promo,
+	})
+}
+
+// PUT /api/promo-codes/:id
+func UpdatePromoCode(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var promo models.PromoCode
+	if err := database.DB.Preload("TicketTypes").First(&promo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Kode promo tidak ditemukan",
+		})
+		return
+	}
+	if !canManagePromo(c, userID, promo.EventID) {
+		return
+	}
+
+	var req PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+	// Memindahkan promo ke event lain (atau menjadikannya global) butuh akses ke tujuan juga
+	if !canManagePromo(c, userID, req.EventID) {
+		return
+	}
+
+	oldPromo := promo
+	if !applyPromoCodeRequest(c, &promo, req) {
+		return
+	}
+	if err := database.DB.Omit("TicketTypes").Save(&promo).Error; err != nil {
+		status, message := http.StatusInternalServerError, "Gagal memperbarui kode promo"
+		if helpers.IsDuplicateEntryError(err) {
+			status, message = http.StatusConflict, "Kode promo sudah dipakai"
+		}
+		c.JSON(status, structs.ErrorResponse{
+			Success: false,
+			Message: message,
+		})
+		return
+	}
+	database.DB.Model(&promo).Association("TicketTypes").Replace(promo.TicketTypes)
+
+	CreateAuditLog(userID, "update", "promo_code", promo.ID, oldPromo, promo,
+		"Memperbarui kode promo "+promo.Code, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Kode promo berhasil diperbarui",
+		Data:    promo,
+	})
+}
+
+// DELETE /api/promo-codes/:id
+// Kode yang sudah pernah dipakai tidak dihapus agar laporan tetap utuh, nonaktifkan saja
+func DeletePromoCode(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var promo models.PromoCode
+	if err := database.DB.First(&promo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Kode promo tidak ditemukan",
+		})
+		return
+	}
+	if !canManagePromo(c, userID, promo.EventID) {
+		return
+	}
+
+	var used int64
+	database.DB.Model(&models.PromoCodeUsage{}).Where("promo_code_id = ?", promo.ID).Count(&used)
+	if used > 0 {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Kode promo sudah pernah dipakai dan tidak bisa dihapus. Nonaktifkan kode ini.",
+		})
+		return
+	}
+
+	if err := database.DB.Select("TicketTypes").Delete(&promo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menghapus kode promo",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	CreateAuditLog(userID, "delete", "promo_code", promo.ID, promo, nil,
+		"Menghapus kode promo "+promo.Code, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Kode promo berhasil dihapus",
+	})
+}
+
+// GET /api/promo-codes/:id/usages
+// Laporan pemakaian kode promo: siapa yang memakai, status registrasinya, dan potongan yang diberikan
+func GetPromoCodeUsages(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var promo models.PromoCode
+	if err := database.DB.First(&promo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Kode promo tidak ditemukan",
+		})
+		return
+	}
+	if !canManagePromo(c, userID, promo.EventID) {
+		return
+	}
+
+	var usages []models.PromoCodeUsage
+	database.DB.Preload("Registration.User").Preload("Registration.Event").Preload("Registration.TicketType").
+		Where("promo_code_id = ?", promo.ID).
+		Order("id DESC").
+		Find(&usages)
+
+	var usedCount, totalDiscount int64
+	for _, u := range usages {
+		if u.Registration == nil || slices.Contains(models.ReleasedSeatStatuses, u.Registration.Status) {
+			continue
+		}
+		usedCount++
+		totalDiscount += u.DiscountAmount
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Laporan pemakaian kode promo",
+		Data: gin.H{
+			"promo_code":     promo,
+			"used_count":     usedCount,
+			"total_discount": totalDiscount,
+			"usages":         usages,
+		},
+	})
+}
+
+// POST /api/promo-codes/check
+// Cek kode promo sebelum mendaftar dan tampilkan harga setelah diskon
+func CheckPromoCode(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var req CheckPromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.First(&event, req.EventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Event tidak ditemukan",
+		})
+		return
+	}
+	price := event.Price
+	if req.TicketTypeID != nil {
+		var ticketType models.TicketType
+		if err := database.DB.Where("id = ? AND event_id = ?", *req.TicketTypeID, event.ID).First(&ticketType).Error; err != nil {
+			c.JSON(http.StatusNotFound, structs.ErrorResponse{
+				Success: false,
+				Message: "Tipe tiket tidak ditemukan",
+			})
+			return
+		}
+		price = ticketType.Price
+	}
+
+	promo, discount, err := helpers.ValidatePromoCode(req.Code, event.ID, req.TicketTypeID, userID, price, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: promoErrorMessage(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Kode promo berlaku",
+		Data: gin.H{
+			"code":           promo.Code,
+			"description":    promo.Description,
+			"original_price": price,
+			"discount":       discount,
+			"final_price":    price - discount,
+		},
+	})
+}
+
+// promoErrorMessage menerjemahkan error validasi promo ke pesan untuk peserta
+func promoErrorMessage(err error) string {
+	switch err {
+	case helpers.ErrPromoNotFound:
+		return "Kode promo tidak ditemukan"
+	case helpers.ErrPromoInactive:
+		return "Kode promo tidak aktif atau sudah tidak berlaku"
+	case helpers.ErrPromoNotApplicable:
+		return "Kode promo tidak berlaku untuk event atau tipe tiket ini"
+	case helpers.ErrPromoExhausted:
+		return "Kuota pemakaian kode promo sudah habis"
+	case helpers.ErrPromoUserLimit:
+		return "Anda sudah mencapai batas pemakaian kode promo ini"
+	case helpers.ErrPromoFreeTicket:
+		return "Kode promo tidak bisa dipakai untuk tiket gratis"
+	}
+	return "Gagal memakai kode promo"
+}
+
+// canManagePromo mengecek permission promo.manage: pada event jika promo terikat event, global jika tidak.
+// Response error sudah ditulis jika mengembalikan false.
+func canManagePromo(c *gin.Context, userID uint, eventID *uint) bool {
+	allowed := false
+	if eventID != nil {
+		allowed = helpers.HasEventPermission(userID, *eventID, "promo.manage")
+	} else {
+		allowed = helpers.HasPermission(userID, "promo.manage")
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses untuk mengelola kode promo ini",
+		})
+	}
+	return allowed
+}
+
+// applyPromoCodeRequest mengisi kode promo dari request dan memvalidasi nilai diskon, masa berlaku dan tipe tiket
+func applyPromoCodeRequest(c *gin.Context, promo *models.PromoCode, req PromoCodeRequest) bool {
+	fieldErrors := map[string]string{}
+
+	if req.DiscountType == models.PromoDiscountPercentage && req.DiscountValue > 100 {
+		fieldErrors["discount_value"] = "Persentase diskon maksimal 100"
+	}
+
+	var validFrom, validUntil *time.Time
+	if req.ValidFrom != "" {
+		parsed, err := time.Parse("2006-01-02 15:04", req.ValidFrom)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", req.ValidFrom)
+		}
+		if err != nil {
+			fieldErrors["valid_from"] = "Format harus YYYY-MM-DD HH:mm atau YYYY-MM-DD"
+		} else {
+			validFrom = &parsed
+		}
+	}
+	if req.ValidUntil != "" {
+		if parsed, ok := parseDeadlineInput(req.ValidUntil); ok {
+			validUntil = parsed
+		} else {
+			fieldErrors["valid_until"] = "Format harus YYYY-MM-DD HH:mm atau YYYY-MM-DD"
+		}
+	}
+	if validFrom != nil && validUntil != nil && !validUntil.After(*validFrom) {
+		fieldErrors["valid_until"] = "Harus setelah tanggal mulai berlaku"
+	}
+
+	var ticketTypes []models.TicketType
+	if len(req.TicketTypeIDs) > 0 {
+		if req.EventID == nil {
+			fieldErrors["ticket_type_ids"] = "Pilih event terlebih dahulu"
+		} else {
+			database.DB.Where("id IN ? AND event_id = ?", req.TicketTypeIDs, *req.EventID).Find(&ticketTypes)
+			if len(ticketTypes) != len(req.TicketTypeIDs) {
+				fieldErrors["ticket_type_ids"] = "Tipe tiket harus milik event yang dipilih"
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Data kode promo tidak valid",
+			Errors:  fieldErrors,
+		})
+		return false
+	}
+
+	promo.Code = helpers.NormalizePromoCode(req.Code)
+	promo.Description = req.Description
+	promo.DiscountType = req.DiscountType
+	promo.DiscountValue = req.DiscountValue
+	promo.MaxDiscount = req.MaxDiscount
+	promo.MaxUses = req.MaxUses
+	promo.PerUserLimit = 1
+	if req.PerUserLimit != nil {
+		promo.PerUserLimit = *req.PerUserLimit
+	}
+	promo.ValidFrom = validFrom
+	promo.ValidUntil = validUntil
+	promo.EventID = req.EventID
+	promo.TicketTypes = ticketTypes
+	promo.IsActive = req.IsActive == nil || *req.IsActive
+	return true
+}
@@ -0,0 +1,351 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/services"
+	"santrikoding/backend-api/structs"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CancelRegistrationRequest struct {
+	Reason       string `json:"reason" binding:"max=255"`
+	RefundAmount *int64 `json:"refund_amount"` // Hanya untuk panitia (payment.refund), default refund penuh
+}
+
+// POST /api/registrations/:id/cancel
+// Peserta membatalkan pendaftarannya sendiri sebelum event dimulai (refund mengikuti kebijakan event),
+// atau panitia dengan permission payment.refund membatalkan dan me-refund tiket peserta.
+// Memanggil ulang endpoint ini untuk registrasi yang sudah cancelled akan mencoba ulang refund yang gagal.
+func CancelRegistration(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var req CancelRegistrationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+				Success: false,
+				Message: "Validation Errors",
+				Errors:  helpers.TranslateErrorMessage(err),
+			})
+			return
+		}
+	}
+
+	var registration models.Registration
+	if err := database.DB.Preload("Event").Preload("User").First(&registration, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran tidak ditemukan",
+		})
+		return
+	}
+	event := registration.Event
+
+	isCommittee := helpers.HasEventPermission(userID, event.ID, "payment.refund")
+	if !isCommittee && registration.UserID != userID {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses untuk membatalkan pendaftaran ini",
+		})
+		return
+	}
+
+	if !isCommittee {
+		if !event.StartDate.IsZero() && !time.Now().Before(event.StartDate) {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Pendaftaran tidak bisa dibatalkan setelah event dimulai",
+			})
+			return
+		}
+		if registration.Attendance {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Pendaftaran tidak bisa dibatalkan karena Anda sudah check-in",
+			})
+			return
+		}
+		if req.RefundAmount != nil {
+			c.JSON(http.StatusForbidden, structs.ErrorResponse{
+				Success: false,
+				Message: "Nominal refund hanya bisa diatur oleh panitia",
+			})
+			return
+		}
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "Dibatalkan oleh peserta"
+		if registration.UserID != userID {
+			reason = "Dibatalkan oleh panitia"
+		}
+	}
+
+	payment, hasPayment := helpers.GetPaidPayment(registration.ID)
+	if req.RefundAmount != nil && (!hasPayment || *req.RefundAmount < 0 || *req.RefundAmount > payment.Amount) {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Nominal refund tidak valid",
+			Errors:  map[string]string{"refund_amount": "Harus antara 0 dan nominal pembayaran"},
+		})
+		return
+	}
+
+	previousStatus, refund, err := helpers.MarkRegistrationCancelled(registration.ID, reason, helpers.CancellationRefund{
+		Event:       event,
+		IsCommittee: isCommittee,
+		Requested:   req.RefundAmount,
+		Reason:      reason,
+		RequestedBy: userID,
+	})
+	if err == helpers.ErrRegistrationNotCancellable {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran yang sudah ditolak tidak bisa dibatalkan",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error cancelling registration %d: %v", registration.ID, err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membatalkan pendaftaran",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	newlyCancelled := previousStatus != "cancelled" && previousStatus != "refunded" && previousStatus != "rejected"
+
+	var refundErr error
+	if refund != nil {
+		var refundPayment models.Payment
+		if refundErr = database.DB.First(&refundPayment, refund.PaymentID).Error; refundErr == nil {
+			refundErr = executeRefund(&refundPayment, refund)
+		}
+	}
+
+	if newlyCancelled {
+		CreateAuditLog(
+			userID,
+			"registration_cancelled",
+			"registration",
+			registration.ID,
+			gin.H{"status": previousStatus},
+			gin.H{"status": "cancelled"},
+			reason,
+			c.ClientIP(),
+			c.Request.UserAgent(),
+		)
+		CreateActivity(registration.UserID, "registration_cancelled", "event", event.ID,
+			"membatalkan pendaftaran event "+event.Title)
+
+		var refundAmount int64
+		if refund != nil {
+			refundAmount = refund.Amount
+		}
+		go helpers.NotifyRegistrationCancelled(registration.UserID, event.Title, refundAmount)
+		go helpers.PromoteWaitlist(event.ID)
+	}
+
+	if refund != nil {
+		action := "refund_succeeded"
+		switch refund.Status {
+		case models.RefundStatusPending:
+			action = "refund_requested"
+		case models.RefundStatusFailed:
+			action = "refund_failed"
+		}
+		CreateAuditLog(
+			userID,
+			action,
+			"payment",
+			refund.PaymentID,
+			nil,
+			gin.H{"refund_id": refund.ID, "refund_key": refund.RefundKey, "amount": refund.Amount, "status": refund.Status},
+			fmt.Sprintf("Refund Rp %d untuk registrasi #%d", refund.Amount, registration.ID),
+			c.ClientIP(),
+			c.Request.UserAgent(),
+		)
+		if refund.Status == models.RefundStatusSucceeded {
+			go helpers.NotifyRefundProcessed(registration.UserID, event.Title, refund.Amount)
+		}
+	}
+
+	database.DB.First(&registration, registration.ID)
+	data := gin.H{
+		"registration_id": registration.ID,
+		"status":          registration.Status,
+		"refund":          refund,
+	}
+
+	if refundErr != nil {
+		log.Printf("Refund for registration %d failed: %v", registration.ID, refundErr)
+		c.JSON(http.StatusBadGateway, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran dibatalkan, namun refund gagal diproses. Silakan coba lagi nanti.",
+			Errors:  map[string]string{"error": refundErr.Error()},
+		})
+		return
+	}
+
+	message := "Pendaftaran berhasil dibatalkan"
+	if refund != nil && refund.Status == models.RefundStatusPending {
+		message = fmt.Sprintf("Pendaftaran dibatalkan, refund Rp %d akan ditransfer oleh bendahara", refund.Amount)
+	} else if refund != nil {
+		message = fmt.Sprintf("Pendaftaran dibatalkan dan refund Rp %d berhasil diproses", refund.Amount)
+	}
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// executeRefund mengirim refund ke provider payment, lalu menandai payment dan registrasi refunded jika berhasil.
+// Refund transfer manual dibiarkan pending sampai bendahara mentransfer dana dan menandainya selesai.
+func executeRefund(payment *models.Payment, refund *models.Refund) error {
+	if payment.Provider == models.PaymentProviderManual {
+		return nil
+	}
+
+	provider, err := services.GetPaymentProvider(payment.Provider)
+	if err == nil {
+		var result *services.RefundResult
+		result, err = provider.Refund(services.RefundRequest{
+			OrderID:   payment.OrderID,
+			RefundKey: refund.RefundKey,
+			Amount:    refund.Amount,
+			Reason:    refund.Reason,
+		})
+		if err == nil {
+			if err := helpers.FinishRefund(refund, result.Status, string(result.Raw), nil); err != nil {
+				return err
+			}
+			if _, err := helpers.ApplyPaymentStatus(payment.ID, helpers.PaymentUpdate{
+				Status:         models.PaymentStatusRefunded,
+				ProviderStatus: result.Status,
+				Source:         "refund",
+				Note:           refund.RefundKey,
+				Raw:            string(result.Raw),
+			}); err != nil {
+				return err
+			}
+			database.DB.First(refund, refund.ID)
+			return nil
+		}
+	}
+
+	helpers.FinishRefund(refund, "", "", err)
+	database.DB.First(refund, refund.ID)
+	return err
+}
+
+type CompleteRefundRequest struct {
+	Note string `json:"note" binding:"max=255"` // Misal nomor referensi transfer balik ke peserta
+}
+
+// POST /api/refunds/:id/complete
+// Bendahara menandai refund transfer manual sudah ditransfer kembali ke peserta
+// (permission payment.verify atau payment.refund pada event)
+func CompleteManualRefund(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var req CompleteRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+				Success: false,
+				Message: "Validation Errors",
+				Errors:  helpers.TranslateErrorMessage(err),
+			})
+			return
+		}
+	}
+
+	var refund models.Refund
+	if err := database.DB.First(&refund, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Refund tidak ditemukan",
+		})
+		return
+	}
+
+	var registration models.Registration
+	database.DB.Preload("Event").First(&registration, refund.RegistrationID)
+	if !helpers.HasEventPermission(userID, registration.EventID, "payment.verify") &&
+		!helpers.HasEventPermission(userID, registration.EventID, "payment.refund") {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak memiliki akses untuk memproses refund ini",
+		})
+		return
+	}
+
+	var payment models.Payment
+	if err := database.DB.First(&payment, refund.PaymentID).Error; err != nil || payment.Provider != models.PaymentProviderManual {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Hanya refund pembayaran transfer manual yang bisa diselesaikan secara manual",
+		})
+		return
+	}
+	if refund.Status == models.RefundStatusSucceeded {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Refund sudah selesai diproses",
+		})
+		return
+	}
+
+	if err := helpers.FinishRefund(&refund, "manual_transferred", req.Note, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal memperbarui refund",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	if _, err := helpers.ApplyPaymentStatus(payment.ID, helpers.PaymentUpdate{
+		Status:         models.PaymentStatusRefunded,
+		ProviderStatus: "manual_transferred",
+		Source:         "refund",
+		Note:           refund.RefundKey,
+	}); err != nil {
+		log.Printf("Error applying refund status for payment %d: %v", payment.ID, err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal memperbarui status pembayaran",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	database.DB.First(&refund, refund.ID)
+
+	CreateAuditLog(
+		userID,
+		"refund_succeeded",
+		"payment",
+		refund.PaymentID,
+		nil,
+		gin.H{"refund_id": refund.ID, "refund_key": refund.RefundKey, "amount": refund.Amount, "status": refund.Status},
+		fmt.Sprintf("Refund manual Rp %d untuk registrasi #%d", refund.Amount, registration.ID),
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+	go helpers.NotifyRefundProcessed(registration.UserID, registration.Event.Title, refund.Amount)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Refund berhasil ditandai selesai",
+		Data:    refund,
+	})
+}
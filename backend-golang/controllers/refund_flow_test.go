@@ -0,0 +1,93 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"sync"
+	"testing"
+)
+
+// registerPaid mendaftarkan peserta ke event berbayar lalu melunasi tagihannya lewat fake provider
+func registerPaid(t *testing.T, event models.Event, participant models.User, token string) models.Registration {
+	t.Helper()
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/participant/event/%d/register", event.ID), token,
+		map[string]interface{}{"answers": []interface{}{}})
+	requireStatus(t, "register", code, http.StatusCreated, body)
+	orderID := body["payment"].(map[string]interface{})["order_id"].(string)
+	registrationID := uint(body["data"].(map[string]interface{})["id"].(float64))
+
+	code, body = doJSON(t, http.MethodPost, "/api/payment/fake/simulate", "",
+		map[string]interface{}{"order_id": orderID, "transaction_status": "settlement"})
+	requireStatus(t, "settlement", code, http.StatusOK, body)
+
+	var registration models.Registration
+	database.DB.First(&registration, registrationID)
+	if registration.Status != "confirmed" {
+		t.Fatalf("setelah settlement: status %q, want confirmed", registration.Status)
+	}
+	return registration
+}
+
+// Pembatalan yang dikirim bersamaan hanya membuat satu refund untuk payment yang sama
+func TestConcurrentCancellationCreatesSingleRefund(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	participant := createUser(t)
+	event := createEvent(t, organizer, 100000, 10)
+	database.DB.Model(&event).Update("refund_percentage", 100)
+	token := login(t, participant)
+	registration := registerPaid(t, event, participant, token)
+
+	const requests = 5
+	var wg sync.WaitGroup
+	codes := make([]int, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i], _ = doJSON(t, http.MethodPost, fmt.Sprintf("/api/registrations/%d/cancel", registration.ID), token, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("pembatalan #%d: status %d, want 200", i, code)
+		}
+	}
+
+	var refunds []models.Refund
+	database.DB.Where("registration_id = ?", registration.ID).Find(&refunds)
+	if len(refunds) != 1 {
+		t.Fatalf("pembatalan bersamaan membuat %d refund, want 1", len(refunds))
+	}
+	if refunds[0].Amount != 100000 || refunds[0].Status != models.RefundStatusSucceeded {
+		t.Fatalf("refund: nominal %d status %q, want 100000 succeeded", refunds[0].Amount, refunds[0].Status)
+	}
+}
+
+// Tiket registrasi yang sudah dibatalkan tidak bisa dipakai check-in
+func TestCancelledRegistrationCannotCheckIn(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	participant := createUser(t)
+	event := createEvent(t, organizer, 100000, 10)
+	token := login(t, participant)
+	registration := registerPaid(t, event, participant, token)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/registrations/%d/cancel", registration.ID), token, nil)
+	requireStatus(t, "cancel", code, http.StatusOK, body)
+
+	code, body = doJSON(t, http.MethodPost, "/api/scan/check-in", login(t, organizer),
+		map[string]interface{}{"qr_code": registration.QRCode})
+	requireStatus(t, "scan tiket dibatalkan", code, http.StatusBadRequest, body)
+
+	database.DB.First(&registration, registration.ID)
+	if registration.Attendance || registration.Status != "cancelled" {
+		t.Fatalf("setelah scan: status %q attendance %v, want cancelled tanpa kehadiran", registration.Status, registration.Attendance)
+	}
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"santrikoding/backend-api/database"
@@ -57,24 +58,50 @@ func SendVerificationCode(c *gin.Context) {
 	}
 
 	// Generate kode verifikasi
-	verificationCode := helpers.GenerateVerificationCode()
-
-	// Simpan data verifikasi sementara
-	userData := map[string]interface{}{
-		"name":             req.Name,
-		"username":         req.Username,
-		"password":         req.Password,
-		"phone":            req.Phone,
-		"university_id":    req.UniversityID,
-		"faculty_id":       req.FacultyID,
-		"study_program_id": req.StudyProgramID,
-		"angkatan":         req.Angkatan,
+	verificationCode, err := helpers.GenerateVerificationCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat kode verifikasi",
+		})
+		return
+	}
+
+	// Simpan data pendaftaran sementara (password langsung di-hash)
+	pending := models.PendingRegistration{
+		Email:          req.Email,
+		Name:           req.Name,
+		Username:       req.Username,
+		PasswordHash:   helpers.HashPassword(req.Password),
+		Phone:          req.Phone,
+		UniversityID:   req.UniversityID,
+		FacultyID:      req.FacultyID,
+		StudyProgramID: req.StudyProgramID,
+		Angkatan:       req.Angkatan,
+	}
+	if err := helpers.StoreVerificationCode(&pending, verificationCode); err != nil {
+		var cooldownErr *helpers.VerificationCooldownError
+		if errors.As(err, &cooldownErr) || errors.Is(err, helpers.ErrVerificationLocked) {
+			c.JSON(http.StatusTooManyRequests, structs.ErrorResponse{
+				Success: false,
+				Message: err.Error(),
+				Errors:  map[string]string{"email": err.Error()},
+			})
+			return
+		}
+		log.Printf("Error storing verification code: %v", err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menyimpan data pendaftaran",
+		})
+		return
 	}
-	helpers.StoreVerificationCode(req.Email, verificationCode, userData)
 
 	// Kirim email verifikasi
 	if err := helpers.SendVerificationEmail(req.Email, verificationCode); err != nil {
 		log.Printf("Error sending verification email: %v", err)
+		// Hapus supaya user bisa langsung mencoba lagi tanpa menunggu cooldown
+		helpers.DeleteVerificationCode(req.Email)
 		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
 			Success: false,
 			Message: "Gagal mengirim email verifikasi. Pastikan SMTP sudah dikonfigurasi dengan benar.",
@@ -113,7 +140,11 @@ func VerifyAndRegister(c *gin.Context) {
 	// Verifikasi kode
 	verificationData, err := helpers.VerifyCode(req.Email, req.Code)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+		status := http.StatusBadRequest
+		if errors.Is(err, helpers.ErrVerificationLocked) {
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, structs.ErrorResponse{
 			Success: false,
 			Message: err.Error(),
 			Errors:  map[string]string{"code": err.Error()},
@@ -121,13 +152,13 @@ func VerifyAndRegister(c *gin.Context) {
 		return
 	}
 
-	// Buat data user baru dengan password yang sudah di-hash
+	// Buat data user baru (password sudah di-hash saat kode dikirim)
 	// RoleID default adalah 8 (Mahasiswa) untuk user baru
 	user := models.User{
 		Name:           verificationData.Name,
 		Username:       verificationData.Username,
 		Email:          verificationData.Email,
-		Password:       helpers.HashPassword(verificationData.Password),
+		Password:       verificationData.PasswordHash,
 		Phone:          verificationData.Phone,
 		UniversityID:   verificationData.UniversityID,
 		FacultyID:      verificationData.FacultyID,
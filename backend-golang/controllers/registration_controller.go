@@ -8,20 +8,26 @@ import (
 	"santrikoding/backend-api/database"
 	"santrikoding/backend-api/helpers"
 	"santrikoding/backend-api/models"
-	"santrikoding/backend-api/services"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Struct Input dari Frontend
 type RegistrationRequest struct {
-	Answers []struct {
-		FormFieldID uint   `json:"form_field_id"`
-		Value       string `json:"value"`
-	} `json:"answers"`
+	TicketTypeID *uint                `json:"ticket_type_id"` // Wajib jika event punya tipe tiket
+	AccessCode   string               `json:"access_code"`    // Wajib untuk tipe tiket hidden
+	PromoCode    string               `json:"promo_code"`     // Opsional, kode diskon untuk event berbayar
+	Answers      []RegistrationAnswer `json:"answers"`
+}
+
+// RegistrationAnswer adalah jawaban satu field form pendaftaran
+type RegistrationAnswer struct {
+	FormFieldID uint   `json:"form_field_id"`
+	Value       string `json:"value"`
 }
 
 // POST /api/events/:id/register
@@ -77,20 +83,38 @@ func RegisterEvent(c *gin.Context) {
 
 	// 3. Cek apakah User sudah pernah daftar di event ini? (Cegah double register)
 	var existingReg int64
-	database.DB.Model(&models.Registration{}).Where("event_id = ? AND user_id = ?", eventID, userID).Count(&existingReg)
+	database.DB.Model(&models.Registration{}).
+		Where("event_id = ? AND user_id = ? AND guest_email = ? AND status NOT IN ?", eventID, userID, "", models.InactiveRegistrationStatuses).
+		Count(&existingReg)
 	if existingReg > 0 {
 		c.JSON(http.StatusConflict, gin.H{"message": "Anda sudah terdaftar di event ini!"})
 		return
 	}
 
-	// 4. Validasi Kuota
-	var registeredCount int64
-	database.DB.Model(&models.Registration{}).Where("event_id = ?", eventID).Count(&registeredCount)
-	if event.Quota > 0 && int(registeredCount) >= event.Quota {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Kuota event sudah penuh. Tidak dapat mendaftar lagi.",
-		})
-		return
+	// 4. Validasi Kuota (registrasi yang ditolak/dibatalkan tidak dihitung, kursi yang sedang
+	// ditawarkan ke waitlist dihitung kecuali tawaran milik user ini). Dicek ulang di dalam
+	// transaksi oleh helpers.ReserveSeat.
+	if event.Quota > 0 {
+		hasOffer := event.WaitlistEnabled && helpers.HasValidWaitlistOffer(event.ID, userID)
+		full := int(helpers.SeatsTaken(database.DB, event.ID, userID)) >= event.Quota
+		// Antrian waitlist didahulukan: kursi yang baru kosong ditawarkan ke antrian, bukan pendaftar baru
+		if event.WaitlistEnabled && !hasOffer && !full && helpers.CountWaitlistWaiting(event.ID) > 0 {
+			full = true
+			go helpers.PromoteWaitlist(event.ID)
+		}
+		if full {
+			if event.WaitlistEnabled {
+				c.JSON(http.StatusConflict, gin.H{
+					"message":            "Kuota event sudah penuh. Silakan masuk daftar tunggu (waitlist).",
+					"waitlist_available": true,
+				})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Kuota event sudah penuh. Tidak dapat mendaftar lagi.",
+			})
+			return
+		}
 	}
 
 	// 5. Parse Input Jawaban
@@ -100,47 +124,127 @@ func RegisterEvent(c *gin.Context) {
 		return
 	}
 
+	// Validasi tipe tiket: periode penjualan dan kuota per tipe
+	ticketType, err := helpers.ResolveTicketType(event.ID, input.TicketTypeID, input.AccessCode, time.Now())
+	if err != nil {
+		message := "Tipe tiket tidak valid"
+		switch err {
+		case helpers.ErrTicketTypeRequired:
+			message = "Silakan pilih tipe tiket terlebih dahulu"
+		case helpers.ErrTicketAccessCode:
+			message = "Kode akses tiket tidak valid"
+		case helpers.ErrTicketNotOnSale:
+			message = "Tiket yang dipilih sedang tidak dalam periode penjualan"
+		case helpers.ErrTicketSoldOut:
+			message = "Kuota tiket yang dipilih sudah habis. Silakan pilih tipe tiket lain."
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
+
+	price := event.Price
+	if ticketType != nil {
+		price = ticketType.Price
+	}
+
+	// Validasi kode promo (dicek ulang dengan lock saat disimpan)
+	var promo *models.PromoCode
+	var discount int64
+	if input.PromoCode != "" {
+		promo, discount, err = helpers.ValidatePromoCode(input.PromoCode, event.ID, input.TicketTypeID, userID, price, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": promoErrorMessage(err)})
+			return
+		}
+	}
+
 	// 6. Validasi Field Required
-	var formFields []models.FormField
-	if err := database.DB.Where("event_id = ? AND is_required = ?", eventID, true).Find(&formFields).Error; err == nil {
-		for _, field := range formFields {
-			// Cek apakah field required ada di jawaban
-			found := false
-			for _, ans := range input.Answers {
-				if ans.FormFieldID == field.ID && ans.Value != "" {
-					found = true
-					break
-				}
-			}
-			if !found {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"message": fmt.Sprintf("Field '%s' wajib diisi", field.Label),
+	if label, ok := missingRequiredField(event.ID, input.Answers); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": fmt.Sprintf("Field '%s' wajib diisi", label),
+		})
+		return
+	}
+
+	// 7. Mulai Transaksi Database
+	tx := database.DB.Begin()
+
+	// Kunci kursi: cek ulang pendaftaran ganda dan kuota dengan baris event terkunci
+	if err := helpers.ReserveSeat(tx, event.ID, userID, input.TicketTypeID); err != nil {
+		tx.Rollback()
+		switch err {
+		case helpers.ErrAlreadyRegistered:
+			c.JSON(http.StatusConflict, gin.H{"message": "Anda sudah terdaftar di event ini!"})
+		case helpers.ErrEventFull:
+			if event.WaitlistEnabled {
+				c.JSON(http.StatusConflict, gin.H{
+					"message":            "Kuota event sudah penuh. Silakan masuk daftar tunggu (waitlist).",
+					"waitlist_available": true,
 				})
 				return
 			}
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Kuota event sudah penuh. Tidak dapat mendaftar lagi."})
+		case helpers.ErrTicketSoldOut:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Kuota tiket yang dipilih sudah habis. Silakan pilih tipe tiket lain."})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal menyimpan pendaftaran"})
 		}
+		return
 	}
 
-	// 7. Mulai Transaksi Database
-	tx := database.DB.Begin()
-
 	// A. Simpan Header Pendaftaran
-	// Generate QR Code unik: EVT-{EventID}-USER-{UserID}-{Random}
-	qrCode := fmt.Sprintf("EVT-%s-USR-%d-%s", eventID, userID, uuid.New().String()[:6])
-
+	// QR tiket ditandatangani setelah ID registrasi didapat (lihat helpers.SignTicketQR)
 	registration := models.Registration{
 		EventID:    stringToUint(eventID),
 		UserID:     userID,
 		Status:     "pending",
-		QRCode:     qrCode,
+		QRCode:     helpers.TemporaryTicketCode(),
 		Attendance: false,
+		Price:      price - discount,
+	}
+	if ticketType != nil {
+		registration.TicketTypeID = &ticketType.ID
 	}
 
 	if err := tx.Create(&registration).Error; err != nil {
 		tx.Rollback()
+		// Unique index (event_id, user_id) untuk registrasi aktif menahan pendaftaran ganda
+		if helpers.IsDuplicateEntryError(err) {
+			c.JSON(http.StatusConflict, gin.H{"message": "Anda sudah terdaftar di event ini!"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal menyimpan pendaftaran"})
 		return
 	}
+	if err := helpers.AssignTicketQR(tx, &registration, event); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal membuat QR tiket"})
+		return
+	}
+
+	// Catat pemakaian kode promo
+	if promo != nil {
+		usage, err := helpers.RedeemPromoCode(tx, promo.ID, &registration, input.TicketTypeID, price, time.Now())
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, gin.H{"message": promoErrorMessage(err)})
+			return
+		}
+		// Promo bisa saja diubah panitia di antara validasi dan penyimpanan
+		if usage.DiscountAmount != discount {
+			registration.Price = price - usage.DiscountAmount
+			tx.Model(&registration).Update("price", registration.Price)
+		}
+	}
+
+	// Kursi dari waitlist sudah diklaim
+	if event.WaitlistEnabled {
+		if err := helpers.ClaimWaitlistOffer(tx, event.ID, userID, registration.ID); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal menyimpan pendaftaran"})
+			return
+		}
+	}
 
 	// B. Simpan Jawaban Form
 	for _, ans := range input.Answers {
@@ -157,7 +261,10 @@ func RegisterEvent(c *gin.Context) {
 	}
 
 	// 8. Commit (Simpan Permanen)
-	tx.Commit()
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Gagal menyimpan pendaftaran"})
+		return
+	}
 
 	// 9. Ambil data user untuk notifikasi
 	var user models.User
@@ -172,7 +279,7 @@ func RegisterEvent(c *gin.Context) {
 		fmt.Sprintf("mendaftar event %s", event.Title))
 
 	// 12. Handle Event Gratis vs Berbayar
-	if event.Price == 0 {
+	if registration.Price == 0 {
 		// Event GRATIS: Status tetap "pending", perlu approval panitia
 		c.JSON(http.StatusCreated, gin.H{
 			"success": true,
@@ -184,8 +291,13 @@ func RegisterEvent(c *gin.Context) {
 		// Event BERBAYAR: Initiate payment Midtrans
 		// user sudah diambil di atas, tidak perlu fetch lagi
 
-		// Generate snap token untuk pembayaran
-		token, redirectURL, orderID, err := services.GetSnapToken(registration, user, event)
+		// Buat tagihan di payment provider (snap token) untuk registrasi ini
+		var payment *models.Payment
+		err = database.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			payment, err = createPayment(tx, registration, user, event)
+			return err
+		})
 		if err != nil {
 			// Jika gagal generate payment, tetap simpan registration sebagai pending
 			c.JSON(http.StatusCreated, gin.H{
@@ -198,34 +310,38 @@ func RegisterEvent(c *gin.Context) {
 			return
 		}
 
-		// Simpan payment token dan order ID ke database
-		registration.PaymentToken = token
-		registration.PaymentURL = redirectURL
-		registration.OrderID = orderID
-		if err := database.DB.Save(&registration).Error; err != nil {
-			c.JSON(http.StatusCreated, gin.H{
-				"success": true,
-				"message": "Pendaftaran berhasil, namun gagal menyimpan data pembayaran. Silakan hubungi panitia.",
-				"data":    registration,
-				"is_free": false,
-			})
-			return
-		}
-
 		c.JSON(http.StatusCreated, gin.H{
 			"success": true,
 			"message": "Pendaftaran berhasil! Silakan selesaikan pembayaran.",
 			"data":    registration,
 			"is_free": false,
-			"payment": gin.H{
-				"token":        token,
-				"redirect_url": redirectURL,
-				"order_id":     orderID,
-			},
+			"payment": paymentInstructions(payment, event),
 		})
 	}
 }
 
+// missingRequiredField mengecek jawaban form wajib event; mengembalikan label field pertama yang kosong
+func missingRequiredField(eventID uint, answers []RegistrationAnswer) (string, bool) {
+	var formFields []models.FormField
+	if err := database.DB.Where("event_id = ? AND is_required = ?", eventID, true).Find(&formFields).Error; err != nil {
+		return "", true
+	}
+	for _, field := range formFields {
+		// Cek apakah field required ada di jawaban
+		found := false
+		for _, ans := range answers {
+			if ans.FormFieldID == field.ID && ans.Value != "" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return field.Label, false
+		}
+	}
+	return "", true
+}
+
 // GET /api/events/:id/participants
 func GetParticipants(c *gin.Context) {
 	eventID := c.Param("id")
@@ -291,7 +407,7 @@ func GetUserRegistrations(c *gin.Context) {
 
 	// Hanya ambil registrations dari event yang statusnya bukan "done" (event masih aktif)
 	if err := database.DB.
-		Preload("Event").Preload("User").
+		Preload("Event").Preload("User").Preload("TicketType").
 		Joins("INNER JOIN events ON registrations.event_id = events.id").
 		Where("registrations.user_id = ? AND events.status != ?", userID, "done").
 		Find(&registrations).Error; err != nil {
@@ -351,9 +467,13 @@ func GetRegistrationStatus(c *gin.Context) {
 		return
 	}
 
-	// Cek apakah user sudah terdaftar
+	// Cek apakah user sudah terdaftar (pendaftaran yang dibatalkan dianggap belum terdaftar)
 	var registration models.Registration
-	result := database.DB.Where("event_id = ? AND user_id = ?", eventID, userID).Find(&registration)
+	result := database.DB.
+		Where("event_id = ? AND user_id = ? AND guest_email = ? AND status NOT IN ?", eventID, userID, "", models.InactiveRegistrationStatuses).
+		Order("id DESC").
+		Limit(1).
+		Find(&registration)
 
 	if result.Error != nil {
 		// Error database (bukan "record not found")
@@ -368,11 +488,17 @@ func GetRegistrationStatus(c *gin.Context) {
 	// Cek apakah record ditemukan
 	if result.RowsAffected == 0 {
 		// User belum terdaftar - ini normal, bukan error
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"success":    true,
 			"registered": false,
 			"message":    "Anda belum terdaftar di event ini",
-		})
+		}
+		if id, err := strconv.ParseUint(eventID, 10, 64); err == nil {
+			if entry, ok := helpers.GetActiveWaitlistEntry(uint(id), userID); ok {
+				response["waitlist"] = waitlistEntryResponse(entry)
+			}
+		}
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
@@ -386,7 +512,8 @@ func GetRegistrationStatus(c *gin.Context) {
 }
 
 type ScanRequest struct {
-	QRCode string `json:"qr_code" binding:"required"`
+	QRCode    string `json:"qr_code" binding:"required"`
+	SessionID *uint  `json:"session_id"` // Event multi-sesi: kosong = sesi yang sedang berlangsung
 }
 
 type UpdateStatusRequest struct {
@@ -406,11 +533,12 @@ func VerifyCheckIn(c *gin.Context) {
 		return
 	}
 
-	var registration models.Registration
-	if err := database.DB.Preload("Event").Preload("User").Where("qr_code = ?", req.QRCode).First(&registration).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "QR Code tidak valid / Data tidak ditemukan"})
+	found, status, message := findRegistrationByTicket(req.QRCode)
+	if found == nil {
+		c.JSON(status, gin.H{"success": false, "message": message})
 		return
 	}
+	registration := *found
 
 	// Cek event type: scan QR hanya untuk event offline dan hybrid
 	if registration.Event.EventType == "online" {
@@ -421,8 +549,45 @@ func VerifyCheckIn(c *gin.Context) {
 		return
 	}
 
-	if registration.Status == "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Peserta belum melunasi pembayaran (Status: Pending)"})
+	if message := checkInNotAllowedMessage(registration.Status); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": message})
+		return
+	}
+
+	// Event multi-sesi: kehadiran dicatat per sesi
+	if helpers.EventHasSessions(registration.EventID) {
+		panitiaUserID, _ := currentSession(c)
+		record, session, status, message := checkInToSession(&registration, req.SessionID, models.AttendanceMethodScan, &panitiaUserID, "")
+		if status == http.StatusConflict {
+			c.JSON(status, gin.H{
+				"success": false,
+				"message": message,
+				"data": gin.H{
+					"user_name":     registration.AttendeeName(),
+					"session":       session.Title,
+					"check_in_time": record.CheckInAt,
+				},
+			})
+			return
+		}
+		if status != 0 {
+			c.JSON(status, gin.H{"success": false, "message": message})
+			return
+		}
+
+		CreateActivity(panitiaUserID, "check_in", "registration", registration.ID,
+			fmt.Sprintf("Panitia melakukan check-in untuk %s di sesi %s event %s", registration.AttendeeName(), session.Title, registration.Event.Title))
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Check-in Berhasil!",
+			"data": gin.H{
+				"user_name": registration.AttendeeName(),
+				"event":     registration.Event.Title,
+				"session":   session.Title,
+				"status":    "Hadir",
+			},
+		})
 		return
 	}
 
@@ -432,16 +597,18 @@ func VerifyCheckIn(c *gin.Context) {
 			"message": "Peserta SUDAH Check-in sebelumnya!",
 			"data": gin.H{
 				"user_name":     registration.User.Name,
-				"check_in_time": registration.UpdatedAt,
+				"check_in_time": registration.CheckInTime(),
 			},
 		})
 		return
 	}
 
 	// Update attendance
+	now := time.Now()
 	registration.Status = "checked_in"
 	registration.Attendance = true
 	registration.AttendanceType = "offline" // Hadir secara offline (scan QR)
+	registration.CheckedInAt = &now
 	if err := database.DB.Save(&registration).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -477,6 +644,55 @@ func VerifyCheckIn(c *gin.Context) {
 	})
 }
 
+// findRegistrationByTicket memvalidasi QR tiket lalu mengambil registrasinya (Event dan User di-preload).
+// QR bertanda tangan dicek keasliannya sebelum query database dan masa berlakunya terhadap jadwal event;
+// QR format lama hanya diterima selama setting ticket_qr_accept_legacy aktif. Mengembalikan status HTTP dan pesan jika gagal.
+func findRegistrationByTicket(code string) (*models.Registration, int, string) {
+	return findRegistrationByTicketAt(code, time.Now())
+}
+
+// findRegistrationByTicketAt sama dengan findRegistrationByTicket, tetapi masa berlaku QR dicek
+// terhadap waktu scan (dipakai sinkronisasi scan offline yang dikirim belakangan). Masa berlaku
+// dihitung dari jadwal event saat ini, bukan yang tertulis di QR, supaya QR tetap sesuai walau
+// tanggal event diubah setelah QR diterbitkan.
+func findRegistrationByTicketAt(code string, at time.Time) (*models.Registration, int, string) {
+	query := database.DB.Preload("Event").Preload("User").Where("qr_code = ?", code)
+	signed := !helpers.IsLegacyTicketQR(code)
+	if !signed {
+		if !helpers.LegacyTicketQRAccepted() {
+			return nil, http.StatusBadRequest, "QR Code versi lama sudah tidak berlaku. Silakan tampilkan QR terbaru dari halaman Tiket Saya."
+		}
+	} else {
+		claims, err := helpers.VerifyTicketQR(code, at)
+		if err != nil && err != helpers.ErrTicketQRExpired {
+			return nil, http.StatusBadRequest, "QR Code tidak valid"
+		}
+		query = query.Where("id = ? AND event_id = ?", claims.RegistrationID, claims.EventID)
+	}
+
+	var registration models.Registration
+	if err := query.First(&registration).Error; err != nil {
+		return nil, http.StatusNotFound, "QR Code tidak valid / Data tidak ditemukan"
+	}
+	if signed && at.After(helpers.TicketQRExpiry(registration.Event)) {
+		return nil, http.StatusBadRequest, "QR Code sudah kadaluarsa"
+	}
+	return &registration, 0, ""
+}
+
+// checkInNotAllowedMessage mengembalikan alasan registrasi tidak boleh check-in (kosong jika boleh).
+// Hanya registrasi confirmed, atau yang sudah checked_in, yang bisa dipakai masuk; tiket yang
+// ditolak, dibatalkan atau sudah di-refund ditolak.
+func checkInNotAllowedMessage(status string) string {
+	switch status {
+	case "confirmed", "checked_in":
+		return ""
+	case "pending":
+		return "Peserta belum melunasi pembayaran (Status: Pending)"
+	}
+	return "Registrasi tidak aktif (Status: " + status + ")"
+}
+
 // POST /api/check-in/self (Self Check-in untuk event online via token/QRCode dengan upload bukti kehadiran)
 func SelfCheckIn(c *gin.Context) {
 	// Parse multipart form untuk token dan file bukti kehadiran
@@ -507,11 +723,12 @@ func SelfCheckIn(c *gin.Context) {
 	}
 
 	// Cari registration berdasarkan token/QRCode
-	var registration models.Registration
-	if err := database.DB.Preload("Event").Preload("User").Where("qr_code = ?", token).First(&registration).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Token tidak valid atau data tidak ditemukan"})
+	found, status, message := findRegistrationByTicket(token)
+	if found == nil {
+		c.JSON(status, gin.H{"success": false, "message": message})
 		return
 	}
+	registration := *found
 
 	// Validasi: Token harus milik user yang login
 	if registration.UserID != userID {
@@ -529,17 +746,19 @@ func SelfCheckIn(c *gin.Context) {
 	}
 
 	// Validasi: Status harus confirmed (sudah bayar/approve)
-	if registration.Status == "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Peserta belum melunasi pembayaran (Status: Pending)"})
+	if message := checkInNotAllowedMessage(registration.Status); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": message})
 		return
 	}
 
-	if registration.Status == "checked_in" {
+	// Event multi-sesi boleh check-in sekali per sesi; duplikat dicek saat mencatat kehadiran sesi
+	hasSessions := helpers.EventHasSessions(registration.EventID)
+	if registration.Status == "checked_in" && !hasSessions {
 		c.JSON(http.StatusConflict, gin.H{
 			"success": false,
 			"message": "Anda sudah melakukan check-in sebelumnya!",
 			"data": gin.H{
-				"check_in_time": registration.UpdatedAt,
+				"check_in_time": registration.CheckInTime(),
 			},
 		})
 		return
@@ -570,10 +789,52 @@ func SelfCheckIn(c *gin.Context) {
 		proofURL = fmt.Sprintf("http://localhost:8000/public/attendance_proofs/%s", filename)
 	}
 
+	if hasSessions {
+		var sessionID *uint
+		if value, err := strconv.ParseUint(c.PostForm("session_id"), 10, 64); err == nil {
+			id := uint(value)
+			sessionID = &id
+		}
+		record, session, status, message := checkInToSession(&registration, sessionID, models.AttendanceMethodSelf, nil, proofURL)
+		if status == http.StatusConflict {
+			c.JSON(status, gin.H{
+				"success": false,
+				"message": "Anda sudah melakukan check-in di sesi ini!",
+				"data": gin.H{
+					"session":       session.Title,
+					"check_in_time": record.CheckInAt,
+				},
+			})
+			return
+		}
+		if status != 0 {
+			c.JSON(status, gin.H{"success": false, "message": message})
+			return
+		}
+
+		CreateActivity(userID, "check_in", "registration", registration.ID,
+			fmt.Sprintf("%s checked in to session %s of online event %s", registration.User.Name, session.Title, registration.Event.Title))
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Check-in Berhasil! Terima kasih sudah hadir di sesi ini.",
+			"data": gin.H{
+				"event":         registration.Event.Title,
+				"session":       session.Title,
+				"status":        "Hadir",
+				"check_in_time": record.CheckInAt,
+				"proof_url":     proofURL,
+			},
+		})
+		return
+	}
+
 	// Update attendance dan bukti kehadiran
+	now := time.Now()
 	registration.Status = "checked_in"
 	registration.Attendance = true
 	registration.AttendanceType = "online" // Hadir secara online (self check-in)
+	registration.CheckedInAt = &now
 	if proofURL != "" {
 		registration.AttendanceProofURL = proofURL
 	}
@@ -598,7 +859,7 @@ func SelfCheckIn(c *gin.Context) {
 		"data": gin.H{
 			"event":         registration.Event.Title,
 			"status":        "Hadir",
-			"check_in_time": time.Now(),
+			"check_in_time": now,
 			"proof_url":     proofURL,
 		},
 	})
@@ -630,7 +891,8 @@ func UpdateAttendance(c *gin.Context) {
 
 	// Parse request body
 	var req struct {
-		Attendance bool `json:"attendance"` // true = hadir, false = tidak hadir
+		Attendance bool  `json:"attendance"` // true = hadir, false = tidak hadir
+		SessionID  *uint `json:"session_id"` // Wajib untuk event multi-sesi
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -650,31 +912,67 @@ func UpdateAttendance(c *gin.Context) {
 		return
 	}
 
-	// Cek apakah user adalah panitia dari event ini ATAU Super Admin
-	var committeeMember models.CommitteeMember
-	isCommittee := database.DB.Where("event_id = ? AND user_id = ?", registration.EventID, userID).First(&committeeMember).Error == nil
+	// Cek permission attendance.manage (role global atau panitia event ini)
+	if !helpers.HasEventPermission(userID, registration.EventID, "attendance.manage") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": "Anda tidak memiliki izin untuk mengubah kehadiran peserta event ini.",
+		})
+		return
+	}
 
-	// Cek apakah user adalah Super Admin
-	var currentUser models.User
-	isSuperAdmin := false
-	if err := database.DB.Preload("Role").First(&currentUser, userID).Error; err == nil {
-		roleCodeLower := strings.ToLower(currentUser.Role.Code)
-		roleNameUpper := strings.ToUpper(currentUser.Role.Name)
-		isSuperAdmin = roleCodeLower == "superadmin" ||
-			strings.Contains(roleNameUpper, "SUPER ADMIN") ||
-			strings.Contains(roleNameUpper, "SUPERADMIN")
+	// Event multi-sesi: kehadiran diubah per sesi, status registrasi mengikuti record kehadirannya
+	if helpers.EventHasSessions(registration.EventID) {
+		if req.SessionID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Event ini memiliki beberapa sesi. Pilih sesi (session_id) yang akan diubah kehadirannya.",
+			})
+			return
+		}
+		session, err := helpers.ResolveEventSession(registration.EventID, req.SessionID, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": sessionErrorMessage(err)})
+			return
+		}
 
-		// Debug logging
-		fmt.Printf("UpdateAttendance: User ID=%d, Role.Code=%s, Role.Name=%s, isSuperAdmin=%v, isCommittee=%v\n",
-			userID, currentUser.Role.Code, currentUser.Role.Name, isSuperAdmin, isCommittee)
-	} else {
-		fmt.Printf("UpdateAttendance: Error fetching user ID=%d: %v\n", userID, err)
-	}
+		err = database.DB.Transaction(func(tx *gorm.DB) error {
+			if req.Attendance {
+				_, err := helpers.RecordSessionCheckIn(tx, &registration, session, models.AttendanceMethodManual, time.Now(), &userID, "")
+				if err == helpers.ErrSessionAlreadyCheckedIn {
+					return nil
+				}
+				return err
+			}
+			if err := tx.Where("registration_id = ? AND event_session_id = ?", registration.ID, session.ID).
+				Delete(&models.AttendanceRecord{}).Error; err != nil {
+				return err
+			}
+			return helpers.SyncRegistrationAttendance(tx, &registration)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Gagal mengupdate kehadiran",
+			})
+			return
+		}
 
-	if !isCommittee && !isSuperAdmin {
-		c.JSON(http.StatusForbidden, gin.H{
-			"success": false,
-			"message": fmt.Sprintf("Anda bukan panitia dari event ini. (User ID: %d, isCommittee: %v, isSuperAdmin: %v)", userID, isCommittee, isSuperAdmin),
+		label := "Tidak Hadir"
+		if req.Attendance {
+			label = "Hadir"
+		}
+		CreateActivity(userID, "attendance_updated", "registration", registration.ID,
+			fmt.Sprintf("%s mengupdate kehadiran %s di sesi %s menjadi %s untuk event %s",
+				getUserName(userID), registration.AttendeeName(), session.Title, label, registration.Event.Title))
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": fmt.Sprintf("Kehadiran sesi %s berhasil diupdate menjadi %s", session.Title, label),
+			"data": gin.H{
+				"registration": registration,
+				"attendance":   helpers.GetAttendanceSummary(registration.Event, registration),
+			},
 		})
 		return
 	}
@@ -687,12 +985,17 @@ func UpdateAttendance(c *gin.Context) {
 	if req.Attendance {
 		// Jika attendance = true, ubah status menjadi checked_in
 		registration.Status = "checked_in"
+		if registration.CheckedInAt == nil {
+			now := time.Now()
+			registration.CheckedInAt = &now
+		}
 		fmt.Printf("UpdateAttendance: Registration ID=%d: attendance %v -> %v, status %s -> checked_in\n",
 			registration.ID, oldAttendance, req.Attendance, oldStatus)
 	} else {
 		// Jika attendance = false, ubah status kembali ke confirmed (jika sebelumnya checked_in)
 		if registration.Status == "checked_in" {
 			registration.Status = "confirmed"
+			registration.CheckedInAt = nil
 			fmt.Printf("UpdateAttendance: Registration ID=%d: attendance %v -> %v, status checked_in -> confirmed\n",
 				registration.ID, oldAttendance, req.Attendance)
 		} else {
@@ -724,10 +1027,7 @@ func UpdateAttendance(c *gin.Context) {
 		registration.ID, registration.Attendance, registration.Status)
 
 	// Log activity
-	actorType := "Panitia"
-	if isSuperAdmin {
-		actorType = "Super Admin"
-	}
+	actorType := getUserName(userID)
 	if req.Attendance {
 		CreateActivity(userID, "attendance_updated", "registration", registration.ID,
 			fmt.Sprintf("%s mengupdate kehadiran %s menjadi Hadir untuk event %s", actorType, registration.User.Name, registration.Event.Title))
@@ -784,26 +1084,15 @@ func UpdateRegistrationStatus(c *gin.Context) {
 		return
 	}
 
-	// Kirim email jika status confirmed
-	if req.Status == "confirmed" && registration.User.Email != "" {
-		eventDate := ""
-		if !registration.Event.StartDate.IsZero() {
-			eventDate = registration.Event.StartDate.Format("02 Jan 2006")
-		}
-		go helpers.SendSuccessEmail(
-			registration.User.Email,
-			registration.User.Name,
-			registration.Event.Title,
-			eventDate,
-			registration.Event.Location,
-		)
-		// Kirim notifikasi approved
-		go helpers.NotifyRegistrationApproved(registration.UserID, registration.Event.Title, registration.Event.Slug)
+	// Kirim email dan notifikasi approved jika status confirmed (tamu grup menerima tiket lewat email)
+	if req.Status == "confirmed" {
+		sendTicketConfirmation(registration)
 	}
 
-	// Kirim notifikasi rejected
+	// Kirim notifikasi rejected, kursinya ditawarkan ke waitlist
 	if req.Status == "rejected" {
 		go helpers.NotifyRegistrationRejected(registration.UserID, registration.Event.Title, "")
+		go helpers.PromoteWaitlist(registration.EventID)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -857,22 +1146,8 @@ func BulkUpdateRegistrationStatus(c *gin.Context) {
 		var regs []models.Registration
 		if err := database.DB.Preload("User").Preload("Event").Where("id IN ?", req.RegistrationIDs).Find(&regs).Error; err == nil {
 			for _, r := range regs {
-				if r.User.Email == "" {
-					continue
-				}
-				eventDate := ""
-				if !r.Event.StartDate.IsZero() {
-					eventDate = r.Event.StartDate.Format("02 Jan 2006")
-				}
-				go helpers.SendSuccessEmail(
-					r.User.Email,
-					r.User.Name,
-					r.Event.Title,
-					eventDate,
-					r.Event.Location,
-				)
-				// Kirim notifikasi approved
-				go helpers.NotifyRegistrationApproved(r.UserID, r.Event.Title, r.Event.Slug)
+				// Kirim email dan notifikasi approved (tamu grup menerima tiket lewat email)
+				sendTicketConfirmation(r)
 			}
 		}
 	}
@@ -881,8 +1156,14 @@ func BulkUpdateRegistrationStatus(c *gin.Context) {
 	if req.Status == "rejected" && updatedCount > 0 {
 		var regs []models.Registration
 		if err := database.DB.Preload("User").Preload("Event").Where("id IN ?", req.RegistrationIDs).Find(&regs).Error; err == nil {
+			promoteEvents := map[uint]bool{}
 			for _, r := range regs {
 				go helpers.NotifyRegistrationRejected(r.UserID, r.Event.Title, "")
+				promoteEvents[r.EventID] = true
+			}
+			// Kursi yang dilepas ditawarkan ke waitlist
+			for eventID := range promoteEvents {
+				go helpers.PromoteWaitlist(eventID)
 			}
 		}
 	}
@@ -0,0 +1,449 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GroupAttendeeRequest struct {
+	Email   string               `json:"email" binding:"required,email"`
+	Name    string               `json:"name" binding:"max=100"` // Wajib untuk tamu yang belum punya akun
+	Answers []RegistrationAnswer `json:"answers"`
+}
+
+type GroupRegistrationRequest struct {
+	Name         string                 `json:"name" binding:"max=150"` // Nama organisasi/delegasi
+	TicketTypeID *uint                  `json:"ticket_type_id"`         // Wajib jika event punya tipe tiket
+	AccessCode   string                 `json:"access_code"`            // Wajib untuk tipe tiket hidden
+	Attendees    []GroupAttendeeRequest `json:"attendees" binding:"required,min=1,dive"`
+}
+
+// groupAttendee adalah peserta grup yang sudah dicocokkan dengan akun user (jika emailnya terdaftar)
+type groupAttendee struct {
+	UserID     uint
+	User       *models.User
+	GuestName  string
+	GuestEmail string
+	Answers    []RegistrationAnswer
+}
+
+// POST /api/events/:id/register-group
+// Satu pendaftar mendaftarkan beberapa peserta sekaligus: user terdaftar (dicocokkan lewat email) atau
+// tamu dengan nama/email. Setiap peserta mendapat registrasi dan QR tiket sendiri; tagihan seluruh
+// grup dibayar sekali lewat registrasi ketua.
+func RegisterGroup(c *gin.Context) {
+	registrantID, _ := currentSession(c)
+
+	var registrant models.User
+	if err := database.DB.First(&registrant, registrantID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, structs.ErrorResponse{
+			Success: false,
+			Message: "Unauthorized",
+		})
+		return
+	}
+
+	var event models.Event
+	if err := database.DB.First(&event, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Event tidak ditemukan",
+		})
+		return
+	}
+	if event.Status != "published" {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Event belum dibuka untuk pendaftaran. Status: " + event.Status,
+		})
+		return
+	}
+	if event.RegistrationDeadline != nil && time.Now().After(*event.RegistrationDeadline) {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran sudah ditutup. Batas waktu pendaftaran: " + event.RegistrationDeadline.Format("02 January 2006 pukul 15:04"),
+		})
+		return
+	}
+
+	var req GroupRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	maxSize := helpers.GetSettingInt("group_registration_max_size", 20)
+	if maxSize > 0 && len(req.Attendees) > maxSize {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: fmt.Sprintf("Maksimal %d peserta dalam satu pendaftaran grup", maxSize),
+		})
+		return
+	}
+
+	attendees, fieldErrors := resolveGroupAttendees(event.ID, registrant, req.Attendees)
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Data peserta tidak valid",
+			Errors:  fieldErrors,
+		})
+		return
+	}
+
+	ticketType, err := helpers.ResolveTicketType(event.ID, req.TicketTypeID, req.AccessCode, time.Now())
+	if err != nil {
+		message := "Tipe tiket tidak valid"
+		switch err {
+		case helpers.ErrTicketTypeRequired:
+			message = "Silakan pilih tipe tiket terlebih dahulu"
+		case helpers.ErrTicketAccessCode:
+			message = "Kode akses tiket tidak valid"
+		case helpers.ErrTicketNotOnSale:
+			message = "Tiket yang dipilih sedang tidak dalam periode penjualan"
+		case helpers.ErrTicketSoldOut:
+			message = "Kuota tiket yang dipilih sudah habis. Silakan pilih tipe tiket lain."
+		}
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: message,
+		})
+		return
+	}
+
+	// Antrian waitlist didahulukan atas pendaftar baru
+	if event.Quota > 0 && event.WaitlistEnabled && helpers.CountWaitlistWaiting(event.ID) > 0 {
+		go helpers.PromoteWaitlist(event.ID)
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Kuota event sudah penuh",
+		})
+		return
+	}
+
+	price := event.Price
+	if ticketType != nil {
+		price = ticketType.Price
+	}
+	total := price * int64(len(attendees))
+
+	// Ketua grup (penanggung tagihan) harus registrasi milik pendaftar: dirinya sendiri atau tamunya.
+	// Tagihan tidak boleh jatuh ke akun peserta lain, karena pembayaran, pembatalan dan refund
+	// hanya bisa dilakukan pemilik registrasi ketua.
+	lead := -1
+	for i, a := range attendees {
+		if a.UserID == registrantID {
+			lead = i
+			break
+		}
+	}
+	if lead < 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Daftar peserta harus memuat Anda sendiri atau tamu tanpa akun yang Anda daftarkan",
+		})
+		return
+	}
+
+	tx := database.DB.Begin()
+
+	if err := helpers.ReserveGroupSeats(tx, event.ID, req.TicketTypeID, len(attendees)); err != nil {
+		tx.Rollback()
+		switch err {
+		case helpers.ErrEventFull:
+			c.JSON(http.StatusConflict, structs.ErrorResponse{
+				Success: false,
+				Message: fmt.Sprintf("Sisa kuota event tidak cukup untuk %d peserta", len(attendees)),
+			})
+		case helpers.ErrTicketSoldOut:
+			c.JSON(http.StatusConflict, structs.ErrorResponse{
+				Success: false,
+				Message: fmt.Sprintf("Sisa kuota tiket yang dipilih tidak cukup untuk %d peserta", len(attendees)),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+				Success: false,
+				Message: "Gagal menyimpan pendaftaran grup",
+			})
+		}
+		return
+	}
+
+	// Peserta yang sudah terdaftar dicek ulang setelah event dikunci
+	registeredErrors := map[string]string{}
+	for i, a := range attendees {
+		if helpers.HasActiveRegistration(tx, event.ID, a.UserID, a.GuestEmail) {
+			registeredErrors[fmt.Sprintf("attendees.%d.email", i)] = "Peserta ini sudah terdaftar di event"
+		}
+	}
+	if len(registeredErrors) > 0 {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Sebagian peserta sudah terdaftar di event ini",
+			Errors:  registeredErrors,
+		})
+		return
+	}
+
+	group := models.RegistrationGroup{
+		EventID:      event.ID,
+		RegistrantID: registrantID,
+		Name:         req.Name,
+		TicketTypeID: req.TicketTypeID,
+		Quantity:     len(attendees),
+		TotalPrice:   total,
+	}
+	if err := tx.Create(&group).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menyimpan pendaftaran grup",
+		})
+		return
+	}
+
+	registrations := make([]models.Registration, len(attendees))
+	for i, a := range attendees {
+		registration := models.Registration{
+			EventID:    event.ID,
+			UserID:     a.UserID,
+			Status:     "pending",
+			QRCode:     helpers.TemporaryTicketCode(),
+			Attendance: false,
+			GroupID:    &group.ID,
+			GuestName:  a.GuestName,
+			GuestEmail: a.GuestEmail,
+		}
+		if ticketType != nil {
+			registration.TicketTypeID = &ticketType.ID
+		}
+		// Tagihan seluruh grup ada di registrasi ketua
+		if i == lead {
+			registration.Price = total
+		}
+
+		if err := tx.Create(&registration).Error; err != nil {
+			tx.Rollback()
+			if helpers.IsDuplicateEntryError(err) {
+				c.JSON(http.StatusConflict, structs.ErrorResponse{
+					Success: false,
+					Message: "Sebagian peserta sudah terdaftar di event ini",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+				Success: false,
+				Message: "Gagal menyimpan pendaftaran grup",
+			})
+			return
+		}
+		if err := helpers.AssignTicketQR(tx, &registration, event); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+				Success: false,
+				Message: "Gagal membuat QR tiket",
+			})
+			return
+		}
+
+		for _, ans := range a.Answers {
+			answer := models.FormAnswer{
+				RegistrationID: registration.ID,
+				FormFieldID:    ans.FormFieldID,
+				Value:          ans.Value,
+			}
+			if err := tx.Create(&answer).Error; err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+					Success: false,
+					Message: "Gagal menyimpan jawaban form",
+				})
+				return
+			}
+		}
+		registrations[i] = registration
+	}
+
+	group.LeadRegistrationID = &registrations[lead].ID
+	if err := tx.Model(&group).Update("lead_registration_id", group.LeadRegistrationID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menyimpan pendaftaran grup",
+		})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menyimpan pendaftaran grup",
+		})
+		return
+	}
+
+	// Notifikasi ke pendaftar, peserta yang punya akun, dan panitia
+	go helpers.NotifyRegistrationSubmitted(registrantID, event.Title, event.Slug)
+	for _, a := range attendees {
+		if a.User != nil && a.UserID != registrantID {
+			go helpers.NotifyRegistrationSubmitted(a.UserID, event.Title, event.Slug)
+		}
+	}
+	go helpers.NotifyNewRegistration(event.ID, event.Title,
+		fmt.Sprintf("%s (grup %d peserta)", registrant.Name, len(attendees)))
+
+	CreateActivity(registrantID, "registered", "event", event.ID,
+		fmt.Sprintf("mendaftarkan %d peserta ke event %s", len(attendees), event.Title))
+
+	group.Registrations = registrations
+	if total == 0 {
+		c.JSON(http.StatusCreated, structs.SuccessResponse{
+			Success: true,
+			Message: "Pendaftaran grup berhasil! Silakan tunggu konfirmasi dari panitia.",
+			Data:    gin.H{"group": group, "is_free": true},
+		})
+		return
+	}
+
+	var payment *models.Payment
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		payment, err = createPayment(tx, registrations[lead], registrant, event)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusCreated, structs.SuccessResponse{
+			Success: true,
+			Message: "Pendaftaran grup berhasil, namun gagal membuat link pembayaran. Silakan hubungi panitia.",
+			Data:    gin.H{"group": group, "is_free": false, "payment_error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Pendaftaran grup berhasil! Silakan selesaikan pembayaran.",
+		Data:    gin.H{"group": group, "is_free": false, "payment": paymentInstructions(payment, event)},
+	})
+}
+
+// GET /api/registration-groups/:id
+// Detail pendaftaran grup beserta tiket tiap peserta untuk pendaftar, pembuat event dan panitia
+func GetRegistrationGroup(c *gin.Context) {
+	var group models.RegistrationGroup
+	if err := database.DB.Preload("Event").Preload("Registrant").
+		Preload("Registrations.User").Preload("Registrations.TicketType").
+		First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran grup tidak ditemukan",
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	if group.RegistrantID != userID {
+		if _, ok := loadManagedEvent(c, group.EventID, "Anda tidak memiliki akses ke pendaftaran grup ini"); !ok {
+			return
+		}
+	}
+
+	var payment *models.Payment
+	if group.LeadRegistrationID != nil {
+		payment, _ = helpers.GetLatestPayment(*group.LeadRegistrationID)
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Detail pendaftaran grup",
+		Data:    gin.H{"group": group, "payment": payment},
+	})
+}
+
+// resolveGroupAttendees mencocokkan email peserta dengan akun user, memvalidasi tamu dan jawaban form
+// wajib tiap peserta. Error dikembalikan per field (attendees.N.field).
+func resolveGroupAttendees(eventID uint, registrant models.User, inputs []GroupAttendeeRequest) ([]groupAttendee, map[string]string) {
+	fieldErrors := map[string]string{}
+	seen := map[string]bool{}
+	attendees := make([]groupAttendee, 0, len(inputs))
+
+	for i, input := range inputs {
+		email := strings.ToLower(strings.TrimSpace(input.Email))
+		if seen[email] {
+			fieldErrors[fmt.Sprintf("attendees.%d.email", i)] = "Email peserta tidak boleh sama"
+			continue
+		}
+		seen[email] = true
+
+		attendee := groupAttendee{Answers: input.Answers}
+		var user models.User
+		if err := database.DB.Where("email = ?", email).First(&user).Error; err == nil {
+			attendee.UserID = user.ID
+			attendee.User = &user
+		} else {
+			// Tamu tanpa akun dicatat atas nama pendaftar
+			name := strings.TrimSpace(input.Name)
+			if name == "" {
+				fieldErrors[fmt.Sprintf("attendees.%d.name", i)] = "Nama wajib diisi untuk peserta yang belum punya akun"
+				continue
+			}
+			attendee.UserID = registrant.ID
+			attendee.GuestName = name
+			attendee.GuestEmail = email
+		}
+
+		if label, ok := missingRequiredField(eventID, input.Answers); !ok {
+			fieldErrors[fmt.Sprintf("attendees.%d.answers", i)] = fmt.Sprintf("Field '%s' wajib diisi", label)
+			continue
+		}
+		attendees = append(attendees, attendee)
+	}
+	return attendees, fieldErrors
+}
+
+// sendTicketConfirmation mengirim email konfirmasi tiket ke peserta registrasi (User dan Event harus
+// di-preload): peserta tamu menerima kode tiketnya lewat email, peserta biasa juga mendapat notifikasi.
+func sendTicketConfirmation(registration models.Registration) {
+	eventDate := ""
+	if !registration.Event.StartDate.IsZero() {
+		eventDate = registration.Event.StartDate.Format("02 Jan 2006")
+	}
+
+	if registration.IsGuest() {
+		go helpers.SendGuestTicketEmail(
+			registration.GuestEmail,
+			registration.GuestName,
+			registration.User.Name,
+			registration.Event.Title,
+			eventDate,
+			registration.Event.Location,
+			registration.QRCode,
+		)
+		return
+	}
+
+	if registration.User.Email != "" {
+		go helpers.SendSuccessEmail(
+			registration.User.Email,
+			registration.User.Name,
+			registration.Event.Title,
+			eventDate,
+			registration.Event.Location,
+		)
+	}
+	go helpers.NotifyRegistrationApproved(registration.UserID, registration.Event.Title, registration.Event.Slug)
+}
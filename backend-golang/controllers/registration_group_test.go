@@ -0,0 +1,31 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"testing"
+)
+
+// Pendaftaran grup yang hanya berisi akun peserta lain ditolak: tagihan grup harus milik pendaftar
+func TestGroupRegistrationRequiresRegistrantOwnedLead(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	registrant := createUser(t)
+	other := createUser(t)
+	event := createEvent(t, organizer, 50000, 10)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/events/%d/register-group", event.ID), login(t, registrant),
+		map[string]interface{}{
+			"attendees": []map[string]interface{}{{"email": other.Email}},
+		})
+	requireStatus(t, "register grup tanpa pendaftar", code, http.StatusBadRequest, body)
+
+	var count int64
+	database.DB.Model(&models.Registration{}).Where("event_id = ?", event.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("registrasi grup tersimpan (%d), want 0", count)
+	}
+}
@@ -0,0 +1,93 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"sync"
+	"testing"
+)
+
+// Ratusan pendaftaran bersamaan ke event berkuota tidak pernah melebihi kuota
+func TestConcurrentRegistrationsRespectQuota(t *testing.T) {
+	requireDB(t)
+
+	const quota, participants = 10, 300
+	event := createEvent(t, createUser(t), 0, quota)
+	path := fmt.Sprintf("/api/participant/event/%d/register", event.ID)
+
+	tokens := make([]string, participants)
+	for i := range tokens {
+		tokens[i] = login(t, createUser(t))
+	}
+
+	// Request yang berjalan bersamaan dibatasi supaya koneksi database tidak melebihi max_connections MySQL
+	inFlight := make(chan struct{}, 50)
+	var wg sync.WaitGroup
+	codes := make([]int, participants)
+	for i, token := range tokens {
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			inFlight <- struct{}{}
+			defer func() { <-inFlight }()
+			codes[i], _ = doJSON(t, http.MethodPost, path, token, map[string]interface{}{"answers": []interface{}{}})
+		}(i, token)
+	}
+	wg.Wait()
+
+	created := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusBadRequest, http.StatusConflict:
+		default:
+			t.Errorf("pendaftaran peserta #%d: status %d, want 201 atau ditolak karena kuota penuh", i, code)
+		}
+	}
+
+	var active int64
+	database.DB.Model(&models.Registration{}).
+		Where("event_id = ? AND status NOT IN ?", event.ID, models.ReleasedSeatStatuses).
+		Count(&active)
+	if created != quota || int(active) != quota {
+		t.Fatalf("berhasil daftar %d (tersimpan %d registrasi aktif), want %d", created, active, quota)
+	}
+}
+
+// Satu peserta yang mendaftar berkali-kali bersamaan hanya mendapat satu registrasi
+func TestConcurrentDuplicateRegistrationStoresOne(t *testing.T) {
+	requireDB(t)
+
+	event := createEvent(t, createUser(t), 0, 10)
+	participant := createUser(t)
+	token := login(t, participant)
+	path := fmt.Sprintf("/api/participant/event/%d/register", event.ID)
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	codes := make([]int, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i], _ = doJSON(t, http.MethodPost, path, token, map[string]interface{}{"answers": []interface{}{}})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		if code == http.StatusCreated {
+			created++
+		}
+	}
+
+	var count int64
+	database.DB.Model(&models.Registration{}).Where("event_id = ? AND user_id = ?", event.ID, participant.ID).Count(&count)
+	if created != 1 || count != 1 {
+		t.Fatalf("pendaftaran ganda bersamaan: %d berhasil, %d tersimpan, want 1", created, count)
+	}
+}
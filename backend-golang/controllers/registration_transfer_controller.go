@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransferRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Note  string `json:"note" binding:"max=255"`
+}
+
+type AcceptTransferRequest struct {
+	Answers []RegistrationAnswer `json:"answers"`
+}
+
+// POST /api/registrations/:id/transfer
+// Pemilik tiket menominasikan penerima (user terdaftar) lewat email. Tiket baru berpindah setelah
+// penerima menerima transfer dan mengisi form pendaftaran.
+func RequestTransfer(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var registration models.Registration
+	if err := database.DB.Preload("Event").Preload("User").First(&registration, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Registrasi tidak ditemukan",
+		})
+		return
+	}
+	if registration.UserID != userID {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda hanya dapat mentransfer tiket milik Anda sendiri",
+		})
+		return
+	}
+
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	if err := helpers.CanTransferRegistration(database.DB, registration, registration.Event, time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: transferErrorMessage(err),
+		})
+		return
+	}
+
+	var recipient models.User
+	if err := database.DB.Where("email = ?", strings.ToLower(strings.TrimSpace(req.Email))).First(&recipient).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Penerima belum memiliki akun. Minta penerima mendaftar terlebih dahulu.",
+			Errors:  map[string]string{"email": "Email tidak terdaftar"},
+		})
+		return
+	}
+	if recipient.ID == userID {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Tidak dapat mentransfer tiket ke akun sendiri",
+		})
+		return
+	}
+	if helpers.HasActiveRegistration(database.DB, registration.EventID, recipient.ID, "") {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Penerima sudah terdaftar di event ini",
+		})
+		return
+	}
+
+	// Satu registrasi hanya boleh punya satu transfer yang menunggu respon
+	helpers.ExpireRegistrationTransfers()
+	var pending int64
+	database.DB.Model(&models.RegistrationTransfer{}).
+		Where("registration_id = ? AND status = ?", registration.ID, models.TransferPending).
+		Count(&pending)
+	if pending > 0 {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Tiket ini sedang dalam proses transfer. Batalkan transfer sebelumnya terlebih dahulu.",
+		})
+		return
+	}
+
+	transfer := models.RegistrationTransfer{
+		RegistrationID: registration.ID,
+		EventID:        registration.EventID,
+		FromUserID:     userID,
+		ToUserID:       recipient.ID,
+		Status:         models.TransferPending,
+		Note:           req.Note,
+		ExpiresAt:      helpers.TransferDeadlineFor(registration.Event),
+	}
+	if err := database.DB.Create(&transfer).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat transfer tiket",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	go helpers.NotifyTransferRequested(recipient.ID, registration.User.Name, registration.Event.Title, transfer.ID, transfer.ExpiresAt)
+	CreateActivity(userID, "ticket_transfer_requested", "event", registration.EventID,
+		"mengajukan transfer tiket event "+registration.Event.Title+" ke "+recipient.Name)
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Transfer tiket diajukan. Tiket akan berpindah setelah penerima menerimanya.",
+		Data:    transfer,
+	})
+}
+
+// GET /api/registration-transfers
+// Transfer tiket yang diterima (incoming) dan yang diajukan (outgoing) oleh user
+func GetMyTransfers(c *gin.Context) {
+	userID, _ := currentSession(c)
+	helpers.ExpireRegistrationTransfers()
+
+	var incoming, outgoing []models.RegistrationTransfer
+	database.DB.Preload("Event").Preload("FromUser").
+		Where("to_user_id = ?", userID).Order("id DESC").Find(&incoming)
+	database.DB.Preload("Event").Preload("ToUser").
+		Where("from_user_id = ?", userID).Order("id DESC").Find(&outgoing)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar transfer tiket",
+		Data: gin.H{
+			"incoming": incoming,
+			"outgoing": outgoing,
+		},
+	})
+}
+
+// POST /api/registration-transfers/:id/accept
+// Penerima menerima transfer dan mengisi ulang form pendaftaran event
+func AcceptTransfer(c *gin.Context) {
+	transfer, ok := loadTransferForRecipient(c)
+	if !ok {
+		return
+	}
+
+	var req AcceptTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Data jawaban tidak valid",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	if label, ok := missingRequiredField(transfer.EventID, req.Answers); !ok {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Field '" + label + "' wajib diisi",
+		})
+		return
+	}
+
+	answers := make([]models.FormAnswer, 0, len(req.Answers))
+	for _, ans := range req.Answers {
+		answers = append(answers, models.FormAnswer{FormFieldID: ans.FormFieldID, Value: ans.Value})
+	}
+
+	oldRegistration := transfer.Registration
+	registration, err := helpers.AcceptRegistrationTransfer(transfer.ID, transfer.ToUserID, answers)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == helpers.ErrAlreadyRegistered {
+			status = http.StatusConflict
+		}
+		c.JSON(status, structs.ErrorResponse{
+			Success: false,
+			Message: transferErrorMessage(err),
+		})
+		return
+	}
+
+	CreateAuditLog(transfer.ToUserID, "transfer", "registration", registration.ID,
+		gin.H{"user_id": oldRegistration.UserID, "qr_code": oldRegistration.QRCode},
+		gin.H{"user_id": registration.UserID, "qr_code": registration.QRCode},
+		"Transfer tiket event "+transfer.Event.Title+" dari "+transfer.FromUser.Name+" ke "+transfer.ToUser.Name,
+		c.ClientIP(), c.Request.UserAgent())
+	CreateActivity(transfer.ToUserID, "ticket_transfer_accepted", "event", transfer.EventID,
+		"menerima transfer tiket event "+transfer.Event.Title+" dari "+transfer.FromUser.Name)
+
+	go helpers.NotifyTransferAccepted(transfer.FromUserID, transfer.ToUserID, transfer.FromUser.Name, transfer.ToUser.Name, transfer.Event.Title)
+	go helpers.NotifyTicketTransferred(transfer.EventID, transfer.Event.Title, transfer.FromUser.Name, transfer.ToUser.Name)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Transfer tiket diterima. Tiket sekarang milik Anda.",
+		Data:    registration,
+	})
+}
+
+// POST /api/registration-transfers/:id/decline
+func DeclineTransfer(c *gin.Context) {
+	transfer, ok := loadTransferForRecipient(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Model(&models.RegistrationTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, models.TransferPending).
+		Updates(map[string]interface{}{
+			"status":       models.TransferDeclined,
+			"responded_at": time.Now(),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: transferErrorMessage(helpers.ErrTransferNotPending),
+		})
+		return
+	}
+
+	go helpers.NotifyTransferDeclined(transfer.FromUserID, transfer.ToUser.Name, transfer.Event.Title)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Transfer tiket ditolak",
+	})
+}
+
+// DELETE /api/registration-transfers/:id
+// Pemilik tiket membatalkan transfer yang belum direspon penerima
+func CancelTransfer(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var transfer models.RegistrationTransfer
+	if err := database.DB.First(&transfer, c.Param("id")).Error; err != nil || transfer.FromUserID != userID {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Transfer tiket tidak ditemukan",
+		})
+		return
+	}
+
+	result := database.DB.Model(&models.RegistrationTransfer{}).
+		Where("id = ? AND status = ?", transfer.ID, models.TransferPending).
+		Update("status", models.TransferCancelled)
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: transferErrorMessage(helpers.ErrTransferNotPending),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Transfer tiket dibatalkan",
+	})
+}
+
+// loadTransferForRecipient mengambil transfer yang ditujukan ke user login.
+// Response error sudah ditulis jika mengembalikan false.
+func loadTransferForRecipient(c *gin.Context) (*models.RegistrationTransfer, bool) {
+	userID, _ := currentSession(c)
+
+	var transfer models.RegistrationTransfer
+	if err := database.DB.Preload("Registration").Preload("Event").Preload("FromUser").Preload("ToUser").
+		First(&transfer, c.Param("id")).Error; err != nil || transfer.ToUserID != userID {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Transfer tiket tidak ditemukan",
+		})
+		return nil, false
+	}
+	return &transfer, true
+}
+
+func transferErrorMessage(err error) string {
+	switch err {
+	case helpers.ErrTransferUnavailable:
+		return "Event ini tidak mengizinkan transfer tiket"
+	case helpers.ErrTransferExpired:
+		return "Batas waktu transfer tiket sudah lewat"
+	case helpers.ErrTicketNotTransfer:
+		return "Tiket ini tidak bisa ditransfer (sudah check-in, dibatalkan, atau bukan milik pemilik transfer)"
+	case helpers.ErrTransferGroupLead:
+		return "Tiket ketua grup tidak bisa ditransfer karena menanggung tiket anggota grup"
+	case helpers.ErrTransferNotPending:
+		return "Transfer tiket sudah tidak berlaku"
+	case helpers.ErrAlreadyRegistered:
+		return "Penerima sudah terdaftar di event ini"
+	}
+	return "Gagal memproses transfer tiket"
+}
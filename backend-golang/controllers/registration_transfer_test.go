@@ -0,0 +1,48 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"testing"
+)
+
+// Tiket ketua grup tidak bisa ditransfer: tiket tamu anggota grupnya tetap milik pendaftar
+func TestGroupLeadRegistrationCannotBeTransferred(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	registrant := createUser(t)
+	recipient := createUser(t)
+	event := createEvent(t, organizer, 0, 10)
+	database.DB.Model(&event).Update("transfer_enabled", true)
+	token := login(t, registrant)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/events/%d/register-group", event.ID), token,
+		map[string]interface{}{
+			"name": "Delegasi Test",
+			"attendees": []map[string]interface{}{
+				{"email": registrant.Email},
+				{"email": "tamu-" + uniqueSuffix() + "@example.test", "name": "Tamu Grup"},
+			},
+		})
+	requireStatus(t, "register grup", code, http.StatusCreated, body)
+	groupID := uint(body["data"].(map[string]interface{})["group"].(map[string]interface{})["id"].(float64))
+
+	var group models.RegistrationGroup
+	database.DB.First(&group, groupID)
+	if group.LeadRegistrationID == nil {
+		t.Fatalf("grup %d tanpa registrasi ketua", groupID)
+	}
+
+	code, body = doJSON(t, http.MethodPost, fmt.Sprintf("/api/registrations/%d/transfer", *group.LeadRegistrationID), token,
+		map[string]interface{}{"email": recipient.Email})
+	requireStatus(t, "transfer tiket ketua grup", code, http.StatusBadRequest, body)
+
+	var transfers int64
+	database.DB.Model(&models.RegistrationTransfer{}).Where("registration_id = ?", *group.LeadRegistrationID).Count(&transfers)
+	if transfers != 0 {
+		t.Fatalf("transfer tiket ketua grup tersimpan (%d), want 0", transfers)
+	}
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentSession mengambil user ID dan session ID yang diset oleh AuthMiddleware
+func currentSession(c *gin.Context) (uint, uint) {
+	userID, _ := c.Get("id")
+	sessionID, _ := c.Get("session_id")
+	uid, _ := userID.(uint)
+	sid, _ := sessionID.(uint)
+	return uid, sid
+}
+
+// RefreshToken menukar refresh token dengan access token baru (refresh token ikut dirotasi)
+func RefreshToken(c *gin.Context) {
+	var req structs.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	tokens, err := helpers.RefreshSession(req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "Gagal memperbarui token"
+		if err == helpers.ErrInvalidRefreshToken {
+			status = http.StatusUnauthorized
+			message = "Sesi tidak valid atau sudah berakhir, silakan login kembali"
+		}
+		c.JSON(status, structs.ErrorResponse{
+			Success: false,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Token diperbarui",
+		Data: gin.H{
+			"token":              tokens.AccessToken,
+			"token_expires_at":   tokens.AccessExpiresAt.Format(time.RFC3339),
+			"refresh_token":      tokens.RefreshToken,
+			"refresh_expires_at": tokens.RefreshExpiresAt.Format(time.RFC3339),
+		},
+	})
+}
+
+// Logout mencabut sesi yang sedang dipakai
+func Logout(c *gin.Context) {
+	userID, sessionID := currentSession(c)
+
+	helpers.RevokeSession(sessionID, userID, "logout")
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Logout berhasil",
+	})
+}
+
+// LogoutAll mencabut semua sesi user di semua perangkat (termasuk sesi saat ini)
+func LogoutAll(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	revoked := helpers.RevokeAllSessions(userID, 0, "logout_all")
+
+	CreateAuditLog(
+		userID,
+		"logout_all",
+		"session",
+		userID,
+		nil,
+		gin.H{"revoked": revoked},
+		"Logout dari semua perangkat",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Berhasil logout dari semua perangkat",
+		Data:    gin.H{"revoked": revoked},
+	})
+}
+
+// GetMySessions menampilkan sesi aktif milik user yang sedang login
+func GetMySessions(c *gin.Context) {
+	userID, sessionID := currentSession(c)
+
+	var sessions []models.Session
+	database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions)
+
+	type SessionResponse struct {
+		models.Session
+		Current bool `json:"current"`
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		response = append(response, SessionResponse{Session: s, Current: s.ID == sessionID})
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar sesi aktif",
+		Data:    response,
+	})
+}
+
+// RevokeMySession mencabut salah satu sesi milik user (misal perangkat yang hilang)
+func RevokeMySession(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "ID sesi tidak valid",
+		})
+		return
+	}
+
+	if !helpers.RevokeSession(uint(id), userID, "revoked") {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Sesi tidak ditemukan",
+		})
+		return
+	}
+
+	CreateAuditLog(
+		userID,
+		"revoke",
+		"session",
+		uint(id),
+		nil,
+		nil,
+		"Mencabut sesi login",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Sesi berhasil dicabut",
+	})
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
 	"santrikoding/backend-api/models"
 	"santrikoding/backend-api/structs"
 	"strconv"
@@ -10,13 +11,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// InitializeDefaultSettings menginisialisasi default settings jika belum ada
+// InitializeDefaultSettings menambahkan default settings yang belum ada (termasuk key baru)
 func InitializeDefaultSettings() {
-	var count int64
-	database.DB.Model(&models.SystemSetting{}).Count(&count)
+	var existingKeys []string
+	database.DB.Model(&models.SystemSetting{}).Pluck("`key`", &existingKeys)
 
-	if count == 0 {
-		for _, setting := range models.DefaultSettings {
+	existing := make(map[string]bool, len(existingKeys))
+	for _, key := range existingKeys {
+		existing[key] = true
+	}
+
+	for _, setting := range models.DefaultSettings {
+		if !existing[setting.Key] {
 			database.DB.Create(&setting)
 		}
 	}
@@ -24,24 +30,8 @@ func InitializeDefaultSettings() {
 
 // GetSystemSettings mengambil semua pengaturan sistem
 func GetSystemSettings(c *gin.Context) {
-	// Cek superadmin
-	userID, _ := c.Get("id")
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak. Hanya superadmin yang bisa mengakses.",
-		})
-		return
-	}
-
-	// Auto-initialize default settings jika kosong
-	var count int64
-	database.DB.Model(&models.SystemSetting{}).Count(&count)
-	if count == 0 {
-		InitializeDefaultSettings()
-	}
+	// Auto-initialize default settings yang belum ada
+	InitializeDefaultSettings()
 
 	category := c.Query("category")
 
@@ -77,7 +67,7 @@ func GetSystemSettings(c *gin.Context) {
 
 // UpdateSystemSetting mengupdate pengaturan sistem
 func UpdateSystemSetting(c *gin.Context) {
-	// Cek superadmin
+	// Ambil user ID dari token
 	userIDInterface, _ := c.Get("id")
 	var userID uint
 	switch v := userIDInterface.(type) {
@@ -89,16 +79,6 @@ func UpdateSystemSetting(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak. Hanya superadmin yang bisa mengakses.",
-		})
-		return
-	}
-
 	settingID := c.Param("id")
 
 	var setting models.SystemSetting
@@ -158,7 +138,7 @@ func UpdateSystemSetting(c *gin.Context) {
 
 // BulkUpdateSystemSettings update banyak pengaturan sekaligus
 func BulkUpdateSystemSettings(c *gin.Context) {
-	// Cek superadmin
+	// Ambil user ID dari token
 	userIDInterface, _ := c.Get("id")
 	var userID uint
 	switch v := userIDInterface.(type) {
@@ -170,16 +150,6 @@ func BulkUpdateSystemSettings(c *gin.Context) {
 		userID = uint(v)
 	}
 
-	var user models.User
-	database.DB.First(&user, userID)
-	if user.RoleID != 1 {
-		c.JSON(http.StatusForbidden, structs.ErrorResponse{
-			Success: false,
-			Message: "Akses ditolak",
-		})
-		return
-	}
-
 	var req struct {
 		Settings []struct {
 			Key   string `json:"key"`
@@ -256,11 +226,7 @@ func GetPublicSettings(c *gin.Context) {
 
 // GetSettingByKey mengambil satu setting berdasarkan key
 func GetSettingByKey(key string) string {
-	var setting models.SystemSetting
-	if err := database.DB.Where("`key` = ?", key).First(&setting).Error; err != nil {
-		return ""
-	}
-	return setting.Value
+	return helpers.GetSetting(key)
 }
 
 // GetSettingByKeyBool mengambil setting boolean
@@ -280,16 +280,8 @@ func UpdateTask(c *gin.Context) {
 	// Cek apakah user adalah yang membuat tugas (CreatedByID)
 	isCreator := task.CreatedByID == userID
 
-	// Cek apakah user adalah panitia dari event ini
-	var committeeMember models.CommitteeMember
-	isCommittee := database.DB.Where("event_id = ? AND user_id = ?", task.EventID, userID).First(&committeeMember).Error == nil
-
-	// Cek apakah user adalah Super Admin
-	var user models.User
-	isSuperAdmin := false
-	if err := database.DB.Preload("Role").First(&user, userID).Error; err == nil {
-		isSuperAdmin = user.Role.Code == "superadmin" || user.Role.Code == "kahim"
-	}
+	// Cek permission task.manage (role global atau panitia event ini)
+	canManage := helpers.HasEventPermission(userID, task.EventID, "task.manage")
 
 	// Cek apakah user adalah yang diberi tugas (AssignedToID)
 	isAssignee := task.AssignedToID != nil && *task.AssignedToID == userID
@@ -297,7 +289,7 @@ func UpdateTask(c *gin.Context) {
 	// Jika request mengubah status, cek permission khusus
 	if req.Status != nil {
 		// Yang diberi tugas (assignee) TIDAK BISA mengubah status
-		if isAssignee && !isCreator && !isCommittee && !isSuperAdmin {
+		if isAssignee && !isCreator && !canManage {
 			c.JSON(http.StatusForbidden, structs.ErrorResponse{
 				Success: false,
 				Message: "Anda tidak memiliki izin untuk mengubah status tugas ini. Hanya yang memberi tugas atau panitia yang dapat mengubah status. Anda hanya bisa mengerjakan dan mengirim bukti pengerjaan.",
@@ -307,7 +299,7 @@ func UpdateTask(c *gin.Context) {
 		}
 
 		// Yang bisa ubah status: creator, panitia, atau super admin
-		if !isCreator && !isCommittee && !isSuperAdmin {
+		if !isCreator && !canManage {
 			c.JSON(http.StatusForbidden, structs.ErrorResponse{
 				Success: false,
 				Message: "Anda tidak memiliki izin untuk mengubah status tugas ini. Hanya yang memberi tugas atau panitia yang dapat mengubah status.",
@@ -320,7 +312,7 @@ func UpdateTask(c *gin.Context) {
 	// Untuk field lain (title, description, priority, assigned_to_id, due_date):
 	// Hanya creator, panitia, atau super admin yang bisa edit
 	if req.Title != nil || req.Description != nil || req.Priority != nil || req.AssignedToID != nil || req.DueDate != nil {
-		if !isCreator && !isCommittee && !isSuperAdmin {
+		if !isCreator && !canManage {
 			c.JSON(http.StatusForbidden, structs.ErrorResponse{
 				Success: false,
 				Message: "Anda tidak memiliki izin untuk mengedit tugas ini. Hanya yang memberi tugas atau panitia yang dapat mengedit.",
@@ -660,9 +652,9 @@ func UpdateComments(c *gin.Context) {
 		return
 	}
 
-	// Check permission: assigned user atau admin (superadmin/kahim)
+	// Check permission: assigned user atau role dengan permission task.manage
 	isAssignedUser := task.AssignedToID != nil && *task.AssignedToID == userID
-	isAdmin := user.Role.Code == "superadmin" || user.Role.Code == "kahim"
+	isAdmin := helpers.HasPermission(userID, "task.manage")
 
 	if !isAssignedUser && !isAdmin {
 		c.JSON(http.StatusForbidden, structs.ErrorResponse{
@@ -720,14 +712,25 @@ func UpdateComments(c *gin.Context) {
 	})
 }
 
-// Helper function untuk check permission edit task
-func canEditTask(userID uint, task models.Task) bool {
-	// Get user dengan role
-	var user models.User
-	if err := database.DB.Preload("Role").First(&user, userID).Error; err != nil {
-		return false
+// SendTaskDeadlineReminders mengirim notifikasi ke penanggung jawab tugas yang deadline-nya kurang dari 24 jam
+// Dijalankan oleh scheduler (lihat main.go)
+func SendTaskDeadlineReminders() error {
+	now := time.Now()
+
+	var tasks []models.Task
+	if err := database.DB.Preload("Event").
+		Where("status != ? AND assigned_to_id IS NOT NULL AND due_date IS NOT NULL AND due_date BETWEEN ? AND ? AND reminder_sent_at IS NULL",
+			"done", now, now.Add(24*time.Hour)).
+		Find(&tasks).Error; err != nil {
+		return err
 	}
 
-	// Hanya Super Admin atau Ketua Himpunan yang bisa edit
-	return user.Role.Code == "superadmin" || user.Role.Code == "kahim"
+	for _, task := range tasks {
+		helpers.NotifyTaskDeadline(*task.AssignedToID, task.Title, task.DueDate.Format("02 Jan 2006 15:04"), task.Event.Slug)
+
+		if err := database.DB.Model(&task).Update("reminder_sent_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
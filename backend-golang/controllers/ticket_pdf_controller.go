@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ticketPrintableStatuses adalah status registrasi yang e-tiket/badge-nya boleh dicetak
+var ticketPrintableStatuses = []string{"confirmed", "checked_in"}
+
+// GET /api/registrations/:id/ticket
+// E-tiket PDF (info event, nama peserta, QR check-in) untuk pemilik tiket, pembuat event, atau panitia
+func DownloadTicket(c *gin.Context) {
+	var registration models.Registration
+	if err := database.DB.Preload("Event").Preload("User").Preload("TicketType").
+		First(&registration, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran tidak ditemukan",
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	if registration.UserID != userID {
+		if _, ok := loadManagedEvent(c, registration.EventID, "Anda tidak memiliki akses ke tiket ini"); !ok {
+			return
+		}
+	}
+
+	if !isTicketPrintable(registration.Status) {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "E-tiket belum tersedia. Tiket bisa diunduh setelah pendaftaran dikonfirmasi.",
+		})
+		return
+	}
+
+	writeTicketPDF(c, []models.Registration{registration}, fmt.Sprintf("tiket-%d.pdf", registration.ID))
+}
+
+// GET /api/registration-groups/:id/tickets
+// Seluruh e-tiket anggota grup dalam satu PDF (satu halaman per peserta) untuk pendaftar grup
+func DownloadGroupTickets(c *gin.Context) {
+	var group models.RegistrationGroup
+	if err := database.DB.First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran grup tidak ditemukan",
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	if group.RegistrantID != userID {
+		if _, ok := loadManagedEvent(c, group.EventID, "Anda tidak memiliki akses ke pendaftaran grup ini"); !ok {
+			return
+		}
+	}
+
+	var registrations []models.Registration
+	database.DB.Preload("Event").Preload("User").Preload("TicketType").
+		Where("group_id = ? AND status IN ?", group.ID, ticketPrintableStatuses).
+		Order("id ASC").Find(&registrations)
+	if len(registrations) == 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "E-tiket belum tersedia. Tiket bisa diunduh setelah pembayaran grup dikonfirmasi.",
+		})
+		return
+	}
+
+	writeTicketPDF(c, registrations, fmt.Sprintf("tiket-grup-%d.pdf", group.ID))
+}
+
+// GET /api/events/:id/badges?type=all|participants|committee
+// Lembar badge nama A4 untuk dicetak panitia: peserta terkonfirmasi dan/atau panitia event
+func DownloadEventBadges(c *gin.Context) {
+	event, ok := loadManagedEvent(c, c.Param("id"), "Hanya pembuat event atau panitia yang dapat mencetak badge")
+	if !ok {
+		return
+	}
+
+	badgeType := c.DefaultQuery("type", "all")
+	if badgeType != "all" && badgeType != "participants" && badgeType != "committee" {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "type harus 'all', 'participants', atau 'committee'",
+		})
+		return
+	}
+
+	var badges []helpers.BadgeInfo
+	if badgeType != "participants" {
+		var committees []models.CommitteeMember
+		database.DB.Preload("User.University").Preload("User.StudyProgram").
+			Where("event_id = ?", event.ID).Order("division ASC, id ASC").Find(&committees)
+		for _, member := range committees {
+			badges = append(badges, helpers.BadgeInfo{
+				Name:         member.User.Name,
+				Organization: helpers.UserOrganization(member.User),
+				Role:         helpers.BadgeRoleCommittee,
+				Division:     member.Division,
+			})
+		}
+	}
+	if badgeType != "committee" {
+		var registrations []models.Registration
+		database.DB.Preload("User.University").Preload("User.StudyProgram").
+			Where("event_id = ? AND status IN ?", event.ID, ticketPrintableStatuses).
+			Order("id ASC").Find(&registrations)
+		for _, reg := range registrations {
+			badge := helpers.BadgeInfo{
+				Name:   reg.AttendeeName(),
+				Role:   helpers.BadgeRoleParticipant,
+				QRCode: reg.QRCode,
+			}
+			// Tamu grup tidak punya akun, jadi data instansinya tidak diketahui
+			if !reg.IsGuest() {
+				badge.Organization = helpers.UserOrganization(reg.User)
+			}
+			badges = append(badges, badge)
+		}
+	}
+
+	if len(badges) == 0 {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Belum ada peserta atau panitia yang bisa dicetak badge-nya",
+		})
+		return
+	}
+
+	pdf, err := helpers.RenderBadgeSheetPDF(*event, badges)
+	if err != nil {
+		log.Printf("Error rendering badges for event %d: %v", event.ID, err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat file badge",
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	CreateActivity(userID, "badges_printed", "event", event.ID,
+		fmt.Sprintf("Mencetak %d badge untuk event %s", len(badges), event.Title))
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=badge-%s.pdf", event.Slug))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func isTicketPrintable(status string) bool {
+	for _, s := range ticketPrintableStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTicketPDF merender e-tiket lalu mengirimkannya sebagai file unduhan
+func writeTicketPDF(c *gin.Context, registrations []models.Registration, filename string) {
+	pdf, err := helpers.RenderTicketPDF(registrations)
+	if err != nil {
+		log.Printf("Error rendering ticket %s: %v", filename, err)
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat file e-tiket",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
@@ -0,0 +1,64 @@
+package controllers_test
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"testing"
+	"time"
+)
+
+// createTicket membuat registrasi confirmed dengan QR yang ditandatangani dengan masa berlaku expiresAt
+func createTicket(t *testing.T, event models.Event, participant models.User, expiresAt time.Time) string {
+	t.Helper()
+	registration := models.Registration{
+		EventID: event.ID,
+		UserID:  participant.ID,
+		Status:  "confirmed",
+		QRCode:  helpers.TemporaryTicketCode(),
+		PaidAt:  time.Now(),
+	}
+	if err := database.DB.Create(&registration).Error; err != nil {
+		t.Fatalf("create registration: %v", err)
+	}
+	code, err := helpers.SignTicketQR(event.ID, registration.ID, expiresAt)
+	if err != nil {
+		t.Fatalf("sign QR: %v", err)
+	}
+	database.DB.Model(&registration).Update("qr_code", code)
+	return code
+}
+
+// Jadwal event yang diundur setelah QR terbit: QR tetap berlaku sampai akhir event yang baru
+func TestTicketQRFollowsRescheduledEvent(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	event := createEvent(t, organizer, 0, 10)
+	code := createTicket(t, event, createUser(t), time.Now().Add(-time.Hour))
+
+	status, body := doJSON(t, http.MethodPost, "/api/scan/check-in", login(t, organizer),
+		map[string]interface{}{"qr_code": code})
+	requireStatus(t, "scan QR setelah event diundur", status, http.StatusOK, body)
+}
+
+// Jadwal event yang dimajukan: QR kadaluarsa mengikuti akhir event yang baru walau QR mencatat waktu lebih lama
+func TestTicketQRExpiresWithEarlierSchedule(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	event := createEvent(t, organizer, 0, 10)
+	code := createTicket(t, event, createUser(t), time.Now().Add(30*24*time.Hour))
+	database.DB.Model(&event).Updates(map[string]interface{}{
+		"start_date": time.Now().Add(-10 * 24 * time.Hour),
+		"end_date":   time.Now().Add(-9 * 24 * time.Hour),
+	})
+
+	status, body := doJSON(t, http.MethodPost, "/api/scan/check-in", login(t, organizer),
+		map[string]interface{}{"qr_code": code})
+	requireStatus(t, "scan QR setelah event dimajukan", status, http.StatusBadRequest, body)
+	if body["message"] != "QR Code sudah kadaluarsa" {
+		t.Fatalf("scan QR setelah event dimajukan: pesan %v, want QR Code sudah kadaluarsa", body["message"])
+	}
+}
@@ -0,0 +1,323 @@
+package controllers
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketTypeRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+	Price       int64  `json:"price" binding:"min=0"`
+	Quota       int    `json:"quota" binding:"min=0"` // 0 = mengikuti kuota event
+	SalesStart  string `json:"sales_start"`           // "2006-01-02 15:04" atau "2006-01-02", kosong = langsung dijual
+	SalesEnd    string `json:"sales_end"`             // "2006-01-02 15:04" atau "2006-01-02" (sampai 23:59)
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public hidden"`
+	AccessCode  string `json:"access_code" binding:"max=50"` // Untuk tiket hidden, kosong = kode lama atau dibuat otomatis
+	SortOrder   int    `json:"sort_order"`
+}
+
+// TicketTypeSummary adalah tipe tiket beserta jumlah terjual dan sisa kuotanya
+type TicketTypeSummary struct {
+	models.TicketType
+	Sold      int64 `json:"sold"`
+	Available int   `json:"available"` // -1 berarti unlimited (mengikuti kuota event)
+	OnSale    bool  `json:"on_sale"`
+}
+
+// GET /api/ticket-types/event/:event_id
+// Semua tipe tiket event (termasuk yang hidden) untuk pembuat event dan panitia
+func GetTicketTypes(c *gin.Context) {
+	event, ok := loadEventForTicketManagement(c, c.Param("event_id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar tipe tiket",
+		Data:    summarizeTicketTypes(event.ID, false),
+	})
+}
+
+// POST /api/ticket-types/event/:event_id
+func CreateTicketType(c *gin.Context) {
+	event, ok := loadEventForTicketManagement(c, c.Param("event_id"))
+	if !ok {
+		return
+	}
+
+	var req TicketTypeRequest
+	if !bindTicketTypeRequest(c, &req) {
+		return
+	}
+
+	ticketType := models.TicketType{EventID: event.ID}
+	if !applyTicketTypeRequest(c, &ticketType, req) {
+		return
+	}
+	if err := database.DB.Create(&ticketType).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat tipe tiket",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	CreateAuditLog(userID, "create", "ticket_type", ticketType.ID, nil, ticketType,
+		"Membuat tipe tiket "+ticketType.Name+" untuk event "+event.Title, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Tipe tiket berhasil dibuat",
+		Data:    ticketType,
+	})
+}
+
+// PUT /api/ticket-types/:id
+func UpdateTicketType(c *gin.Context) {
+	var ticketType models.TicketType
+	if err := database.DB.First(&ticketType, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Tipe tiket tidak ditemukan",
+		})
+		return
+	}
+	event, ok := loadEventForTicketManagement(c, ticketType.EventID)
+	if !ok {
+		return
+	}
+
+	var req TicketTypeRequest
+	if !bindTicketTypeRequest(c, &req) {
+		return
+	}
+
+	oldTicketType := ticketType
+	if !applyTicketTypeRequest(c, &ticketType, req) {
+		return
+	}
+
+	// Kuota tidak boleh lebih kecil dari tiket yang sudah terjual
+	sold := helpers.CountTicketsSold(event.ID)[ticketType.ID]
+	if ticketType.Quota > 0 && int64(ticketType.Quota) < sold {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Kuota tidak boleh lebih kecil dari jumlah tiket yang sudah terjual",
+			Errors:  map[string]string{"quota": "Minimal sama dengan jumlah terjual"},
+		})
+		return
+	}
+
+	if err := database.DB.Save(&ticketType).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal memperbarui tipe tiket",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	CreateAuditLog(userID, "update", "ticket_type", ticketType.ID, oldTicketType, ticketType,
+		"Memperbarui tipe tiket "+ticketType.Name+" untuk event "+event.Title, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Tipe tiket berhasil diperbarui",
+		Data:    ticketType,
+	})
+}
+
+// DELETE /api/ticket-types/:id
+// Tipe tiket yang sudah dipakai pendaftar tidak bisa dihapus (ubah visibility atau periode penjualannya)
+func DeleteTicketType(c *gin.Context) {
+	var ticketType models.TicketType
+	if err := database.DB.First(&ticketType, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Tipe tiket tidak ditemukan",
+		})
+		return
+	}
+	event, ok := loadEventForTicketManagement(c, ticketType.EventID)
+	if !ok {
+		return
+	}
+
+	var used int64
+	database.DB.Model(&models.Registration{}).Where("ticket_type_id = ?", ticketType.ID).Count(&used)
+	if used > 0 {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Tipe tiket sudah dipakai pendaftar dan tidak bisa dihapus. Tutup periode penjualannya atau ubah menjadi hidden.",
+		})
+		return
+	}
+
+	if err := database.DB.Delete(&ticketType).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menghapus tipe tiket",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	CreateAuditLog(userID, "delete", "ticket_type", ticketType.ID, ticketType, nil,
+		"Menghapus tipe tiket "+ticketType.Name+" dari event "+event.Title, c.ClientIP(), c.Request.UserAgent())
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Tipe tiket berhasil dihapus",
+	})
+}
+
+// summarizeTicketTypes menyusun tipe tiket event beserta jumlah terjual, sisa kuota dan status penjualannya
+func summarizeTicketTypes(eventID uint, publicOnly bool) []TicketTypeSummary {
+	ticketTypes := helpers.GetEventTicketTypes(eventID, publicOnly)
+	sold := helpers.CountTicketsSold(eventID)
+	now := time.Now()
+
+	summaries := make([]TicketTypeSummary, 0, len(ticketTypes))
+	for _, t := range ticketTypes {
+		available := -1
+		if t.Quota > 0 {
+			available = t.Quota - int(sold[t.ID])
+			if available < 0 {
+				available = 0
+			}
+		}
+		summaries = append(summaries, TicketTypeSummary{
+			TicketType: t,
+			Sold:       sold[t.ID],
+			Available:  available,
+			OnSale:     t.IsOnSale(now) && available != 0,
+		})
+	}
+	return summaries
+}
+
+// loadEventForTicketManagement mengambil event yang tipe tiketnya boleh dikelola user
+func loadEventForTicketManagement(c *gin.Context, eventID interface{}) (*models.Event, bool) {
+	return loadManagedEvent(c, eventID, "Hanya pembuat event atau panitia yang dapat mengelola tipe tiket")
+}
+
+// loadManagedEvent mengambil event dan memastikan user adalah pembuat event atau panitianya
+// (aturan yang sama dengan edit event). Response error sudah ditulis jika mengembalikan false.
+func loadManagedEvent(c *gin.Context, eventID interface{}, forbiddenMessage string) (*models.Event, bool) {
+	var event models.Event
+	if err := database.DB.First(&event, eventID).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Event tidak ditemukan",
+		})
+		return nil, false
+	}
+
+	userID, _ := currentSession(c)
+	if event.CreatedByID != userID {
+		var count int64
+		database.DB.Model(&models.CommitteeMember{}).Where("event_id = ? AND user_id = ?", event.ID, userID).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusForbidden, structs.ErrorResponse{
+				Success: false,
+				Message: forbiddenMessage,
+			})
+			return nil, false
+		}
+	}
+	return &event, true
+}
+
+func bindTicketTypeRequest(c *gin.Context, req *TicketTypeRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return false
+	}
+	return true
+}
+
+// applyTicketTypeRequest mengisi tipe tiket dari request dan memvalidasi periode penjualan
+func applyTicketTypeRequest(c *gin.Context, ticketType *models.TicketType, req TicketTypeRequest) bool {
+	fieldErrors := map[string]string{}
+
+	var salesStart, salesEnd *time.Time
+	if req.SalesStart != "" {
+		parsed, err := time.Parse("2006-01-02 15:04", req.SalesStart)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", req.SalesStart)
+		}
+		if err != nil {
+			fieldErrors["sales_start"] = "Format harus YYYY-MM-DD HH:mm atau YYYY-MM-DD"
+		} else {
+			salesStart = &parsed
+		}
+	}
+	if req.SalesEnd != "" {
+		if parsed, ok := parseDeadlineInput(req.SalesEnd); ok {
+			salesEnd = parsed
+		} else {
+			fieldErrors["sales_end"] = "Format harus YYYY-MM-DD HH:mm atau YYYY-MM-DD"
+		}
+	}
+	if salesStart != nil && salesEnd != nil && !salesEnd.After(*salesStart) {
+		fieldErrors["sales_end"] = "Harus setelah waktu mulai penjualan"
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Periode penjualan tidak valid",
+			Errors:  fieldErrors,
+		})
+		return false
+	}
+
+	ticketType.Name = req.Name
+	ticketType.Description = req.Description
+	ticketType.Price = req.Price
+	ticketType.Quota = req.Quota
+	ticketType.SalesStart = salesStart
+	ticketType.SalesEnd = salesEnd
+	ticketType.Visibility = req.Visibility
+	if ticketType.Visibility == "" {
+		ticketType.Visibility = models.TicketVisibilityPublic
+	}
+	// Tiket hidden selalu punya kode akses (kode lama dipertahankan jika tidak diganti),
+	// tiket public tidak memerlukannya
+	if ticketType.Visibility != models.TicketVisibilityHidden {
+		ticketType.AccessCode = ""
+	} else {
+		if code := helpers.NormalizeTicketAccessCode(req.AccessCode); code != "" {
+			ticketType.AccessCode = code
+		}
+		if ticketType.AccessCode == "" {
+			code, err := helpers.GenerateTicketAccessCode()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+					Success: false,
+					Message: "Gagal membuat kode akses tiket",
+					Errors:  map[string]string{"error": err.Error()},
+				})
+				return false
+			}
+			ticketType.AccessCode = code
+		}
+	}
+	ticketType.SortOrder = req.SortOrder
+	return true
+}
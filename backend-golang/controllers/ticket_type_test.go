@@ -0,0 +1,47 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strings"
+	"testing"
+)
+
+// Tiket hidden hanya bisa dipilih dengan kode aksesnya, walaupun ID tiketnya diketahui
+func TestHiddenTicketTypeRequiresAccessCode(t *testing.T) {
+	requireDB(t)
+
+	organizer := createUser(t)
+	event := createEvent(t, organizer, 0, 10)
+
+	code, body := doJSON(t, http.MethodPost, fmt.Sprintf("/api/ticket-types/event/%d", event.ID), login(t, organizer),
+		map[string]interface{}{"name": "Internal", "visibility": "hidden"})
+	requireStatus(t, "buat tiket hidden", code, http.StatusCreated, body)
+	data := body["data"].(map[string]interface{})
+	ticketTypeID := uint(data["id"].(float64))
+	accessCode, _ := data["access_code"].(string)
+	if accessCode == "" {
+		t.Fatalf("tiket hidden dibuat tanpa kode akses")
+	}
+
+	register := func(participant models.User, accessCode string) (int, map[string]interface{}) {
+		return doJSON(t, http.MethodPost, fmt.Sprintf("/api/participant/event/%d/register", event.ID), login(t, participant),
+			map[string]interface{}{"ticket_type_id": ticketTypeID, "access_code": accessCode, "answers": []interface{}{}})
+	}
+
+	code, body = register(createUser(t), "")
+	requireStatus(t, "daftar tanpa kode akses", code, http.StatusBadRequest, body)
+	code, body = register(createUser(t), "SALAH")
+	requireStatus(t, "daftar dengan kode salah", code, http.StatusBadRequest, body)
+
+	var count int64
+	database.DB.Model(&models.Registration{}).Where("event_id = ?", event.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("registrasi tiket hidden tanpa kode tersimpan (%d), want 0", count)
+	}
+
+	code, body = register(createUser(t), " "+strings.ToLower(accessCode)+" ")
+	requireStatus(t, "daftar dengan kode akses", code, http.StatusCreated, body)
+}
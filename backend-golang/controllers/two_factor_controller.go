@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// GetTwoFactorStatus menampilkan status 2FA user yang login
+func GetTwoFactorStatus(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	data := gin.H{
+		"enabled":                  false,
+		"pending_enrollment":       false,
+		"required":                 helpers.IsTwoFactorRequired(userID),
+		"recovery_codes_remaining": int64(0),
+	}
+	if tf, ok := helpers.GetTwoFactor(userID); ok {
+		data["enabled"] = tf.EnabledAt != nil
+		data["pending_enrollment"] = tf.EnabledAt == nil
+		data["enabled_at"] = tf.EnabledAt
+		data["recovery_codes_remaining"] = helpers.CountRecoveryCodes(userID)
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Status autentikasi dua faktor",
+		Data:    data,
+	})
+}
+
+// EnrollTwoFactor membuat secret TOTP baru dan mengembalikan URI otpauth untuk di-scan.
+// 2FA belum aktif sampai kode pertama dikonfirmasi lewat ConfirmTwoFactor.
+func EnrollTwoFactor(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "User tidak ditemukan",
+		})
+		return
+	}
+
+	if helpers.IsTwoFactorEnabled(userID) {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Autentikasi dua faktor sudah aktif",
+		})
+		return
+	}
+
+	secret, err := helpers.StartTwoFactorEnrollment(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat secret 2FA",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	issuer := helpers.GetSetting("app_name")
+	if issuer == "" {
+		issuer = "Event Management System"
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Scan QR code dengan aplikasi authenticator, lalu konfirmasi dengan kode 6 digit",
+		Data: gin.H{
+			"secret":      secret,
+			"otpauth_uri": helpers.TOTPProvisioningURI(issuer, user.Email, secret),
+		},
+	})
+}
+
+// ConfirmTwoFactor mengaktifkan 2FA dengan kode pertama dari authenticator
+// dan mengembalikan recovery code (hanya ditampilkan sekali)
+func ConfirmTwoFactor(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var req structs.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	codes, err := helpers.ConfirmTwoFactorEnrollment(userID, req.Code)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err != helpers.ErrInvalidTwoFactorCode && err != helpers.ErrTwoFactorNotEnabled {
+			status = http.StatusConflict
+		}
+		c.JSON(status, structs.ErrorResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	CreateAuditLog(
+		userID,
+		"2fa_enabled",
+		"user",
+		userID,
+		nil,
+		nil,
+		"Mengaktifkan autentikasi dua faktor",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Autentikasi dua faktor berhasil diaktifkan. Simpan recovery code di tempat aman.",
+		Data: gin.H{
+			"recovery_codes": codes,
+			"permissions":    helpers.GetUserPermissions(userID),
+		},
+	})
+}
+
+// RegenerateRecoveryCodes mengganti semua recovery code (butuh kode TOTP yang valid)
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var req structs.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	if err := helpers.VerifyTwoFactorCode(userID, req.Code); err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	codes, err := helpers.RegenerateRecoveryCodes(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal membuat recovery code",
+		})
+		return
+	}
+
+	CreateAuditLog(
+		userID,
+		"2fa_recovery_regenerated",
+		"user",
+		userID,
+		nil,
+		nil,
+		"Membuat ulang recovery code 2FA",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Recovery code baru berhasil dibuat. Recovery code lama tidak berlaku lagi.",
+		Data:    gin.H{"recovery_codes": codes},
+	})
+}
+
+// DisableTwoFactor menonaktifkan 2FA (butuh password dan kode TOTP / recovery code).
+// Ditolak jika role user mewajibkan 2FA.
+func DisableTwoFactor(c *gin.Context) {
+	userID, _ := currentSession(c)
+
+	var req structs.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Errors",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	if helpers.IsTwoFactorRequired(userID) {
+		c.JSON(http.StatusForbidden, structs.ErrorResponse{
+			Success: false,
+			Message: "Role Anda wajib memakai autentikasi dua faktor",
+		})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "User tidak ditemukan",
+		})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Password salah",
+		})
+		return
+	}
+
+	// Terima kode TOTP atau recovery code (jika perangkat authenticator hilang)
+	if err := helpers.VerifyTwoFactorCode(userID, req.Code); err != nil {
+		if recErr := helpers.UseRecoveryCode(userID, req.Code); recErr != nil {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := helpers.DisableTwoFactor(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal menonaktifkan autentikasi dua faktor",
+		})
+		return
+	}
+
+	CreateAuditLog(
+		userID,
+		"2fa_disabled",
+		"user",
+		userID,
+		nil,
+		nil,
+		"Menonaktifkan autentikasi dua faktor",
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Autentikasi dua faktor dinonaktifkan",
+	})
+}
+
+// ResetUserTwoFactor menghapus 2FA user lain yang kehilangan authenticator dan recovery code
+// (permission users.unlock). Semua sesi user tersebut ikut dicabut.
+func ResetUserTwoFactor(c *gin.Context) {
+	adminID, _ := currentSession(c)
+
+	var user models.User
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "User not found",
+		})
+		return
+	}
+
+	wasEnabled := helpers.IsTwoFactorEnabled(user.ID)
+	if err := helpers.DisableTwoFactor(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mereset autentikasi dua faktor",
+		})
+		return
+	}
+	helpers.RevokeAllSessions(user.ID, 0, "2fa_reset")
+
+	CreateAuditLog(
+		adminID,
+		"2fa_reset",
+		"user",
+		user.ID,
+		gin.H{"enabled": wasEnabled},
+		gin.H{"enabled": false},
+		"Mereset autentikasi dua faktor user "+user.Username,
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Autentikasi dua faktor user berhasil direset",
+		Data:    gin.H{"user_id": user.ID, "was_enabled": wasEnabled},
+	})
+}
@@ -517,8 +517,53 @@ func ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// Cabut sesi di perangkat lain, sesi saat ini tetap aktif
+	_, sessionID := currentSession(c)
+	helpers.RevokeAllSessions(user.ID, sessionID, "password_changed")
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Password berhasil diganti",
 	})
 }
+
+// UnlockUser membuka lockout login sebuah akun (permission users.unlock)
+func UnlockUser(c *gin.Context) {
+	adminIDInterface, _ := c.Get("id")
+	adminID, _ := adminIDInterface.(uint)
+
+	var user models.User
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "User not found",
+		})
+		return
+	}
+
+	key := helpers.AccountThrottleKey(user.ID, "")
+	lockedUntil, wasLocked := helpers.GetLoginLock(helpers.ThrottleScopeAccount, key)
+	helpers.ClearLoginFailures(helpers.ThrottleScopeAccount, key)
+
+	var oldValue interface{}
+	if wasLocked {
+		oldValue = gin.H{"locked_until": lockedUntil}
+	}
+	CreateAuditLog(
+		adminID,
+		"unlock",
+		"user",
+		user.ID,
+		oldValue,
+		nil,
+		"Membuka lockout login user "+user.Username,
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Lockout login user berhasil dibuka",
+		Data:    gin.H{"user_id": user.ID, "was_locked": wasLocked},
+	})
+}
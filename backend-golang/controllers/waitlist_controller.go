@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"net/http"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/models"
+	"santrikoding/backend-api/structs"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /api/events/:id/waitlist
+// Masuk daftar tunggu event yang kuotanya penuh (hanya jika waitlist diaktifkan panitia)
+func JoinWaitlist(c *gin.Context) {
+	var event models.Event
+	if err := database.DB.First(&event, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Event tidak ditemukan",
+		})
+		return
+	}
+
+	if event.Status != "published" {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Event belum dibuka untuk pendaftaran",
+		})
+		return
+	}
+	if event.RegistrationDeadline != nil && time.Now().After(*event.RegistrationDeadline) {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Pendaftaran sudah ditutup",
+		})
+		return
+	}
+	if !event.WaitlistEnabled || event.Quota <= 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Event ini tidak menyediakan daftar tunggu",
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+
+	var existingReg int64
+	database.DB.Model(&models.Registration{}).
+		Where("event_id = ? AND user_id = ? AND guest_email = ? AND status NOT IN ?", event.ID, userID, "", models.InactiveRegistrationStatuses).
+		Count(&existingReg)
+	if existingReg > 0 {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda sudah terdaftar di event ini",
+		})
+		return
+	}
+
+	if _, ok := helpers.GetActiveWaitlistEntry(event.ID, userID); ok {
+		c.JSON(http.StatusConflict, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda sudah berada di daftar tunggu event ini",
+		})
+		return
+	}
+
+	// Daftar tunggu hanya dibuka saat kursi penuh atau masih ada antrian di depan
+	if int(helpers.SeatsTaken(database.DB, event.ID, 0)) < event.Quota && helpers.CountWaitlistWaiting(event.ID) == 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Kuota event masih tersedia. Silakan langsung mendaftar.",
+		})
+		return
+	}
+
+	entry := models.WaitlistEntry{
+		EventID: event.ID,
+		UserID:  userID,
+		Status:  models.WaitlistWaiting,
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal masuk daftar tunggu",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	CreateActivity(userID, "waitlist_joined", "event", event.ID, "Masuk daftar tunggu event "+event.Title)
+
+	// Jika ada kursi yang kosong sejak pengecekan di atas, langsung tawarkan
+	go helpers.PromoteWaitlist(event.ID)
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Berhasil masuk daftar tunggu. Kami akan mengabari Anda jika ada kursi kosong.",
+		Data:    waitlistEntryResponse(&entry),
+	})
+}
+
+// DELETE /api/events/:id/waitlist
+// Keluar dari daftar tunggu; tawaran kursi yang belum diklaim diteruskan ke antrian berikutnya
+func LeaveWaitlist(c *gin.Context) {
+	var event models.Event
+	if err := database.DB.First(&event, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Event tidak ditemukan",
+		})
+		return
+	}
+
+	userID, _ := currentSession(c)
+	entry, ok := helpers.GetActiveWaitlistEntry(event.ID, userID)
+	if !ok {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Anda tidak berada di daftar tunggu event ini",
+		})
+		return
+	}
+
+	wasOffered := entry.Status == models.WaitlistOffered
+	if err := database.DB.Model(entry).Update("status", models.WaitlistCancelled).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal keluar dari daftar tunggu",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	CreateActivity(userID, "waitlist_left", "event", event.ID, "Keluar dari daftar tunggu event "+event.Title)
+
+	if wasOffered {
+		go helpers.PromoteWaitlist(event.ID)
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Anda telah keluar dari daftar tunggu",
+	})
+}
+
+// GET /api/events/:id/waitlist
+// Daftar tunggu event untuk pembuat event dan panitia
+func GetEventWaitlist(c *gin.Context) {
+	event, ok := loadManagedEvent(c, c.Param("id"), "Hanya pembuat event atau panitia yang dapat melihat daftar tunggu")
+	if !ok {
+		return
+	}
+
+	query := database.DB.Preload("User").Where("event_id = ?", event.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var entries []models.WaitlistEntry
+	if err := query.Order("id ASC").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Gagal mengambil daftar tunggu",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daftar tunggu event",
+		Data: gin.H{
+			"waitlist_enabled": event.WaitlistEnabled,
+			"waiting_count":    helpers.CountWaitlistWaiting(event.ID),
+			"entries":          entries,
+		},
+	})
+}
+
+// waitlistEntryResponse menyusun status antrian user beserta nomor antriannya (jika masih menunggu)
+func waitlistEntryResponse(entry *models.WaitlistEntry) gin.H {
+	response := gin.H{
+		"id":               entry.ID,
+		"status":           entry.Status,
+		"offered_at":       entry.OfferedAt,
+		"offer_expires_at": entry.OfferExpiresAt,
+		"created_at":       entry.CreatedAt,
+	}
+	if entry.Status == models.WaitlistWaiting {
+		response["position"] = helpers.WaitlistPosition(entry)
+	}
+	return response
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"os" // ✅ Kita pakai library bawaan biar aman di Cloud
-	"santrikoding/backend-api/models"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -21,7 +20,8 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
-func InitDB() {
+// Connect membuka koneksi ke database tanpa menjalankan migrasi
+func Connect() {
 
 	// ✅ Pakai fungsi helper kita sendiri (bukan dari package config)
 	dbUser := getEnv("DB_USER", "root")
@@ -45,144 +45,28 @@ func InitDB() {
 	}
 
 	fmt.Println("Database connected successfully!")
+}
 
-	// **Auto Migrate Models**
-	// (Bagian ini saya biarkan sama persis karena sudah benar)
-	err = DB.AutoMigrate(&models.User{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate User: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Role{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Role: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Event{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Event: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.CommitteeMember{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate CommitteeMember: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.FormField{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate FormField: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.FormAnswer{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate FormAnswer: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Registration{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Registration: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Certificate{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Certificate: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Budget{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Budget: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Task{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Task: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Inventory{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Inventory: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Loan{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Loan: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.LoanItem{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate LoanItem: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Activity{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Activity: %v", err)
-	}
-
-	// Master Data Tables
-	err = DB.AutoMigrate(&models.University{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate University: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Faculty{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Faculty: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.StudyProgram{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate StudyProgram: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Student{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Student: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Organization{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Organization: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Notification{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Notification: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.AuditLog{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate AuditLog: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.SystemSetting{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate SystemSetting: %v", err)
-	}
-
-	err = DB.AutoMigrate(&models.Broadcast{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate Broadcast: %v", err)
-	}
+// InitDB membuka koneksi lalu menjalankan migrasi yang belum diterapkan.
+// Server tidak boleh jalan dengan skema yang setengah termigrasi, jadi kegagalan migrasi bersifat fatal.
+func InitDB() {
+	Connect()
 
-	err = DB.AutoMigrate(&models.BroadcastRead{})
-	if err != nil {
-		log.Printf("Warning: Failed to migrate BroadcastRead: %v", err)
+	if err := MigrateUp(); err != nil {
+		log.Fatal("Failed to migrate database: ", err)
 	}
 
-	// Manual migration checks
-	var constraintExists bool
-	DB.Raw("SELECT COUNT(*) > 0 FROM information_schema.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'loan_items' AND CONSTRAINT_NAME = 'fk_loan_items_inventory'").Scan(&constraintExists)
-	if constraintExists {
-		DB.Exec("ALTER TABLE loan_items DROP FOREIGN KEY fk_loan_items_inventory")
-		fmt.Println("Dropped foreign key constraint fk_loan_items_inventory")
-	}
+	fmt.Println("Database migrated successfully!")
 
-	var columnExists bool
-	DB.Raw("SELECT COUNT(*) > 0 FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'loan_items' AND COLUMN_NAME = 'inventory_id'").Scan(&columnExists)
-	if columnExists {
-		DB.Exec("ALTER TABLE loan_items DROP COLUMN inventory_id")
-		fmt.Println("Dropped old inventory_id column from loan_items table")
+	if err := grantSuperadminPermissions(); err != nil {
+		log.Printf("Warning: Failed to grant permissions to superadmin: %v", err)
 	}
+}
 
-	fmt.Println("Database migrated successfully!")
+// grantSuperadminPermissions memastikan role superadmin selalu punya semua permission,
+// termasuk permission baru dari migrasi dan role yang dibuat setelah migrasi dijalankan.
+func grantSuperadminPermissions() error {
+	return DB.Exec("INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`) " +
+		"SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p " +
+		"WHERE r.code = 'superadmin'").Error
 }
@@ -0,0 +1,282 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// File migrasi: migrations/NNNN_nama.up.sql dan migrations/NNNN_nama.down.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration adalah satu versi skema database
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration mencatat versi migrasi yang sudah dijalankan
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus adalah status satu migrasi untuk perintah `migrate status`
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// loadMigrations membaca semua file migrasi yang di-embed, urut berdasarkan versi
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("nama file migrasi tidak valid: %s", fileName)
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		idx := strings.Index(base, "_")
+		if idx <= 0 {
+			return nil, fmt.Errorf("nama file migrasi tidak valid: %s", fileName)
+		}
+		version, err := strconv.Atoi(base[:idx])
+		if err != nil {
+			return nil, fmt.Errorf("versi migrasi tidak valid: %s", fileName)
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: base[idx+1:]}
+			byVersion[version] = m
+		} else if m.Name != base[idx+1:] {
+			return nil, fmt.Errorf("versi migrasi %d dipakai dua nama: %s dan %s", version, m.Name, base[idx+1:])
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrasi %04d_%s tidak punya file up", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(a, b int) bool { return migrations[a].Version < migrations[b].Version })
+	return migrations, nil
+}
+
+func ensureMigrationTable() error {
+	return DB.Exec("CREATE TABLE IF NOT EXISTS `schema_migrations` (" +
+		"`version` bigint NOT NULL," +
+		"`name` varchar(255) NOT NULL," +
+		"`applied_at` datetime(3) NOT NULL," +
+		"PRIMARY KEY (`version`))").Error
+}
+
+func appliedVersions() (map[int]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := DB.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// MigrateUp menjalankan semua migrasi yang belum diterapkan, berhenti di error pertama
+func MigrateUp() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if err := ensureMigrationTable(); err != nil {
+		return fmt.Errorf("gagal membuat tabel schema_migrations: %w", err)
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		log.Printf("Migrasi %04d_%s: up", m.Version, m.Name)
+		if err := runStatements(m.Up); err != nil {
+			return fmt.Errorf("migrasi %04d_%s gagal: %w", m.Version, m.Name, err)
+		}
+		record := SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}
+		if err := DB.Create(&record).Error; err != nil {
+			return fmt.Errorf("gagal mencatat migrasi %04d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// MigrateDown membatalkan sejumlah migrasi terakhir yang sudah diterapkan dan mengembalikan
+// jumlah yang benar-benar dibatalkan (bisa kurang dari steps jika migrasi yang diterapkan lebih sedikit,
+// atau jika rollback gagal di tengah jalan).
+func MigrateDown(steps int) (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if err := ensureMigrationTable(); err != nil {
+		return 0, fmt.Errorf("gagal membuat tabel schema_migrations: %w", err)
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	rolledBack := 0
+	for i := len(migrations) - 1; i >= 0 && rolledBack < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return rolledBack, fmt.Errorf("migrasi %04d_%s tidak punya file down", m.Version, m.Name)
+		}
+
+		log.Printf("Migrasi %04d_%s: down", m.Version, m.Name)
+		if err := runStatements(m.Down); err != nil {
+			return rolledBack, fmt.Errorf("rollback %04d_%s gagal: %w", m.Version, m.Name, err)
+		}
+		if err := DB.Delete(&SchemaMigration{}, m.Version).Error; err != nil {
+			return rolledBack, fmt.Errorf("gagal menghapus catatan migrasi %04d_%s: %w", m.Version, m.Name, err)
+		}
+		rolledBack++
+	}
+	return rolledBack, nil
+}
+
+// GetMigrationStatus mengembalikan semua migrasi beserta waktu diterapkan (nil = belum)
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(); err != nil {
+		return nil, fmt.Errorf("gagal membuat tabel schema_migrations: %w", err)
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		item := MigrationStatus{Version: m.Version, Name: m.Name}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			item.AppliedAt = &appliedAt
+		}
+		status = append(status, item)
+	}
+	return status, nil
+}
+
+// runStatements menjalankan isi file migrasi statement demi statement dalam satu koneksi,
+// supaya variabel sesi (SET @x, PREPARE) tetap terbawa antar statement.
+// DDL MySQL auto-commit, jadi migrasi sebaiknya ditulis idempoten.
+func runStatements(script string) error {
+	return DB.Connection(func(conn *gorm.DB) error {
+		for _, stmt := range splitStatements(script) {
+			if err := conn.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("%w\nstatement: %s", err, stmt)
+			}
+		}
+		return nil
+	})
+}
+
+// splitStatements memecah script SQL berdasarkan titik koma di luar string/identifier
+// dan membuang komentar baris (--).
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote byte
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+
+		if quote != 0 {
+			current.WriteByte(ch)
+			if ch == '\\' && quote != '`' && i+1 < len(script) {
+				i++
+				current.WriteByte(script[i])
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+			current.WriteByte(ch)
+		case ch == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+		case ch == ';':
+			if stmt := strings.TrimSpace(current.String()); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			current.Reset()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
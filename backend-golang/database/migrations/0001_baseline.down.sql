@@ -0,0 +1,25 @@
+-- Hapus semua tabel baseline (urutan terbalik karena foreign key)
+DROP TABLE IF EXISTS `broadcast_reads`;
+DROP TABLE IF EXISTS `broadcasts`;
+DROP TABLE IF EXISTS `system_settings`;
+DROP TABLE IF EXISTS `audit_logs`;
+DROP TABLE IF EXISTS `notifications`;
+DROP TABLE IF EXISTS `organizations`;
+DROP TABLE IF EXISTS `students`;
+DROP TABLE IF EXISTS `activities`;
+DROP TABLE IF EXISTS `loan_items`;
+DROP TABLE IF EXISTS `loans`;
+DROP TABLE IF EXISTS `inventories`;
+DROP TABLE IF EXISTS `tasks`;
+DROP TABLE IF EXISTS `budgets`;
+DROP TABLE IF EXISTS `certificates`;
+DROP TABLE IF EXISTS `form_answers`;
+DROP TABLE IF EXISTS `registrations`;
+DROP TABLE IF EXISTS `form_fields`;
+DROP TABLE IF EXISTS `committee_members`;
+DROP TABLE IF EXISTS `events`;
+DROP TABLE IF EXISTS `users`;
+DROP TABLE IF EXISTS `study_programs`;
+DROP TABLE IF EXISTS `faculties`;
+DROP TABLE IF EXISTS `universities`;
+DROP TABLE IF EXISTS `roles`;
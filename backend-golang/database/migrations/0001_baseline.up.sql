@@ -0,0 +1,395 @@
+-- Baseline: skema yang sebelumnya dibuat oleh GORM AutoMigrate.
+-- Memakai IF NOT EXISTS supaya aman dijalankan di database lama yang tabelnya sudah ada.
+
+CREATE TABLE IF NOT EXISTS `roles` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` longtext NOT NULL,
+  `code` varchar(191) NOT NULL,
+  `description` longtext,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_roles_code` UNIQUE (`code`)
+);
+
+CREATE TABLE IF NOT EXISTS `universities` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `code` varchar(50) NOT NULL,
+  `address` text,
+  `phone` varchar(20),
+  `website` varchar(255),
+  `logo` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_universities_deleted_at` (`deleted_at`),
+  CONSTRAINT `uni_universities_code` UNIQUE (`code`)
+);
+
+CREATE TABLE IF NOT EXISTS `faculties` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `university_id` bigint unsigned,
+  `name` varchar(255) NOT NULL,
+  `code` varchar(50) NOT NULL,
+  `description` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_faculties_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_faculties_university` FOREIGN KEY (`university_id`) REFERENCES `universities`(`id`),
+  CONSTRAINT `uni_faculties_code` UNIQUE (`code`)
+);
+
+CREATE TABLE IF NOT EXISTS `study_programs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `faculty_id` bigint unsigned,
+  `name` varchar(255) NOT NULL,
+  `code` varchar(50) NOT NULL,
+  `level` varchar(50),
+  `accreditation` varchar(10),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_study_programs_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_study_programs_faculty` FOREIGN KEY (`faculty_id`) REFERENCES `faculties`(`id`),
+  CONSTRAINT `uni_study_programs_code` UNIQUE (`code`)
+);
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` longtext,
+  `username` varchar(191) NOT NULL,
+  `email` varchar(191) NOT NULL,
+  `password` longtext,
+  `faculty` longtext,
+  `major` longtext,
+  `avatar` longtext,
+  `phone` longtext,
+  `university_id` bigint unsigned,
+  `faculty_id` bigint unsigned,
+  `study_program_id` bigint unsigned,
+  `angkatan` longtext,
+  `role_id` bigint unsigned,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_users_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_users_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`),
+  CONSTRAINT `fk_users_university` FOREIGN KEY (`university_id`) REFERENCES `universities`(`id`),
+  CONSTRAINT `fk_users_faculty_ref` FOREIGN KEY (`faculty_id`) REFERENCES `faculties`(`id`),
+  CONSTRAINT `fk_users_study_program` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs`(`id`),
+  CONSTRAINT `uni_users_username` UNIQUE (`username`),
+  CONSTRAINT `uni_users_email` UNIQUE (`email`)
+);
+
+CREATE TABLE IF NOT EXISTS `events` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `title` varchar(255) NOT NULL,
+  `slug` varchar(255) NOT NULL,
+  `description` text,
+  `banner` longtext,
+  `location` longtext,
+  `start_date` datetime(3) NULL,
+  `end_date` datetime(3) NULL,
+  `registration_deadline` datetime(3) NULL,
+  `status` varchar(191) DEFAULT 'draft',
+  `event_type` varchar(191) DEFAULT 'offline',
+  `quota` bigint,
+  `category` varchar(100),
+  `price` bigint DEFAULT 0,
+  `speakers` text,
+  `created_by_id` bigint unsigned,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_events_slug` (`slug`),
+  CONSTRAINT `fk_events_created_by` FOREIGN KEY (`created_by_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `committee_members` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `division` longtext,
+  `position` longtext,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_events_committees` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`),
+  CONSTRAINT `fk_committee_members_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `form_fields` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned,
+  `label` longtext,
+  `field_type` longtext,
+  `options` text,
+  `is_required` boolean,
+  `order` bigint,
+  `parent_field_id` bigint unsigned,
+  `conditional_value` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_form_fields_event_id` (`event_id`),
+  INDEX `idx_form_fields_parent_field_id` (`parent_field_id`),
+  CONSTRAINT `fk_events_form_schema` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `registrations` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned,
+  `user_id` bigint unsigned,
+  `status` varchar(191) DEFAULT 'pending',
+  `attendance` boolean DEFAULT false,
+  `attendance_type` longtext,
+  `attendance_proof_url` longtext,
+  `qr_code` varchar(191),
+  `certificate_url` longtext,
+  `payment_token` longtext,
+  `payment_url` longtext,
+  `order_id` longtext,
+  `paid_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_registrations_event` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`),
+  CONSTRAINT `fk_registrations_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+  CONSTRAINT `uni_registrations_qr_code` UNIQUE (`qr_code`)
+);
+
+CREATE TABLE IF NOT EXISTS `form_answers` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `registration_id` bigint unsigned,
+  `form_field_id` bigint unsigned,
+  `value` text,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_form_answers_form_field` FOREIGN KEY (`form_field_id`) REFERENCES `form_fields`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `certificates` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `registration_id` bigint unsigned NOT NULL,
+  `certificate_url` text,
+  `certificate_code` varchar(100),
+  `uploaded_at` datetime(3) NULL,
+  `email_sent` boolean DEFAULT false,
+  `email_sent_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_certificates_registration_id` (`registration_id`),
+  CONSTRAINT `fk_certificates_registration` FOREIGN KEY (`registration_id`) REFERENCES `registrations`(`id`),
+  CONSTRAINT `uni_certificates_certificate_code` UNIQUE (`certificate_code`)
+);
+
+CREATE TABLE IF NOT EXISTS `budgets` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned,
+  `division` longtext,
+  `item_name` longtext,
+  `quantity` bigint,
+  `plan_amount` double,
+  `real_amount` double,
+  `status` varchar(191) DEFAULT 'pending',
+  `proof_image` longtext,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_events_budgets` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `tasks` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned,
+  `assigned_to_id` bigint unsigned,
+  `created_by_id` bigint unsigned,
+  `title` longtext,
+  `description` longtext,
+  `status` varchar(191) DEFAULT 'todo',
+  `priority` longtext,
+  `due_date` datetime(3) NULL,
+  `proof_file` longtext,
+  `comments` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_tasks_assigned_to` FOREIGN KEY (`assigned_to_id`) REFERENCES `users`(`id`),
+  CONSTRAINT `fk_tasks_created_by` FOREIGN KEY (`created_by_id`) REFERENCES `users`(`id`),
+  CONSTRAINT `fk_events_tasks` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `inventories` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `description` text,
+  `total_stock` bigint NOT NULL DEFAULT 0,
+  `available_stock` bigint NOT NULL DEFAULT 0,
+  `condition` varchar(50) DEFAULT 'Good',
+  `image_url` longtext,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `loans` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned NOT NULL,
+  `loan_date` datetime(3) NOT NULL,
+  `return_date` datetime(3) NOT NULL,
+  `status` varchar(50) DEFAULT 'pending',
+  `notes` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_events_loans` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `loan_items` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `loan_id` bigint unsigned NOT NULL,
+  `item_name` varchar(255) NOT NULL,
+  `quantity` bigint NOT NULL,
+  `supplier` varchar(255),
+  `description` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_loans_items` FOREIGN KEY (`loan_id`) REFERENCES `loans`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `activities` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `activity_type` varchar(50) NOT NULL,
+  `entity_type` varchar(50),
+  `entity_id` bigint unsigned,
+  `description` text NOT NULL,
+  `metadata` text,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_activities_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `students` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `nim` varchar(50) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `email` varchar(255) NOT NULL,
+  `phone` varchar(20),
+  `study_program_id` bigint unsigned,
+  `batch` varchar(10),
+  `status` varchar(50) DEFAULT 'active',
+  `address` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_students_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_students_study_program` FOREIGN KEY (`study_program_id`) REFERENCES `study_programs`(`id`),
+  CONSTRAINT `uni_students_nim` UNIQUE (`nim`),
+  CONSTRAINT `uni_students_email` UNIQUE (`email`)
+);
+
+CREATE TABLE IF NOT EXISTS `organizations` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `code` varchar(50) NOT NULL,
+  `type` varchar(50),
+  `faculty_id` bigint unsigned,
+  `description` text,
+  `logo` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_organizations_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_organizations_faculty` FOREIGN KEY (`faculty_id`) REFERENCES `faculties`(`id`),
+  CONSTRAINT `uni_organizations_code` UNIQUE (`code`)
+);
+
+CREATE TABLE IF NOT EXISTS `notifications` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `type` varchar(50) NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `message` text,
+  `is_read` boolean DEFAULT false,
+  `read_at` datetime(3) NULL,
+  `entity_type` varchar(50),
+  `entity_id` bigint unsigned,
+  `action_url` varchar(255),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_notifications_user_id` (`user_id`),
+  INDEX `idx_notifications_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_notifications_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `audit_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned,
+  `action` varchar(100) NOT NULL,
+  `entity_type` varchar(50),
+  `entity_id` bigint unsigned,
+  `old_value` text,
+  `new_value` text,
+  `ip_address` varchar(50),
+  `user_agent` varchar(255),
+  `description` text,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_audit_logs_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `system_settings` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `key` varchar(100) NOT NULL,
+  `value` text,
+  `type` varchar(50) DEFAULT 'string',
+  `category` varchar(50),
+  `description` varchar(255),
+  `is_public` boolean DEFAULT false,
+  `updated_by` bigint unsigned,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_system_settings_key` (`key`),
+  CONSTRAINT `fk_system_settings_user` FOREIGN KEY (`updated_by`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `broadcasts` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `title` varchar(200) NOT NULL,
+  `message` text NOT NULL,
+  `type` varchar(50) DEFAULT 'info',
+  `target_role` varchar(50),
+  `send_email` boolean DEFAULT false,
+  `send_push` boolean DEFAULT true,
+  `sent_at` datetime(3) NULL,
+  `sent_by` bigint unsigned,
+  `read_count` bigint DEFAULT 0,
+  `total_target` bigint DEFAULT 0,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_broadcasts_user` FOREIGN KEY (`sent_by`) REFERENCES `users`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `broadcast_reads` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `broadcast_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `read_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_broadcast_reads_broadcast` FOREIGN KEY (`broadcast_id`) REFERENCES `broadcasts`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_broadcast_reads_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
@@ -0,0 +1,2 @@
+-- Kolom dikembalikan tanpa foreign key (data lama tidak bisa dipulihkan)
+ALTER TABLE loan_items ADD COLUMN `inventory_id` bigint unsigned NULL;
@@ -0,0 +1,17 @@
+-- Pengganti migrations/fix_loan_items.sql dan pengecekan manual di InitDB.
+-- loan_items sekarang menyimpan item_name/quantity langsung, tidak lagi merujuk ke inventories.
+-- MySQL tidak mendukung DROP ... IF EXISTS untuk foreign key/kolom, jadi dicek lewat information_schema.
+
+SET @stmt = (SELECT IF(COUNT(*) > 0, 'ALTER TABLE loan_items DROP FOREIGN KEY fk_loan_items_inventory', 'DO 0')
+  FROM information_schema.TABLE_CONSTRAINTS
+  WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'loan_items' AND CONSTRAINT_NAME = 'fk_loan_items_inventory');
+PREPARE migration_stmt FROM @stmt;
+EXECUTE migration_stmt;
+DEALLOCATE PREPARE migration_stmt;
+
+SET @stmt = (SELECT IF(COUNT(*) > 0, 'ALTER TABLE loan_items DROP COLUMN inventory_id', 'DO 0')
+  FROM information_schema.COLUMNS
+  WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'loan_items' AND COLUMN_NAME = 'inventory_id');
+PREPARE migration_stmt FROM @stmt;
+EXECUTE migration_stmt;
+DEALLOCATE PREPARE migration_stmt;
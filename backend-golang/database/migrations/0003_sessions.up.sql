@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `sessions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `refresh_token_hash` varchar(64) NOT NULL,
+  `device_name` varchar(255),
+  `ip_address` varchar(50),
+  `user_agent` varchar(255),
+  `last_seen_at` datetime(3) NULL,
+  `expires_at` datetime(3) NULL,
+  `revoked_at` datetime(3) NULL,
+  `revoked_reason` varchar(50),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_sessions_user_id` (`user_id`),
+  UNIQUE INDEX `idx_sessions_refresh_token_hash` (`refresh_token_hash`),
+  CONSTRAINT `fk_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `login_throttles` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `scope` varchar(20) NOT NULL,
+  `key` varchar(191) NOT NULL,
+  `user_id` bigint unsigned,
+  `failed_count` bigint DEFAULT 0,
+  `last_failed_at` datetime(3) NULL,
+  `locked_until` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_login_throttles_scope_key` (`scope`, `key`)
+);
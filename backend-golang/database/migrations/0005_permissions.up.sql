@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS `permissions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `code` varchar(100) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `description` varchar(255),
+  `category` varchar(50),
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_permissions_code` (`code`)
+);
+
+CREATE TABLE IF NOT EXISTS `role_permissions` (
+  `role_id` bigint unsigned NOT NULL,
+  `permission_id` bigint unsigned NOT NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`role_id`, `permission_id`),
+  CONSTRAINT `fk_role_permissions_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_role_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions`(`id`) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO `permissions` (`code`, `name`, `description`, `category`, `created_at`, `updated_at`) VALUES
+  ('settings.manage', 'Kelola pengaturan sistem', 'Melihat dan mengubah system settings', 'system', NOW(3), NOW(3)),
+  ('audit.view', 'Lihat audit log', 'Melihat audit log dan statistiknya', 'system', NOW(3), NOW(3)),
+  ('broadcast.manage', 'Kelola broadcast', 'Membuat, menghapus dan melihat statistik broadcast', 'system', NOW(3), NOW(3)),
+  ('activity.view_all', 'Lihat semua aktivitas', 'Melihat aktivitas seluruh event', 'system', NOW(3), NOW(3)),
+  ('jobs.manage', 'Kelola background job', 'Melihat dan menjalankan background job', 'system', NOW(3), NOW(3)),
+  ('users.unlock', 'Buka lockout login', 'Membuka akun yang terkunci karena login gagal', 'users', NOW(3), NOW(3)),
+  ('roles.manage', 'Kelola hak akses role', 'Mengubah permission yang dimiliki setiap role', 'users', NOW(3), NOW(3)),
+  ('master.manage', 'Kelola master data', 'Universitas, fakultas, prodi, mahasiswa, organisasi', 'master', NOW(3), NOW(3)),
+  ('budget.manage', 'Kelola anggaran', 'Menambah dan mengubah anggaran event', 'event', NOW(3), NOW(3)),
+  ('budget.approve', 'Setujui anggaran', 'Menolak/menyetujui pengajuan anggaran event', 'event', NOW(3), NOW(3)),
+  ('task.manage', 'Kelola tugas', 'Mengubah status dan mengomentari tugas event', 'event', NOW(3), NOW(3)),
+  ('attendance.manage', 'Kelola kehadiran', 'Mengubah kehadiran peserta event', 'event', NOW(3), NOW(3)),
+  ('loan.request', 'Ajukan peminjaman', 'Mengajukan peminjaman inventaris untuk event', 'event', NOW(3), NOW(3)),
+  ('inventory.manage', 'Kelola inventaris', 'CRUD inventaris dan persetujuan peminjaman', 'inventory', NOW(3), NOW(3));
+
+-- Grant awal menyamakan perilaku sebelumnya (cek role ID/code yang di-hardcode)
+
+-- Super Admin: semua permission
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`)
+SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p
+WHERE r.code = 'superadmin' OR r.id = 1;
+
+-- Bendahara Umum (role_id 3)
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`)
+SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p
+WHERE (r.id = 3 OR r.code IN ('bendahara_umum', 'bendahara'))
+  AND p.code IN ('budget.manage', 'budget.approve');
+
+-- Ketua Himpunan
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`)
+SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p
+WHERE (r.code = 'kahim' OR LOWER(r.name) = 'ketua himpunan')
+  AND p.code IN ('master.manage', 'task.manage');
+
+-- Logistik
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`)
+SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p
+WHERE r.code = 'logistik'
+  AND p.code IN ('inventory.manage', 'loan.request');
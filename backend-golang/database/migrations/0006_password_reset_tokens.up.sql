@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `password_reset_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `token_hash` varchar(64) NOT NULL,
+  `expires_at` datetime(3) NULL,
+  `used_at` datetime(3) NULL,
+  `ip_address` varchar(50),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_password_reset_tokens_user_id` (`user_id`),
+  UNIQUE INDEX `idx_password_reset_tokens_token_hash` (`token_hash`),
+  CONSTRAINT `fk_password_reset_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
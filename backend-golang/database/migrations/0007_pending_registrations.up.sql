@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS `pending_registrations` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `email` varchar(191) NOT NULL,
+  `name` varchar(255),
+  `username` varchar(191),
+  `password_hash` varchar(255),
+  `phone` varchar(50),
+  `university_id` bigint unsigned,
+  `faculty_id` bigint unsigned,
+  `study_program_id` bigint unsigned,
+  `angkatan` varchar(20),
+  `code_hash` varchar(255) NOT NULL,
+  `expires_at` datetime(3) NULL,
+  `attempts` bigint DEFAULT 0,
+  `locked_until` datetime(3) NULL,
+  `last_sent_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_pending_registrations_email` (`email`)
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS `user_two_factors` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `secret_encrypted` varchar(255) NOT NULL,
+  `enabled_at` datetime(3) NULL,
+  `last_used_step` bigint DEFAULT 0,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_user_two_factors_user_id` (`user_id`),
+  CONSTRAINT `fk_user_two_factors_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `two_factor_recovery_codes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `code_hash` varchar(64) NOT NULL,
+  `used_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_two_factor_recovery_codes_user_id` (`user_id`),
+  CONSTRAINT `fk_two_factor_recovery_codes_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
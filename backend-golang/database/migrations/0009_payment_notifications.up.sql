@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS `payment_notifications` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `provider` varchar(30) NOT NULL DEFAULT 'midtrans',
+  `order_id` varchar(100),
+  `transaction_id` varchar(100),
+  `transaction_status` varchar(30),
+  `fraud_status` varchar(30),
+  `status_code` varchar(10),
+  `gross_amount` varchar(30),
+  `signature_valid` boolean DEFAULT false,
+  `dedupe_key` varchar(150),
+  `result` varchar(30),
+  `message` varchar(255),
+  `raw_payload` text,
+  `ip_address` varchar(50),
+  `processed_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_payment_notifications_order_id` (`order_id`),
+  UNIQUE INDEX `idx_payment_notifications_dedupe_key` (`dedupe_key`)
+);
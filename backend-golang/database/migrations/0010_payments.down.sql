@@ -0,0 +1,11 @@
+ALTER TABLE `payment_notifications` DROP INDEX `idx_payment_notifications_payment_id`, DROP COLUMN `payment_id`;
+
+ALTER TABLE `registrations` ADD COLUMN `payment_token` longtext, ADD COLUMN `payment_url` longtext, ADD COLUMN `order_id` longtext;
+
+-- Kembalikan payment terbaru tiap registrasi ke kolom lama
+UPDATE `registrations` r
+JOIN `payments` p ON p.id = (SELECT MAX(p2.id) FROM `payments` p2 WHERE p2.registration_id = r.id)
+SET r.payment_token = p.snap_token, r.payment_url = p.redirect_url, r.order_id = p.order_id;
+
+DROP TABLE IF EXISTS `payment_status_histories`;
+DROP TABLE IF EXISTS `payments`;
@@ -0,0 +1,56 @@
+-- Data pembayaran dipindah dari kolom registrations ke tabel payments (satu registrasi bisa punya banyak payment).
+CREATE TABLE IF NOT EXISTS `payments` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `registration_id` bigint unsigned NOT NULL,
+  `provider` varchar(30) NOT NULL DEFAULT 'midtrans',
+  `order_id` varchar(100) NOT NULL,
+  `amount` bigint,
+  `status` varchar(20) NOT NULL DEFAULT 'pending',
+  `provider_status` varchar(30),
+  `payment_type` varchar(50),
+  `transaction_id` varchar(100),
+  `snap_token` varchar(255),
+  `redirect_url` varchar(255),
+  `raw_response` text,
+  `paid_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_payments_registration_id` (`registration_id`),
+  UNIQUE INDEX `idx_payments_order_id` (`order_id`),
+  INDEX `idx_payments_status` (`status`),
+  CONSTRAINT `fk_payments_registration` FOREIGN KEY (`registration_id`) REFERENCES `registrations`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `payment_status_histories` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `payment_id` bigint unsigned NOT NULL,
+  `from_status` varchar(20),
+  `to_status` varchar(20),
+  `provider_status` varchar(30),
+  `source` varchar(30),
+  `note` varchar(255),
+  `raw_payload` text,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_payment_status_histories_payment_id` (`payment_id`),
+  CONSTRAINT `fk_payments_status_history` FOREIGN KEY (`payment_id`) REFERENCES `payments`(`id`) ON DELETE CASCADE
+);
+
+-- Pindahkan order terakhir yang tersimpan di registrations
+INSERT INTO `payments` (`registration_id`, `provider`, `order_id`, `amount`, `status`, `snap_token`, `redirect_url`, `paid_at`, `created_at`, `updated_at`)
+SELECT r.id, 'midtrans', r.order_id, COALESCE(e.price, 0),
+  CASE WHEN r.status = 'confirmed' THEN 'paid' WHEN r.status = 'rejected' THEN 'failed' ELSE 'pending' END,
+  r.payment_token, r.payment_url,
+  CASE WHEN r.status = 'confirmed' THEN r.paid_at ELSE NULL END,
+  r.created_at, r.updated_at
+FROM `registrations` r
+LEFT JOIN `events` e ON e.id = r.event_id
+WHERE r.order_id IS NOT NULL AND r.order_id <> '';
+
+INSERT INTO `payment_status_histories` (`payment_id`, `from_status`, `to_status`, `source`, `note`, `created_at`)
+SELECT id, '', status, 'migration', 'Dipindahkan dari kolom registrations', NOW(3) FROM `payments`;
+
+ALTER TABLE `registrations` DROP COLUMN `payment_token`, DROP COLUMN `payment_url`, DROP COLUMN `order_id`;
+
+ALTER TABLE `payment_notifications` ADD COLUMN `payment_id` bigint unsigned NULL AFTER `provider`, ADD INDEX `idx_payment_notifications_payment_id` (`payment_id`);
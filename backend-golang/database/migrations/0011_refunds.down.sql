@@ -0,0 +1,5 @@
+DELETE FROM `permissions` WHERE `code` = 'payment.refund';
+
+DROP TABLE IF EXISTS `refunds`;
+
+ALTER TABLE `events` DROP COLUMN `refund_percentage`, DROP COLUMN `refund_deadline`;
@@ -0,0 +1,38 @@
+-- Kebijakan refund per event dan pencatatan refund untuk pembatalan registrasi
+ALTER TABLE `events`
+  ADD COLUMN `refund_deadline` datetime(3) NULL AFTER `registration_deadline`,
+  ADD COLUMN `refund_percentage` bigint DEFAULT 0 AFTER `refund_deadline`;
+
+CREATE TABLE IF NOT EXISTS `refunds` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `payment_id` bigint unsigned NOT NULL,
+  `registration_id` bigint unsigned NOT NULL,
+  `amount` bigint,
+  `percentage` bigint,
+  `reason` varchar(255),
+  `status` varchar(20) NOT NULL DEFAULT 'pending',
+  `refund_key` varchar(100),
+  `provider_status` varchar(30),
+  `raw_response` text,
+  `error_message` varchar(255),
+  `requested_by_id` bigint unsigned NULL,
+  `processed_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_refunds_payment_id` (`payment_id`),
+  INDEX `idx_refunds_registration_id` (`registration_id`),
+  INDEX `idx_refunds_status` (`status`),
+  UNIQUE INDEX `idx_refunds_refund_key` (`refund_key`),
+  CONSTRAINT `fk_refunds_payment` FOREIGN KEY (`payment_id`) REFERENCES `payments`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_refunds_registration` FOREIGN KEY (`registration_id`) REFERENCES `registrations`(`id`) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO `permissions` (`code`, `name`, `description`, `category`, `created_at`, `updated_at`) VALUES
+  ('payment.refund', 'Refund pembayaran', 'Membatalkan registrasi peserta dan mengembalikan dana tiket', 'event', NOW(3), NOW(3));
+
+-- Bendahara Umum
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`)
+SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p
+WHERE (r.id = 3 OR r.code IN ('bendahara_umum', 'bendahara'))
+  AND p.code = 'payment.refund';
@@ -0,0 +1,4 @@
+DELETE FROM `permissions` WHERE `code` = 'payment.report';
+
+DROP TABLE IF EXISTS `payment_reconciliation_items`;
+DROP TABLE IF EXISTS `payment_reconciliation_runs`;
@@ -0,0 +1,41 @@
+-- Hasil job rekonsiliasi pembayaran (order pending yang webhook-nya hilang / tidak dibayar)
+CREATE TABLE IF NOT EXISTS `payment_reconciliation_runs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `started_at` datetime(3) NULL,
+  `finished_at` datetime(3) NULL,
+  `checked` bigint DEFAULT 0,
+  `updated` bigint DEFAULT 0,
+  `confirmed` bigint DEFAULT 0,
+  `expired` bigint DEFAULT 0,
+  `mismatched` bigint DEFAULT 0,
+  `failed` bigint DEFAULT 0,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `payment_reconciliation_items` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `run_id` bigint unsigned NOT NULL,
+  `payment_id` bigint unsigned NULL,
+  `registration_id` bigint unsigned,
+  `order_id` varchar(100),
+  `action` varchar(30),
+  `from_status` varchar(20),
+  `to_status` varchar(20),
+  `provider_status` varchar(30),
+  `message` varchar(255),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_payment_reconciliation_items_run_id` (`run_id`),
+  INDEX `idx_payment_reconciliation_items_payment_id` (`payment_id`),
+  INDEX `idx_payment_reconciliation_items_action` (`action`),
+  CONSTRAINT `fk_payment_reconciliation_runs_items` FOREIGN KEY (`run_id`) REFERENCES `payment_reconciliation_runs`(`id`) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO `permissions` (`code`, `name`, `description`, `category`, `created_at`, `updated_at`) VALUES
+  ('payment.report', 'Laporan rekonsiliasi pembayaran', 'Melihat laporan dan menjalankan rekonsiliasi pembayaran', 'payment', NOW(3), NOW(3));
+
+-- Bendahara Umum
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`)
+SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p
+WHERE (r.id = 3 OR r.code IN ('bendahara_umum', 'bendahara'))
+  AND p.code = 'payment.report';
@@ -0,0 +1,36 @@
+-- Kuitansi pembayaran (PDF) dengan nomor urut per tahun
+CREATE TABLE IF NOT EXISTS `invoice_counters` (
+  `year` bigint NOT NULL,
+  `last_number` bigint unsigned NOT NULL DEFAULT 0,
+  PRIMARY KEY (`year`)
+);
+
+CREATE TABLE IF NOT EXISTS `invoices` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `number` varchar(50) NOT NULL,
+  `year` bigint NOT NULL,
+  `sequence` bigint unsigned NOT NULL,
+  `registration_id` bigint unsigned NOT NULL,
+  `payment_id` bigint unsigned,
+  `user_id` bigint unsigned,
+  `event_id` bigint unsigned,
+  `billed_name` varchar(255),
+  `billed_email` varchar(255),
+  `event_title` varchar(255),
+  `order_id` varchar(100),
+  `amount` bigint,
+  `payment_method` varchar(50),
+  `paid_at` datetime(3) NULL,
+  `organizer_name` varchar(255),
+  `organizer_address` varchar(500),
+  `organizer_email` varchar(255),
+  `organizer_phone` varchar(50),
+  `email_sent_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_invoices_number` (`number`),
+  UNIQUE INDEX `idx_invoices_registration_id` (`registration_id`),
+  INDEX `idx_invoices_payment_id` (`payment_id`),
+  INDEX `idx_invoices_user_id` (`user_id`),
+  CONSTRAINT `fk_invoices_registration` FOREIGN KEY (`registration_id`) REFERENCES `registrations`(`id`) ON DELETE CASCADE
+);
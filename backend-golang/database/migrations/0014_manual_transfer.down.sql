@@ -0,0 +1,9 @@
+DELETE FROM `permissions` WHERE `code` = 'payment.verify';
+
+DROP TABLE IF EXISTS `payment_proofs`;
+
+ALTER TABLE `events`
+  DROP COLUMN `transfer_account_name`,
+  DROP COLUMN `transfer_account_number`,
+  DROP COLUMN `transfer_bank_name`,
+  DROP COLUMN `payment_method`;
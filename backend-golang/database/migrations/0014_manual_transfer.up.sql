@@ -0,0 +1,39 @@
+-- Metode pembayaran transfer bank manual dengan upload bukti transfer
+ALTER TABLE `events`
+  ADD COLUMN `payment_method` varchar(30) DEFAULT 'gateway' AFTER `price`,
+  ADD COLUMN `transfer_bank_name` varchar(100) AFTER `payment_method`,
+  ADD COLUMN `transfer_account_number` varchar(50) AFTER `transfer_bank_name`,
+  ADD COLUMN `transfer_account_name` varchar(255) AFTER `transfer_account_number`;
+
+CREATE TABLE IF NOT EXISTS `payment_proofs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `payment_id` bigint unsigned NOT NULL,
+  `registration_id` bigint unsigned NOT NULL,
+  `file_path` varchar(255) NOT NULL,
+  `original_name` varchar(255),
+  `sender_name` varchar(255),
+  `sender_bank` varchar(100),
+  `amount` bigint,
+  `note` varchar(255),
+  `status` varchar(20) NOT NULL DEFAULT 'pending',
+  `reject_reason` varchar(255),
+  `reviewed_by_id` bigint unsigned NULL,
+  `reviewed_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_payment_proofs_payment_id` (`payment_id`),
+  INDEX `idx_payment_proofs_registration_id` (`registration_id`),
+  INDEX `idx_payment_proofs_status` (`status`),
+  CONSTRAINT `fk_payment_proofs_payment` FOREIGN KEY (`payment_id`) REFERENCES `payments`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_payment_proofs_registration` FOREIGN KEY (`registration_id`) REFERENCES `registrations`(`id`) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO `permissions` (`code`, `name`, `description`, `category`, `created_at`, `updated_at`) VALUES
+  ('payment.verify', 'Verifikasi transfer manual', 'Menyetujui/menolak bukti transfer bank peserta', 'event', NOW(3), NOW(3));
+
+-- Bendahara Umum
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`)
+SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p
+WHERE (r.id = 3 OR r.code IN ('bendahara_umum', 'bendahara'))
+  AND p.code = 'payment.verify';
@@ -0,0 +1,7 @@
+ALTER TABLE `registrations`
+  DROP FOREIGN KEY `fk_registrations_ticket_type`,
+  DROP INDEX `idx_registrations_ticket_type_id`,
+  DROP COLUMN `price`,
+  DROP COLUMN `ticket_type_id`;
+
+DROP TABLE IF EXISTS `ticket_types`;
@@ -0,0 +1,27 @@
+-- Tipe tiket dan tingkatan harga per event
+CREATE TABLE IF NOT EXISTS `ticket_types` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `description` text,
+  `price` bigint DEFAULT 0,
+  `quota` bigint DEFAULT 0,
+  `sales_start` datetime(3) NULL,
+  `sales_end` datetime(3) NULL,
+  `visibility` varchar(20) DEFAULT 'public',
+  `sort_order` bigint DEFAULT 0,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_ticket_types_event_id` (`event_id`),
+  CONSTRAINT `fk_ticket_types_event` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE
+);
+
+ALTER TABLE `registrations`
+  ADD COLUMN `ticket_type_id` bigint unsigned NULL AFTER `certificate_url`,
+  ADD COLUMN `price` bigint DEFAULT 0 AFTER `ticket_type_id`,
+  ADD INDEX `idx_registrations_ticket_type_id` (`ticket_type_id`),
+  ADD CONSTRAINT `fk_registrations_ticket_type` FOREIGN KEY (`ticket_type_id`) REFERENCES `ticket_types`(`id`);
+
+-- Registrasi lama dibayar dengan harga event
+UPDATE `registrations` r JOIN `events` e ON e.id = r.event_id SET r.price = e.price;
@@ -0,0 +1,5 @@
+DELETE FROM `permissions` WHERE `code` = 'promo.manage';
+
+DROP TABLE IF EXISTS `promo_code_usages`;
+DROP TABLE IF EXISTS `promo_code_ticket_types`;
+DROP TABLE IF EXISTS `promo_codes`;
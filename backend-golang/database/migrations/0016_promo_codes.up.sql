@@ -0,0 +1,57 @@
+-- Kode promo / diskon untuk event berbayar
+CREATE TABLE IF NOT EXISTS `promo_codes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `code` varchar(50) NOT NULL,
+  `description` varchar(255),
+  `discount_type` varchar(20) NOT NULL,
+  `discount_value` bigint,
+  `max_discount` bigint DEFAULT 0,
+  `max_uses` bigint DEFAULT 0,
+  `per_user_limit` bigint DEFAULT 1,
+  `valid_from` datetime(3) NULL,
+  `valid_until` datetime(3) NULL,
+  `event_id` bigint unsigned NULL,
+  `is_active` boolean DEFAULT true,
+  `created_by_id` bigint unsigned NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_promo_codes_code` (`code`),
+  INDEX `idx_promo_codes_event_id` (`event_id`),
+  CONSTRAINT `fk_promo_codes_event` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `promo_code_ticket_types` (
+  `promo_code_id` bigint unsigned NOT NULL,
+  `ticket_type_id` bigint unsigned NOT NULL,
+  PRIMARY KEY (`promo_code_id`, `ticket_type_id`),
+  CONSTRAINT `fk_promo_code_ticket_types_promo_code` FOREIGN KEY (`promo_code_id`) REFERENCES `promo_codes`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_promo_code_ticket_types_ticket_type` FOREIGN KEY (`ticket_type_id`) REFERENCES `ticket_types`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `promo_code_usages` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `promo_code_id` bigint unsigned NOT NULL,
+  `registration_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `event_id` bigint unsigned NOT NULL,
+  `original_price` bigint,
+  `discount_amount` bigint,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_promo_code_usages_registration_id` (`registration_id`),
+  INDEX `idx_promo_code_usages_promo_code_id` (`promo_code_id`),
+  INDEX `idx_promo_code_usages_user_id` (`user_id`),
+  INDEX `idx_promo_code_usages_event_id` (`event_id`),
+  CONSTRAINT `fk_promo_code_usages_promo_code` FOREIGN KEY (`promo_code_id`) REFERENCES `promo_codes`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_promo_code_usages_registration` FOREIGN KEY (`registration_id`) REFERENCES `registrations`(`id`) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO `permissions` (`code`, `name`, `description`, `category`, `created_at`, `updated_at`) VALUES
+  ('promo.manage', 'Kelola kode promo', 'Membuat kode diskon event berbayar dan melihat laporan pemakaiannya', 'event', NOW(3), NOW(3));
+
+-- Bendahara Umum
+INSERT IGNORE INTO `role_permissions` (`role_id`, `permission_id`, `created_at`)
+SELECT r.id, p.id, NOW(3) FROM `roles` r CROSS JOIN `permissions` p
+WHERE (r.id = 3 OR r.code IN ('bendahara_umum', 'bendahara'))
+  AND p.code = 'promo.manage';
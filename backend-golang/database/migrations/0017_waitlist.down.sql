@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `waitlist_entries`;
+
+ALTER TABLE `events` DROP COLUMN `waitlist_enabled`;
@@ -0,0 +1,21 @@
+-- Waitlist untuk event yang kuotanya penuh
+ALTER TABLE `events`
+  ADD COLUMN `waitlist_enabled` boolean DEFAULT false AFTER `quota`;
+
+CREATE TABLE IF NOT EXISTS `waitlist_entries` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `status` varchar(20) NOT NULL DEFAULT 'waiting',
+  `offered_at` datetime(3) NULL,
+  `offer_expires_at` datetime(3) NULL,
+  `registration_id` bigint unsigned NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_waitlist_entries_event_id` (`event_id`),
+  INDEX `idx_waitlist_entries_user_id` (`user_id`),
+  INDEX `idx_waitlist_entries_status` (`status`),
+  CONSTRAINT `fk_waitlist_entries_event` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_waitlist_entries_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
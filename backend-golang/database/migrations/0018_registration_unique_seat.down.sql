@@ -0,0 +1,3 @@
+ALTER TABLE `registrations`
+  DROP INDEX `idx_registrations_event_user_active`,
+  DROP COLUMN `active_seat`;
@@ -0,0 +1,20 @@
+-- Satu registrasi aktif per user per event. Registrasi yang dibatalkan/direfund tidak ikut
+-- (kolom active_seat bernilai NULL) sehingga user tetap bisa mendaftar ulang.
+-- Duplikat lama hasil pendaftaran bersamaan dibatalkan sebelum unique index dibuat: per (event, user)
+-- dipertahankan satu registrasi aktif dengan status paling maju (checked_in, confirmed, pending,
+-- rejected), lalu id paling kecil. Registrasi lain dibatalkan.
+UPDATE `registrations` r
+  JOIN `registrations` keeper
+    ON keeper.event_id = r.event_id
+   AND keeper.user_id = r.user_id
+   AND keeper.id <> r.id
+   AND keeper.status NOT IN ('cancelled', 'refunded')
+   AND (FIELD(keeper.status, 'rejected', 'pending', 'confirmed', 'checked_in') > FIELD(r.status, 'rejected', 'pending', 'confirmed', 'checked_in')
+     OR (FIELD(keeper.status, 'rejected', 'pending', 'confirmed', 'checked_in') = FIELD(r.status, 'rejected', 'pending', 'confirmed', 'checked_in')
+         AND keeper.id < r.id))
+   SET r.status = 'cancelled'
+ WHERE r.status NOT IN ('cancelled', 'refunded');
+
+ALTER TABLE `registrations`
+  ADD COLUMN `active_seat` tinyint GENERATED ALWAYS AS (IF(`status` IN ('cancelled', 'refunded'), NULL, 1)) STORED,
+  ADD UNIQUE INDEX `idx_registrations_event_user_active` (`event_id`, `user_id`, `active_seat`);
@@ -0,0 +1,12 @@
+ALTER TABLE `registrations`
+  DROP INDEX `idx_registrations_event_attendee_active`,
+  ADD UNIQUE INDEX `idx_registrations_event_user_active` (`event_id`, `user_id`, `active_seat`);
+
+ALTER TABLE `registrations`
+  DROP FOREIGN KEY `fk_registrations_group`,
+  DROP INDEX `idx_registrations_group_id`,
+  DROP COLUMN `guest_email`,
+  DROP COLUMN `guest_name`,
+  DROP COLUMN `group_id`;
+
+DROP TABLE IF EXISTS `registration_groups`;
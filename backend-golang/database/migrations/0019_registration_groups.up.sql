@@ -0,0 +1,31 @@
+-- Pendaftaran grup: satu pendaftar mendaftarkan beberapa peserta (user terdaftar atau tamu)
+CREATE TABLE IF NOT EXISTS `registration_groups` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned NOT NULL,
+  `registrant_id` bigint unsigned NOT NULL,
+  `name` varchar(150),
+  `ticket_type_id` bigint unsigned NULL,
+  `quantity` bigint DEFAULT 0,
+  `total_price` bigint DEFAULT 0,
+  `lead_registration_id` bigint unsigned NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_registration_groups_event_id` (`event_id`),
+  INDEX `idx_registration_groups_registrant_id` (`registrant_id`),
+  CONSTRAINT `fk_registration_groups_event` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_registration_groups_registrant` FOREIGN KEY (`registrant_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
+
+-- Peserta tamu dicatat atas nama pendaftar, dibedakan lewat guest_email ('' untuk peserta biasa)
+ALTER TABLE `registrations`
+  ADD COLUMN `group_id` bigint unsigned NULL AFTER `price`,
+  ADD COLUMN `guest_name` varchar(100) NULL AFTER `group_id`,
+  ADD COLUMN `guest_email` varchar(191) NOT NULL DEFAULT '' AFTER `guest_name`,
+  ADD INDEX `idx_registrations_group_id` (`group_id`),
+  ADD CONSTRAINT `fk_registrations_group` FOREIGN KEY (`group_id`) REFERENCES `registration_groups`(`id`) ON DELETE SET NULL;
+
+-- Satu registrasi aktif per peserta: user sendiri, atau tiap email tamu yang didaftarkan user tersebut
+ALTER TABLE `registrations`
+  DROP INDEX `idx_registrations_event_user_active`,
+  ADD UNIQUE INDEX `idx_registrations_event_attendee_active` (`event_id`, `user_id`, `guest_email`, `active_seat`);
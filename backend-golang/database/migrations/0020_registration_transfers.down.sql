@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `registration_transfers`;
+
+ALTER TABLE `events`
+  DROP COLUMN `transfer_deadline`,
+  DROP COLUMN `transfer_enabled`;
@@ -0,0 +1,28 @@
+-- Transfer tiket ke user lain
+ALTER TABLE `events`
+  ADD COLUMN `transfer_enabled` boolean DEFAULT false AFTER `waitlist_enabled`,
+  ADD COLUMN `transfer_deadline` datetime(3) NULL AFTER `transfer_enabled`;
+
+CREATE TABLE IF NOT EXISTS `registration_transfers` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `registration_id` bigint unsigned NOT NULL,
+  `event_id` bigint unsigned NOT NULL,
+  `from_user_id` bigint unsigned NOT NULL,
+  `to_user_id` bigint unsigned NOT NULL,
+  `status` varchar(20) NOT NULL DEFAULT 'pending',
+  `note` varchar(255),
+  `expires_at` datetime(3) NULL,
+  `responded_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_registration_transfers_registration_id` (`registration_id`),
+  INDEX `idx_registration_transfers_event_id` (`event_id`),
+  INDEX `idx_registration_transfers_from_user_id` (`from_user_id`),
+  INDEX `idx_registration_transfers_to_user_id` (`to_user_id`),
+  INDEX `idx_registration_transfers_status` (`status`),
+  CONSTRAINT `fk_registration_transfers_registration` FOREIGN KEY (`registration_id`) REFERENCES `registrations`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_registration_transfers_event` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_registration_transfers_from_user` FOREIGN KEY (`from_user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_registration_transfers_to_user` FOREIGN KEY (`to_user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
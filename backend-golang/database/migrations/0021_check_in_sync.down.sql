@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `check_in_scans`;
+
+ALTER TABLE `registrations`
+  DROP COLUMN `checked_in_at`;
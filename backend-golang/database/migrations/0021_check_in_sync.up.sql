@@ -0,0 +1,24 @@
+-- Check-in offline: waktu check-in pertama dan log scan dari perangkat scanner
+ALTER TABLE `registrations`
+  ADD COLUMN `checked_in_at` datetime(3) NULL AFTER `attendance_proof_url`;
+
+UPDATE `registrations` SET `checked_in_at` = `updated_at` WHERE `status` = 'checked_in';
+
+CREATE TABLE IF NOT EXISTS `check_in_scans` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned NOT NULL,
+  `registration_id` bigint unsigned NULL,
+  `device_id` varchar(100) NOT NULL,
+  `client_scan_id` varchar(100) NOT NULL,
+  `qr_hash` varchar(64),
+  `scanned_at` datetime(3) NULL,
+  `scanned_by_id` bigint unsigned,
+  `result` varchar(20) NOT NULL,
+  `message` varchar(255),
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_check_in_scans_device_scan` (`device_id`, `client_scan_id`),
+  INDEX `idx_check_in_scans_event_id` (`event_id`),
+  INDEX `idx_check_in_scans_registration_id` (`registration_id`),
+  CONSTRAINT `fk_check_in_scans_event` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+ALTER TABLE `check_in_scans`
+  DROP COLUMN `event_session_id`;
+
+DROP TABLE IF EXISTS `attendance_records`;
+DROP TABLE IF EXISTS `event_sessions`;
+
+ALTER TABLE `events`
+  DROP COLUMN `certificate_min_attendance`;
@@ -0,0 +1,43 @@
+-- Event multi-sesi: jadwal sesi, kehadiran per sesi dan syarat kehadiran sertifikat
+ALTER TABLE `events`
+  ADD COLUMN `certificate_min_attendance` bigint DEFAULT 0 AFTER `transfer_deadline`;
+
+CREATE TABLE IF NOT EXISTS `event_sessions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `event_id` bigint unsigned NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `description` text,
+  `location` varchar(255),
+  `start_time` datetime(3) NULL,
+  `end_time` datetime(3) NULL,
+  `sort_order` bigint DEFAULT 0,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_event_sessions_event_id` (`event_id`),
+  CONSTRAINT `fk_events_sessions` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `attendance_records` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `registration_id` bigint unsigned NOT NULL,
+  `event_session_id` bigint unsigned NOT NULL,
+  `event_id` bigint unsigned NOT NULL,
+  `check_in_at` datetime(3) NULL,
+  `check_out_at` datetime(3) NULL,
+  `method` varchar(20),
+  `recorded_by_id` bigint unsigned NULL,
+  `proof_url` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_attendance_records_registration_session` (`registration_id`, `event_session_id`),
+  INDEX `idx_attendance_records_event_session_id` (`event_session_id`),
+  INDEX `idx_attendance_records_event_id` (`event_id`),
+  CONSTRAINT `fk_attendance_records_registration` FOREIGN KEY (`registration_id`) REFERENCES `registrations`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_attendance_records_session` FOREIGN KEY (`event_session_id`) REFERENCES `event_sessions`(`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_attendance_records_event` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE
+);
+
+ALTER TABLE `check_in_scans`
+  ADD COLUMN `event_session_id` bigint unsigned NULL AFTER `registration_id`;
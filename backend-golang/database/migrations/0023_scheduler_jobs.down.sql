@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS `job_runs`;
+DROP TABLE IF EXISTS `job_locks`;
+
+ALTER TABLE `tasks`
+  DROP COLUMN `reminder_sent_at`;
+
+ALTER TABLE `events`
+  DROP COLUMN `reminder_sent_at`;
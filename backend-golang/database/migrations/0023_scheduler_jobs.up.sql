@@ -0,0 +1,31 @@
+-- Background job scheduler: kolom penanda reminder serta tabel kunci dan riwayat job.
+-- Dipisah dari baseline karena database lama melewati CREATE TABLE baseline (IF NOT EXISTS).
+ALTER TABLE `events`
+  ADD COLUMN `reminder_sent_at` datetime(3) NULL AFTER `speakers`;
+
+ALTER TABLE `tasks`
+  ADD COLUMN `reminder_sent_at` datetime(3) NULL AFTER `comments`;
+
+CREATE TABLE IF NOT EXISTS `job_locks` (
+  `name` varchar(100),
+  `locked_by` varchar(255),
+  `locked_until` datetime(3) NULL,
+  `last_tick_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `job_runs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `job_name` varchar(100) NOT NULL,
+  `trigger` varchar(20) NOT NULL,
+  `triggered_by` bigint unsigned,
+  `instance` varchar(255),
+  `status` varchar(20) NOT NULL,
+  `error` text,
+  `started_at` datetime(3) NULL,
+  `finished_at` datetime(3) NULL,
+  `duration_ms` bigint,
+  PRIMARY KEY (`id`),
+  INDEX `idx_job_runs_job_name` (`job_name`)
+);
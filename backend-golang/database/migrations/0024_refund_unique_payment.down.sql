@@ -0,0 +1,3 @@
+ALTER TABLE `refunds`
+  ADD INDEX `idx_refunds_payment_id` (`payment_id`),
+  DROP INDEX `idx_refunds_payment_unique`;
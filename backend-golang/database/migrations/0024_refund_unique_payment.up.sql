@@ -0,0 +1,5 @@
+-- Satu refund per payment. Retry refund yang gagal memakai baris dan refund key yang sama,
+-- sehingga pembatalan bersamaan tidak bisa mengembalikan dana dua kali.
+ALTER TABLE `refunds`
+  ADD UNIQUE INDEX `idx_refunds_payment_unique` (`payment_id`),
+  DROP INDEX `idx_refunds_payment_id`;
@@ -0,0 +1,2 @@
+ALTER TABLE `ticket_types`
+  DROP COLUMN `access_code`;
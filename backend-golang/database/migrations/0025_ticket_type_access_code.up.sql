@@ -0,0 +1,8 @@
+-- Kode akses untuk tipe tiket hidden. Tiket hidden hanya bisa dipilih dengan kode ini,
+-- tiket hidden yang sudah ada mendapat kode acak.
+ALTER TABLE `ticket_types`
+  ADD COLUMN `access_code` varchar(50) NULL AFTER `visibility`;
+
+UPDATE `ticket_types`
+   SET `access_code` = UPPER(LEFT(REPLACE(UUID(), '-', ''), 10))
+ WHERE `visibility` = 'hidden';
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"os"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ConnectTest membuka koneksi ke database khusus test dari env TEST_DB_DSN lalu menjalankan migrasi.
+// Mengembalikan false jika TEST_DB_DSN tidak di-set, supaya test yang butuh database dilewati.
+// Contoh: TEST_DB_DSN="root:@tcp(127.0.0.1:3306)/db_golang_test?charset=utf8mb4&parseTime=True&loc=Local"
+func ConnectTest() (bool, error) {
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		return false, nil
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return false, err
+	}
+	DB = db
+
+	if err := MigrateUp(); err != nil {
+		return false, err
+	}
+	return true, grantSuperadminPermissions()
+}
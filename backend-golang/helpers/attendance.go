@@ -0,0 +1,206 @@
+package helpers
+
+import (
+	"errors"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionNotFound         = errors.New("sesi tidak ditemukan")
+	ErrNoActiveSession         = errors.New("tidak ada sesi yang sedang berlangsung")
+	ErrSessionAlreadyCheckedIn = errors.New("peserta sudah check-in di sesi ini")
+	ErrSessionNotCheckedIn     = errors.New("peserta belum check-in di sesi ini")
+	ErrSessionAlreadyOut       = errors.New("peserta sudah check-out dari sesi ini")
+)
+
+// AttendanceSummary merangkum kehadiran satu registrasi terhadap seluruh sesi event
+type AttendanceSummary struct {
+	RegistrationID      uint `json:"registration_id"`
+	TotalSessions       int  `json:"total_sessions"`
+	AttendedSessions    int  `json:"attended_sessions"`
+	Percentage          int  `json:"percentage"`
+	MinPercentage       int  `json:"min_percentage"`
+	CertificateEligible bool `json:"certificate_eligible"`
+}
+
+// GetEventSessions mengambil sesi event sesuai urutan jadwal
+func GetEventSessions(eventID uint) []models.EventSession {
+	var sessions []models.EventSession
+	database.DB.Where("event_id = ?", eventID).Order("start_time ASC, sort_order ASC, id ASC").Find(&sessions)
+	return sessions
+}
+
+// EventHasSessions mengecek apakah event memakai kehadiran per sesi
+func EventHasSessions(eventID uint) bool {
+	var count int64
+	database.DB.Model(&models.EventSession{}).Where("event_id = ?", eventID).Count(&count)
+	return count > 0
+}
+
+// ResolveEventSession menentukan sesi untuk check-in. Jika sessionID kosong dipilih sesi yang sedang
+// berlangsung pada waktu at (dibuka session_checkin_open_minutes sebelum mulai sampai sesi selesai).
+func ResolveEventSession(eventID uint, sessionID *uint, at time.Time) (*models.EventSession, error) {
+	var session models.EventSession
+	if sessionID != nil {
+		if err := database.DB.Where("id = ? AND event_id = ?", *sessionID, eventID).First(&session).Error; err != nil {
+			return nil, ErrSessionNotFound
+		}
+		return &session, nil
+	}
+
+	openBefore := time.Duration(GetSettingInt("session_checkin_open_minutes", 30)) * time.Minute
+	if err := database.DB.Where("event_id = ? AND start_time <= ? AND end_time >= ?", eventID, at.Add(openBefore), at).
+		Order("start_time ASC, id ASC").First(&session).Error; err != nil {
+		return nil, ErrNoActiveSession
+	}
+	return &session, nil
+}
+
+// RecordSessionCheckIn mencatat check-in registrasi di sebuah sesi dan menandai registrasi hadir
+// (status checked_in) pada check-in pertamanya. Jika sudah check-in, record yang ada dikembalikan
+// bersama ErrSessionAlreadyCheckedIn. Status registrasi harus sudah divalidasi pemanggil.
+func RecordSessionCheckIn(tx *gorm.DB, registration *models.Registration, session *models.EventSession, method string, at time.Time, recordedByID *uint, proofURL string) (*models.AttendanceRecord, error) {
+	var record models.AttendanceRecord
+	if err := tx.Where("registration_id = ? AND event_session_id = ?", registration.ID, session.ID).First(&record).Error; err == nil {
+		return &record, ErrSessionAlreadyCheckedIn
+	}
+
+	record = models.AttendanceRecord{
+		RegistrationID: registration.ID,
+		EventSessionID: session.ID,
+		EventID:        registration.EventID,
+		CheckInAt:      at,
+		Method:         method,
+		RecordedByID:   recordedByID,
+		ProofURL:       proofURL,
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		// Check-in bersamaan dari perangkat lain lebih dulu tersimpan
+		if IsDuplicateEntryError(err) {
+			var existing models.AttendanceRecord
+			if tx.Where("registration_id = ? AND event_session_id = ?", registration.ID, session.ID).First(&existing).Error == nil {
+				return &existing, ErrSessionAlreadyCheckedIn
+			}
+		}
+		return nil, err
+	}
+
+	if registration.Status != "checked_in" || registration.CheckedInAt == nil || at.Before(*registration.CheckedInAt) {
+		attendanceType := "offline"
+		if method == models.AttendanceMethodSelf {
+			attendanceType = "online"
+		}
+		updates := map[string]interface{}{
+			"status":        "checked_in",
+			"attendance":    true,
+			"checked_in_at": at,
+		}
+		if registration.AttendanceType == "" {
+			updates["attendance_type"] = attendanceType
+			registration.AttendanceType = attendanceType
+		}
+		if err := tx.Model(registration).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+		registration.Status = "checked_in"
+		registration.Attendance = true
+		registration.CheckedInAt = &at
+	}
+	return &record, nil
+}
+
+// RecordSessionCheckOut mencatat waktu check-out registrasi dari sebuah sesi
+func RecordSessionCheckOut(tx *gorm.DB, registrationID, sessionID uint, at time.Time) (*models.AttendanceRecord, error) {
+	var record models.AttendanceRecord
+	if err := tx.Where("registration_id = ? AND event_session_id = ?", registrationID, sessionID).First(&record).Error; err != nil {
+		return nil, ErrSessionNotCheckedIn
+	}
+	if record.CheckOutAt != nil {
+		return &record, ErrSessionAlreadyOut
+	}
+	if err := tx.Model(&record).Update("check_out_at", at).Error; err != nil {
+		return nil, err
+	}
+	record.CheckOutAt = &at
+	return &record, nil
+}
+
+// SyncRegistrationAttendance menyelaraskan Attendance/Status registrasi dengan record kehadiran sesinya
+// setelah panitia menghapus kehadiran sesi (registrasi kembali confirmed jika tidak hadir di sesi manapun)
+func SyncRegistrationAttendance(tx *gorm.DB, registration *models.Registration) error {
+	var records []models.AttendanceRecord
+	if err := tx.Where("registration_id = ?", registration.ID).Order("check_in_at ASC").Find(&records).Error; err != nil {
+		return err
+	}
+	if len(records) > 0 {
+		first := records[0].CheckInAt
+		registration.Status = "checked_in"
+		registration.Attendance = true
+		registration.CheckedInAt = &first
+	} else if registration.Status == "checked_in" {
+		registration.Status = "confirmed"
+		registration.Attendance = false
+		registration.CheckedInAt = nil
+	}
+	return tx.Model(registration).Updates(map[string]interface{}{
+		"status":        registration.Status,
+		"attendance":    registration.Attendance,
+		"checked_in_at": registration.CheckedInAt,
+	}).Error
+}
+
+// GetAttendanceSummaries menghitung kehadiran dan kelayakan sertifikat seluruh registrasi yang diberikan.
+// Event tanpa sesi dianggap satu sesi: hadir jika registrasi sudah check-in.
+func GetAttendanceSummaries(event models.Event, registrations []models.Registration) map[uint]AttendanceSummary {
+	var totalSessions int64
+	database.DB.Model(&models.EventSession{}).Where("event_id = ?", event.ID).Count(&totalSessions)
+
+	attended := map[uint]int{}
+	if totalSessions > 0 && len(registrations) > 0 {
+		ids := make([]uint, 0, len(registrations))
+		for _, reg := range registrations {
+			ids = append(ids, reg.ID)
+		}
+		var rows []struct {
+			RegistrationID uint
+			Total          int
+		}
+		database.DB.Model(&models.AttendanceRecord{}).
+			Select("registration_id, COUNT(*) AS total").
+			Where("registration_id IN ?", ids).
+			Group("registration_id").Scan(&rows)
+		for _, r := range rows {
+			attended[r.RegistrationID] = r.Total
+		}
+	}
+
+	summaries := make(map[uint]AttendanceSummary, len(registrations))
+	for _, reg := range registrations {
+		summary := AttendanceSummary{
+			RegistrationID: reg.ID,
+			TotalSessions:  int(totalSessions),
+			MinPercentage:  event.CertificateMinAttendance,
+		}
+		if totalSessions > 0 {
+			summary.AttendedSessions = attended[reg.ID]
+		} else {
+			summary.TotalSessions = 1
+			if reg.Attendance {
+				summary.AttendedSessions = 1
+			}
+		}
+		summary.Percentage = summary.AttendedSessions * 100 / summary.TotalSessions
+		summary.CertificateEligible = event.CertificateMinAttendance <= 0 || summary.Percentage >= event.CertificateMinAttendance
+		summaries[reg.ID] = summary
+	}
+	return summaries
+}
+
+// GetAttendanceSummary menghitung kehadiran dan kelayakan sertifikat satu registrasi
+func GetAttendanceSummary(event models.Event, registration models.Registration) AttendanceSummary {
+	return GetAttendanceSummaries(event, []models.Registration{registration})[registration.ID]
+}
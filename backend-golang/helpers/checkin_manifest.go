@@ -0,0 +1,84 @@
+package helpers
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"os"
+	"santrikoding/backend-api/config"
+	"santrikoding/backend-api/models"
+	"sync"
+	"time"
+)
+
+// CheckInManifest adalah daftar peserta event yang diunduh aplikasi scanner sebelum masuk venue,
+// sehingga QR tetap bisa dicocokkan saat koneksi putus. QR tidak disimpan mentah, hanya hash-nya.
+type CheckInManifest struct {
+	EventID     uint                   `json:"event_id"`
+	EventTitle  string                 `json:"event_title"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	ValidUntil  time.Time              `json:"valid_until"` // Batas berlaku QR tiket menurut jadwal event terbaru (lihat TicketQRExpiry)
+	Sessions    []models.EventSession  `json:"sessions"`    // Kosong untuk event tanpa sesi
+	Entries     []CheckInManifestEntry `json:"entries"`
+}
+
+type CheckInManifestEntry struct {
+	RegistrationID uint       `json:"registration_id"`
+	QRHash         string     `json:"qr_hash"`
+	AttendeeName   string     `json:"attendee_name"`
+	TicketType     string     `json:"ticket_type,omitempty"`
+	Status         string     `json:"status"`
+	CheckedInAt    *time.Time `json:"checked_in_at"`
+
+	AttendedSessionIDs []uint `json:"attended_session_ids,omitempty"` // Sesi yang sudah di-check-in (event multi-sesi)
+}
+
+// TicketQRHash mengembalikan SHA-256 (hex) dari isi QR tiket, sesuai qr_hash di manifest
+func TicketQRHash(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	manifestKeyOnce sync.Once
+	manifestKey     ed25519.PrivateKey
+)
+
+// loadCheckInManifestKey membaca kunci privat Ed25519 penandatangan manifest dari env CHECKIN_MANIFEST_KEY
+// (seed 32 byte dalam base64). Tanpa konfigurasi, seed diturunkan dari JWT_SECRET sehingga kunci tetap
+// sama setelah restart. Kunci publiknya dibagikan ke scanner (CheckInManifestPublicKey).
+func loadCheckInManifestKey() ed25519.PrivateKey {
+	manifestKeyOnce.Do(func() {
+		if encoded := os.Getenv("CHECKIN_MANIFEST_KEY"); encoded != "" {
+			seed, err := base64.StdEncoding.DecodeString(encoded)
+			if err == nil && len(seed) == ed25519.SeedSize {
+				manifestKey = ed25519.NewKeyFromSeed(seed)
+				return
+			}
+			log.Printf("CHECKIN_MANIFEST_KEY bukan seed Ed25519 base64 %d byte, memakai kunci turunan JWT_SECRET", ed25519.SeedSize)
+		}
+		mac := hmac.New(sha256.New, config.JWT_KEY)
+		mac.Write([]byte("checkin-manifest-ed25519"))
+		manifestKey = ed25519.NewKeyFromSeed(mac.Sum(nil))
+	})
+	return manifestKey
+}
+
+// CheckInManifestPublicKey mengembalikan id dan kunci publik Ed25519 (base64) untuk memverifikasi
+// tanda tangan manifest. Id kunci adalah 16 karakter awal SHA-256 kunci publik.
+func CheckInManifestPublicKey() (keyID string, publicKey string) {
+	public := loadCheckInManifestKey().Public().(ed25519.PublicKey)
+	sum := sha256.Sum256(public)
+	return hex.EncodeToString(sum[:])[:16], base64.StdEncoding.EncodeToString(public)
+}
+
+// SignCheckInManifest menandatangani manifest (JSON persis seperti yang dikirim) dengan Ed25519, sehingga
+// scanner bisa memverifikasinya dengan kunci publik tanpa memegang rahasia server.
+// Signature dalam base64url tanpa padding.
+func SignCheckInManifest(payload []byte) (keyID string, signature string) {
+	keyID, _ = CheckInManifestPublicKey()
+	return keyID, base64.RawURLEncoding.EncodeToString(ed25519.Sign(loadCheckInManifestKey(), payload))
+}
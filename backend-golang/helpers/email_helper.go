@@ -2,6 +2,8 @@ package helpers
 
 import (
 	"fmt"
+	"html"
+	"io"
 	"log"
 	"os"
 	"strconv"
@@ -134,6 +136,68 @@ func SendVerificationEmail(toEmail string, verificationCode string) error {
 	return nil
 }
 
+// SendPasswordResetEmail mengirimkan email berisi link reset password
+func SendPasswordResetEmail(toEmail string, userName string, resetURL string, expiryMinutes int) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic recovered in SendPasswordResetEmail: %v", r)
+		}
+	}()
+
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpPortStr := os.Getenv("SMTP_PORT")
+	smtpEmail := os.Getenv("SMTP_EMAIL")
+	smtpPassword := os.Getenv("SMTP_PASSWORD")
+
+	if smtpHost == "" || smtpPortStr == "" || smtpEmail == "" || smtpPassword == "" {
+		return fmt.Errorf("SMTP config belum lengkap")
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", smtpEmail)
+	m.SetHeader("To", toEmail)
+	m.SetHeader("Subject", "Reset Password - Nexus Event")
+
+	body := fmt.Sprintf(`
+		<!doctype html>
+		<html>
+		<head>
+		  <meta charset="UTF-8">
+		</head>
+		<body style="font-family: Arial, sans-serif; background:#f6f9fc; padding:20px;">
+		  <div style="max-width:520px; margin:0 auto; background:white; padding:20px 24px; border-radius:12px; border:1px solid #e5e7eb;">
+			<h2 style="margin-top:0; color:#111827;">Halo %s,</h2>
+			<p style="color:#374151;">Kami menerima permintaan untuk mereset password akun Nexus Event Anda. Klik tombol di bawah untuk membuat password baru:</p>
+			<div style="text-align:center; margin:24px 0;">
+			  <a href="%s" style="background:#1d4ed8; color:white; padding:12px 24px; border-radius:8px; text-decoration:none; font-weight:bold; display:inline-block;">Reset Password</a>
+			</div>
+			<p style="color:#374151; font-size:13px;">Atau salin link berikut ke browser:<br><span style="color:#1d4ed8; word-break:break-all;">%s</span></p>
+			<p style="color:#374151;">Link ini berlaku selama %d menit dan hanya bisa dipakai satu kali. Setelah password diganti, semua sesi login Anda akan dikeluarkan.</p>
+			<p style="color:#6b7280; font-size:12px; margin-top:24px;">Jika Anda tidak meminta reset password, abaikan email ini. Password Anda tidak akan berubah.</p>
+			<p style="color:#6b7280; font-size:12px;">Email ini dikirim otomatis, mohon tidak membalas.</p>
+		  </div>
+		</body>
+		</html>
+	`, html.EscapeString(userName), resetURL, resetURL, expiryMinutes)
+
+	m.SetBody("text/html", body)
+
+	port, err := strconv.Atoi(smtpPortStr)
+	if err != nil {
+		return fmt.Errorf("SMTP port invalid: %v", err)
+	}
+
+	d := gomail.NewDialer(smtpHost, port, smtpEmail, smtpPassword)
+
+	if err := d.DialAndSend(m); err != nil {
+		log.Printf("Gagal mengirim email reset password ke %s: %v\n", toEmail, err)
+		return err
+	}
+
+	log.Printf("Email reset password dikirim ke %s\n", toEmail)
+	return nil
+}
+
 // SendCertificateEmail mengirimkan email sertifikat dengan attachment
 func SendCertificateEmail(toEmail string, userName string, eventName string, eventDate string, certificateURL string) error {
 	defer func() {
@@ -215,4 +279,134 @@ func SendCertificateEmail(toEmail string, userName string, eventName string, eve
 
 	log.Printf("Email sertifikat dikirim ke %s\n", toEmail)
 	return nil
-}
\ No newline at end of file
+}
+// SendPaymentReceiptEmail mengirimkan email pembayaran berhasil dengan lampiran invoice PDF
+func SendPaymentReceiptEmail(toEmail string, userName string, eventName string, invoiceNumber string, amount int64, filename string, pdf []byte) error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic recovered in SendPaymentReceiptEmail: %v", r)
+		}
+	}()
+
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpPortStr := os.Getenv("SMTP_PORT")
+	smtpEmail := os.Getenv("SMTP_EMAIL")
+	smtpPassword := os.Getenv("SMTP_PASSWORD")
+
+	if smtpHost == "" || smtpPortStr == "" || smtpEmail == "" || smtpPassword == "" {
+		return fmt.Errorf("SMTP config belum lengkap")
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", smtpEmail)
+	m.SetHeader("To", toEmail)
+	m.SetHeader("Subject", fmt.Sprintf("Pembayaran Berhasil: %s", eventName))
+
+	body := fmt.Sprintf(`
+		<!doctype html>
+		<html>
+		<head>
+		  <meta charset="UTF-8">
+		</head>
+		<body style="font-family: Arial, sans-serif; background:#f6f9fc; padding:20px;">
+		  <div style="max-width:520px; margin:0 auto; background:white; padding:20px 24px; border-radius:12px; border:1px solid #e5e7eb;">
+			<h2 style="margin-top:0; color:#111827;">Halo %s,</h2>
+			<p style="color:#374151;">Pembayaran kamu telah <strong>berhasil dikonfirmasi</strong>. Terima kasih!</p>
+			<div style="background:#f3f4f6; padding:12px 14px; border-radius:10px; margin:16px 0;">
+			  <p style="margin:4px 0; color:#111827;"><strong>Event:</strong> %s</p>
+			  <p style="margin:4px 0; color:#111827;"><strong>No. Invoice:</strong> %s</p>
+			  <p style="margin:4px 0; color:#111827;"><strong>Total:</strong> %s</p>
+			</div>
+			<p style="color:#374151;">Invoice terlampir pada email ini dan dapat diunduh kembali melalui halaman Tiket Saya.</p>
+			<p style="color:#6b7280; font-size:12px;">Email ini dikirim otomatis, mohon tidak membalas.</p>
+		  </div>
+		</body>
+		</html>
+	`, html.EscapeString(userName), html.EscapeString(eventName), html.EscapeString(invoiceNumber), FormatRupiah(amount))
+
+	m.SetBody("text/html", body)
+	m.Attach(filename, gomail.SetCopyFunc(func(w io.Writer) error {
+		_, err := w.Write(pdf)
+		return err
+	}), gomail.SetHeader(map[string][]string{"Content-Type": {"application/pdf"}}))
+
+	port, err := strconv.Atoi(smtpPortStr)
+	if err != nil {
+		return fmt.Errorf("SMTP port invalid: %v", err)
+	}
+
+	d := gomail.NewDialer(smtpHost, port, smtpEmail, smtpPassword)
+
+	if err := d.DialAndSend(m); err != nil {
+		log.Printf("Gagal mengirim email invoice ke %s: %v\n", toEmail, err)
+		return err
+	}
+
+	log.Printf("Email invoice %s dikirim ke %s\n", invoiceNumber, toEmail)
+	return nil
+}
+
+// SendGuestTicketEmail mengirimkan tiket ke peserta tamu (tanpa akun) yang didaftarkan lewat pendaftaran grup
+func SendGuestTicketEmail(toEmail string, guestName string, registrantName string, eventName string, eventDate string, eventLocation string, ticketCode string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic recovered in SendGuestTicketEmail: %v", r)
+		}
+	}()
+
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpPortStr := os.Getenv("SMTP_PORT")
+	smtpEmail := os.Getenv("SMTP_EMAIL")
+	smtpPassword := os.Getenv("SMTP_PASSWORD")
+
+	if smtpHost == "" || smtpPortStr == "" || smtpEmail == "" || smtpPassword == "" {
+		log.Println("SMTP config belum lengkap, email tidak dikirim")
+		return
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", smtpEmail)
+	m.SetHeader("To", toEmail)
+	m.SetHeader("Subject", fmt.Sprintf("Tiket Event: %s", eventName))
+
+	body := fmt.Sprintf(`
+		<!doctype html>
+		<html>
+		<head>
+		  <meta charset="UTF-8">
+		</head>
+		<body style="font-family: Arial, sans-serif; background:#f6f9fc; padding:20px;">
+		  <div style="max-width:520px; margin:0 auto; background:white; padding:20px 24px; border-radius:12px; border:1px solid #e5e7eb;">
+			<h2 style="margin-top:0; color:#111827;">Halo %s,</h2>
+			<p style="color:#374151;">Kamu telah didaftarkan oleh <strong>%s</strong> dan pendaftarannya sudah <strong>dikonfirmasi</strong>. Berikut detail event:</p>
+			<div style="background:#f3f4f6; padding:12px 14px; border-radius:10px; margin:16px 0;">
+			  <p style="margin:4px 0; color:#111827;"><strong>Event:</strong> %s</p>
+			  <p style="margin:4px 0; color:#111827;"><strong>Tanggal:</strong> %s</p>
+			  <p style="margin:4px 0; color:#111827;"><strong>Lokasi:</strong> %s</p>
+			</div>
+			<p style="color:#374151;">Tunjukkan kode tiket berikut kepada panitia saat check-in:</p>
+			<p style="font-size:18px; font-weight:bold; letter-spacing:1px; color:#111827; background:#f3f4f6; padding:12px; border-radius:10px; text-align:center;">%s</p>
+			<p style="color:#6b7280; font-size:12px;">Email ini dikirim otomatis, mohon tidak membalas.</p>
+		  </div>
+		</body>
+		</html>
+	`, html.EscapeString(guestName), html.EscapeString(registrantName), html.EscapeString(eventName),
+		html.EscapeString(eventDate), html.EscapeString(eventLocation), html.EscapeString(ticketCode))
+
+	m.SetBody("text/html", body)
+
+	port, err := strconv.Atoi(smtpPortStr)
+	if err != nil {
+		log.Printf("SMTP port invalid: %v\n", err)
+		return
+	}
+
+	d := gomail.NewDialer(smtpHost, port, smtpEmail, smtpPassword)
+
+	if err := d.DialAndSend(m); err != nil {
+		log.Printf("Gagal mengirim email ke %s: %v\n", toEmail, err)
+		return
+	}
+
+	log.Printf("Email tiket tamu dikirim ke %s\n", toEmail)
+}
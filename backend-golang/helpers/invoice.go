@@ -0,0 +1,231 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvoiceNotAvailable dikembalikan jika registrasi belum punya pembayaran lunas
+var ErrInvoiceNotAvailable = errors.New("registrasi belum memiliki pembayaran lunas")
+
+// EnsureInvoice mengambil invoice sebuah registrasi, atau membuatnya dengan nomor urut
+// berikutnya jika registrasi sudah punya payment lunas. Aman dipanggil berulang kali.
+func EnsureInvoice(registrationID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	if err := database.DB.Where("registration_id = ?", registrationID).First(&invoice).Error; err == nil {
+		return &invoice, nil
+	}
+
+	payment, ok := GetPaidPayment(registrationID)
+	if !ok || payment.PaidAt == nil {
+		return nil, ErrInvoiceNotAvailable
+	}
+
+	var registration models.Registration
+	if err := database.DB.Preload("User").Preload("Event").Preload("TicketType").First(&registration, registrationID).Error; err != nil {
+		return nil, err
+	}
+	eventTitle := registration.Event.Title
+	if registration.TicketType != nil {
+		eventTitle += " (" + registration.TicketType.Name + ")"
+	}
+
+	organizer := GetSetting("organizer_name")
+	if organizer == "" {
+		organizer = GetSetting("app_name")
+	}
+	invoice = models.Invoice{
+		Year:             payment.PaidAt.Year(),
+		RegistrationID:   registration.ID,
+		PaymentID:        payment.ID,
+		UserID:           registration.UserID,
+		EventID:          registration.EventID,
+		BilledName:       registration.User.Name,
+		BilledEmail:      registration.User.Email,
+		EventTitle:       eventTitle,
+		OrderID:          payment.OrderID,
+		Amount:           payment.Amount,
+		PaymentMethod:    payment.PaymentType,
+		PaidAt:           *payment.PaidAt,
+		OrganizerName:    organizer,
+		OrganizerAddress: GetSetting("organizer_address"),
+		OrganizerEmail:   GetSetting("organizer_email"),
+		OrganizerPhone:   GetSetting("organizer_phone"),
+	}
+	prefix := GetSetting("invoice_prefix")
+	if prefix == "" {
+		prefix = "INV"
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris counter tahun ini supaya nomor urut tidak dobel antar request
+		counter := models.InvoiceCounter{Year: invoice.Year}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&counter, "year = ?", invoice.Year).Error; err != nil {
+			return err
+		}
+		counter.LastNumber++
+		if err := tx.Model(&counter).Where("year = ?", counter.Year).Update("last_number", counter.LastNumber).Error; err != nil {
+			return err
+		}
+
+		invoice.Sequence = counter.LastNumber
+		invoice.Number = fmt.Sprintf("%s/%d/%06d", prefix, invoice.Year, invoice.Sequence)
+		return tx.Create(&invoice).Error
+	})
+	if err != nil {
+		// Request lain membuat invoice untuk registrasi yang sama lebih dulu
+		if IsDuplicateEntryError(err) {
+			if findErr := database.DB.Where("registration_id = ?", registrationID).First(&invoice).Error; findErr == nil {
+				return &invoice, nil
+			}
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// MarkInvoiceEmailed mencatat invoice sudah dikirim lewat email
+func MarkInvoiceEmailed(invoiceID uint) {
+	database.DB.Model(&models.Invoice{}).Where("id = ?", invoiceID).Update("email_sent_at", time.Now())
+}
+
+// InvoiceFilename mengembalikan nama file PDF invoice (INV/2026/000001 -> INV-2026-000001.pdf)
+func InvoiceFilename(invoice *models.Invoice) string {
+	return strings.ReplaceAll(invoice.Number, "/", "-") + ".pdf"
+}
+
+// FormatRupiah memformat nominal menjadi "Rp 150.000"
+func FormatRupiah(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	var out []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, '.')
+		}
+		out = append(out, digits[i])
+	}
+	return sign + "Rp " + string(out)
+}
+
+// paymentMethodLabel menerjemahkan payment_type provider ke label yang mudah dibaca
+func paymentMethodLabel(paymentType string) string {
+	labels := map[string]string{
+		"bank_transfer":   "Transfer Bank (Virtual Account)",
+		"echannel":        "Mandiri Bill Payment",
+		"credit_card":     "Kartu Kredit",
+		"gopay":           "GoPay",
+		"shopeepay":       "ShopeePay",
+		"qris":            "QRIS",
+		"cstore":          "Gerai Retail",
+		"fake":            "Simulasi",
+		"manual_transfer": "Transfer Bank Manual",
+	}
+	if label, ok := labels[paymentType]; ok {
+		return label
+	}
+	if paymentType == "" {
+		return "-"
+	}
+	return paymentType
+}
+
+// RenderInvoicePDF membuat PDF kuitansi pembayaran dari data invoice
+func RenderInvoicePDF(invoice *models.Invoice) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // cp1252, agar karakter non-ASCII tetap tampil
+	pdf.SetTitle("Invoice "+invoice.Number, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	// Header penyelenggara
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(110, 8, tr(invoice.OrganizerName), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(60, 8, "INVOICE", "", 1, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	for _, line := range []string{invoice.OrganizerAddress, invoice.OrganizerEmail, invoice.OrganizerPhone} {
+		if line != "" {
+			pdf.MultiCell(110, 4.5, tr(line), "", "L", false)
+		}
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(6)
+
+	// Informasi invoice dan pembeli
+	half := 85.0
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(half, 6, "Ditagihkan kepada", "", 0, "L", false, 0, "")
+	pdf.CellFormat(half, 6, "Detail invoice", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	left := []string{invoice.BilledName, invoice.BilledEmail}
+	right := []string{
+		"No. Invoice: " + invoice.Number,
+		"Order ID: " + invoice.OrderID,
+		"Tanggal bayar: " + invoice.PaidAt.Format("02 Jan 2006 15:04"),
+	}
+	for i := 0; i < len(right); i++ {
+		l := ""
+		if i < len(left) {
+			l = left[i]
+		}
+		pdf.CellFormat(half, 5.5, tr(l), "", 0, "L", false, 0, "")
+		pdf.CellFormat(half, 5.5, tr(right[i]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	// Rincian item
+	pdf.SetFillColor(240, 240, 240)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(110, 8, "Deskripsi", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(20, 8, "Qty", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(40, 8, "Jumlah", "1", 1, "R", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(110, 8, tr("Tiket: "+invoice.EventTitle), "1", 0, "L", false, 0, "")
+	pdf.CellFormat(20, 8, "1", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 8, FormatRupiah(invoice.Amount), "1", 1, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(130, 8, "Total dibayar", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(40, 8, FormatRupiah(invoice.Amount), "1", 1, "R", false, 0, "")
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, tr("Metode pembayaran: "+paymentMethodLabel(invoice.PaymentMethod)), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetTextColor(22, 128, 61)
+	pdf.CellFormat(0, 8, "LUNAS", "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+
+	pdf.Ln(10)
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.SetTextColor(120, 120, 120)
+	pdf.MultiCell(0, 4, "Dokumen ini dibuat otomatis oleh sistem dan sah tanpa tanda tangan.", "", "L", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,25 +1,26 @@
 package helpers
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"santrikoding/backend-api/config"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Nilai secret diambil dari environment variable JWT_SECRET
-var jwtKey = []byte(config.GetEnv("JWT_SECRET", "secret_key"))
+// GenerateToken membuat access token untuk user pada sesi tertentu.
+// Durasi mengikuti setting jwt_expiry_hours (default 24 jam).
+func GenerateToken(userID uint, sessionID uint) (string, time.Time) {
 
-// UBAH PARAMETER: dari (username string) menjadi (userID uint)
-func GenerateToken(userID uint) string {
+	expirationTime := time.Now().Add(time.Duration(GetSettingInt("jwt_expiry_hours", 24)) * time.Hour)
 
-	// Mengatur waktu kedaluwarsa token (30 menit)
-	expirationTime := time.Now().Add(30 * time.Minute)
-
-	// GANTI KE MapClaims
-	// Agar "sub" bisa menyimpan Angka (ID), bukan String
+	// Pakai MapClaims agar "sub" bisa menyimpan Angka (ID), bukan String
 	claims := jwt.MapClaims{
-		"sub": userID,                             // Simpan ID User (Angka)
+		"sub": userID,                             // ID User
+		"sid": sessionID,                          // ID Sesi, dicek ke tabel sessions
+		"iat": jwt.NewNumericDate(time.Now()),     // Waktu dibuat
 		"exp": jwt.NewNumericDate(expirationTime), // Expired time
 	}
 
@@ -29,5 +30,20 @@ func GenerateToken(userID uint) string {
 	// Tanda tangani token
 	tokenString, _ := token.SignedString(config.JWT_KEY)
 
-	return tokenString
+	return tokenString, expirationTime
+}
+
+// GenerateRandomToken membuat token acak 256-bit (refresh token, reset password); hanya hash-nya yang disimpan di database
+func GenerateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken menghasilkan SHA-256 hex dari token untuk disimpan/dicari di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
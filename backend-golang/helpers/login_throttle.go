@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"fmt"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scope pencatatan login gagal
+const (
+	ThrottleScopeAccount = "account"
+	ThrottleScopeIP      = "ip"
+)
+
+// AccountThrottleKey membuat key throttle akun. User yang dikenal memakai ID supaya login
+// via email maupun username dihitung bersama; identifier tak dikenal tetap dihitung
+// agar respons tidak membedakan akun yang ada dan tidak ada.
+func AccountThrottleKey(userID uint, identifier string) string {
+	if userID != 0 {
+		return fmt.Sprintf("id:%d", userID)
+	}
+	return "name:" + truncate(strings.ToLower(strings.TrimSpace(identifier)), 180)
+}
+
+// GetLoginLock mengembalikan waktu berakhirnya lockout jika key sedang dikunci
+func GetLoginLock(scope, key string) (time.Time, bool) {
+	var throttle models.LoginThrottle
+	if err := database.DB.Where("scope = ? AND `key` = ?", scope, key).First(&throttle).Error; err != nil {
+		return time.Time{}, false
+	}
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(time.Now()) {
+		return *throttle.LockedUntil, true
+	}
+	return time.Time{}, false
+}
+
+// RegisterLoginFailure menambah hitungan gagal. Hitungan direset jika kegagalan terakhir
+// lebih lama dari window. Jika mencapai maxAttempts, key dikunci selama window dan
+// waktu berakhirnya lockout dikembalikan.
+func RegisterLoginFailure(scope, key string, userID *uint, maxAttempts int, window time.Duration) (*time.Time, error) {
+	var lockedUntil *time.Time
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Pastikan baris ada, lalu kunci untuk update agar request paralel tidak saling menimpa
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginThrottle{Scope: scope, Key: key, UserID: userID}).Error; err != nil {
+			return err
+		}
+
+		var throttle models.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND `key` = ?", scope, key).First(&throttle).Error; err != nil {
+			return err
+		}
+
+		if throttle.LastFailedAt == nil || now.Sub(*throttle.LastFailedAt) > window {
+			throttle.FailedCount = 0
+		}
+		throttle.FailedCount++
+		throttle.LastFailedAt = &now
+
+		if throttle.FailedCount >= maxAttempts {
+			until := now.Add(window)
+			throttle.LockedUntil = &until
+			throttle.FailedCount = 0
+			lockedUntil = &until
+		}
+
+		return tx.Model(&throttle).Updates(map[string]interface{}{
+			"failed_count":   throttle.FailedCount,
+			"last_failed_at": throttle.LastFailedAt,
+			"locked_until":   throttle.LockedUntil,
+		}).Error
+	})
+
+	return lockedUntil, err
+}
+
+// ClearLoginFailures menghapus hitungan gagal dan lockout (login berhasil / dibuka admin)
+func ClearLoginFailures(scope, key string) int64 {
+	result := database.DB.Where("scope = ? AND `key` = ?", scope, key).Delete(&models.LoginThrottle{})
+	return result.RowsAffected
+}
+
+// CleanupLoginThrottles menghapus catatan login gagal yang sudah tidak relevan (lebih dari 1 hari)
+func CleanupLoginThrottles() error {
+	cutoff := time.Now().AddDate(0, 0, -1)
+	return database.DB.
+		Where("(last_failed_at IS NULL OR last_failed_at < ?) AND (locked_until IS NULL OR locked_until < ?)", cutoff, cutoff).
+		Delete(&models.LoginThrottle{}).Error
+}
+
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+// CompareDummyPassword menjalankan bcrypt terhadap hash palsu saat user tidak ditemukan,
+// supaya waktu respons tidak membocorkan apakah username/email terdaftar.
+func CompareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+	})
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
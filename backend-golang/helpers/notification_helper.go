@@ -5,6 +5,7 @@ import (
 	"log"
 	"santrikoding/backend-api/database"
 	"santrikoding/backend-api/models"
+	"time"
 )
 
 // GetCommitteeUserIDs mendapatkan list user ID panitia dari suatu event
@@ -161,6 +162,129 @@ func NotifyPaymentPending(userID uint, eventTitle string, eventSlug string) {
 	)
 }
 
+// NotifyRegistrationCancelled - Notifikasi pendaftaran dibatalkan
+func NotifyRegistrationCancelled(userID uint, eventTitle string, refundAmount int64) {
+	message := fmt.Sprintf("Pendaftaran Anda untuk \"%s\" telah dibatalkan.", eventTitle)
+	if refundAmount > 0 {
+		message += fmt.Sprintf(" Refund sebesar Rp %d sedang diproses.", refundAmount)
+	}
+	CreateNotification(
+		userID,
+		models.NotifRegistrationCancelled,
+		"Pendaftaran Dibatalkan",
+		message,
+		"event",
+		0,
+		"/my-tickets",
+	)
+}
+
+// NotifyRefundProcessed - Notifikasi dana tiket sudah dikembalikan (amount 0 = nominal tidak diketahui)
+func NotifyRefundProcessed(userID uint, eventTitle string, amount int64) {
+	message := fmt.Sprintf("Dana tiket \"%s\" telah dikembalikan ke metode pembayaran Anda.", eventTitle)
+	if amount > 0 {
+		message = fmt.Sprintf("Refund sebesar Rp %d untuk \"%s\" telah dikembalikan ke metode pembayaran Anda.", amount, eventTitle)
+	}
+	CreateNotification(
+		userID,
+		models.NotifRefundProcessed,
+		"Refund Berhasil 💸",
+		message,
+		"event",
+		0,
+		"/my-tickets",
+	)
+}
+
+// NotifyPaymentProofRejected - Notifikasi bukti transfer ditolak bendahara
+func NotifyPaymentProofRejected(userID uint, eventTitle string, reason string) {
+	CreateNotification(
+		userID,
+		models.NotifPaymentProofRejected,
+		"Bukti Transfer Ditolak",
+		fmt.Sprintf("Bukti transfer untuk \"%s\" ditolak. Alasan: %s. Silakan upload ulang bukti transfer yang benar.", eventTitle, reason),
+		"event",
+		0,
+		"/my-tickets",
+	)
+}
+
+// NotifyWaitlistOffer - Notifikasi kursi dari waitlist tersedia dan harus diklaim sebelum batas waktu
+func NotifyWaitlistOffer(userID uint, eventTitle string, eventSlug string, expiresAt time.Time) {
+	CreateNotification(
+		userID,
+		models.NotifWaitlistOffer,
+		"Kursi Tersedia! 🎟️",
+		fmt.Sprintf("Ada kursi kosong untuk \"%s\". Segera daftar sebelum %s atau kursi akan ditawarkan ke antrian berikutnya.",
+			eventTitle, expiresAt.Format("02 Jan 2006 15:04")),
+		"event",
+		0,
+		fmt.Sprintf("/event/%s", eventSlug),
+	)
+}
+
+// NotifyWaitlistExpired - Notifikasi batas waktu klaim kursi waitlist sudah lewat
+func NotifyWaitlistExpired(userID uint, eventTitle string) {
+	CreateNotification(
+		userID,
+		models.NotifWaitlistExpired,
+		"Tawaran Kursi Berakhir",
+		fmt.Sprintf("Batas waktu pendaftaran dari waitlist \"%s\" sudah lewat. Kursi ditawarkan ke antrian berikutnya.", eventTitle),
+		"event",
+		0,
+		"/my-tickets",
+	)
+}
+
+// NotifyTransferRequested - Notifikasi ke penerima bahwa ada tiket yang ditransfer kepadanya
+func NotifyTransferRequested(userID uint, fromName string, eventTitle string, transferID uint, expiresAt time.Time) {
+	CreateNotification(
+		userID,
+		models.NotifTransferRequested,
+		"Transfer Tiket 🎟️",
+		fmt.Sprintf("%s ingin mentransfer tiket \"%s\" kepada Anda. Terima sebelum %s dan lengkapi form pendaftarannya.",
+			fromName, eventTitle, expiresAt.Format("02 Jan 2006 15:04")),
+		"registration_transfer",
+		transferID,
+		"/my-tickets",
+	)
+}
+
+// NotifyTransferAccepted - Notifikasi transfer tiket selesai (ke pemilik lama dan penerima)
+func NotifyTransferAccepted(fromUserID uint, toUserID uint, fromName string, toName string, eventTitle string) {
+	CreateNotification(
+		fromUserID,
+		models.NotifTransferAccepted,
+		"Transfer Tiket Diterima",
+		fmt.Sprintf("%s telah menerima transfer tiket \"%s\". Tiket tersebut tidak lagi berlaku untuk akun Anda.", toName, eventTitle),
+		"event",
+		0,
+		"/my-tickets",
+	)
+	CreateNotification(
+		toUserID,
+		models.NotifTransferAccepted,
+		"Tiket Diterima ✅",
+		fmt.Sprintf("Tiket \"%s\" dari %s sekarang menjadi milik Anda. Lihat QR tiket baru di halaman Tiket Saya.", eventTitle, fromName),
+		"event",
+		0,
+		"/my-tickets",
+	)
+}
+
+// NotifyTransferDeclined - Notifikasi ke pemilik tiket bahwa penerima menolak transfer
+func NotifyTransferDeclined(userID uint, toName string, eventTitle string) {
+	CreateNotification(
+		userID,
+		models.NotifTransferDeclined,
+		"Transfer Tiket Ditolak",
+		fmt.Sprintf("%s menolak transfer tiket \"%s\". Tiket tetap milik Anda.", toName, eventTitle),
+		"event",
+		0,
+		"/my-tickets",
+	)
+}
+
 // ========== NOTIFIKASI UNTUK PANITIA ==========
 
 // NotifyNewRegistration - Notifikasi ada pendaftar baru (ke semua panitia event)
@@ -184,6 +308,56 @@ func NotifyNewRegistration(eventID uint, eventTitle string, participantName stri
 	)
 }
 
+// NotifyTicketTransferred - Notifikasi tiket peserta dipindahkan ke user lain (ke semua panitia event)
+func NotifyTicketTransferred(eventID uint, eventTitle string, fromName string, toName string) {
+	committeeUserIDs := GetCommitteeUserIDs(eventID)
+	if len(committeeUserIDs) == 0 {
+		return
+	}
+
+	var event models.Event
+	database.DB.First(&event, eventID)
+
+	CreateNotificationBulk(
+		committeeUserIDs,
+		models.NotifTicketTransfer,
+		"Transfer Tiket 🔁",
+		fmt.Sprintf("Tiket %s untuk event \"%s\" telah ditransfer ke %s.", fromName, eventTitle, toName),
+		"event",
+		eventID,
+		fmt.Sprintf("/dashboard/event/%s", event.Slug),
+	)
+}
+
+// NotifyPaymentProofUploaded - Notifikasi bukti transfer baru ke panitia yang bisa memverifikasi pembayaran
+func NotifyPaymentProofUploaded(eventID uint, eventTitle string, participantName string, proofID uint) {
+	var members []models.CommitteeMember
+	database.DB.Where("event_id = ?", eventID).Find(&members)
+
+	var userIDs []uint
+	for _, m := range members {
+		for _, perm := range PositionPermissions(m.Position) {
+			if perm == "payment.verify" {
+				userIDs = append(userIDs, m.UserID)
+				break
+			}
+		}
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	CreateNotificationBulk(
+		userIDs,
+		models.NotifPaymentProof,
+		"Bukti Transfer Baru 🧾",
+		fmt.Sprintf("%s mengupload bukti transfer untuk event \"%s\".", participantName, eventTitle),
+		"payment_proof",
+		proofID,
+		"/dashboard/payments/proofs",
+	)
+}
+
 // NotifyTaskAssigned - Notifikasi ditugaskan task
 func NotifyTaskAssigned(userID uint, taskTitle string, eventTitle string, eventSlug string) {
 	CreateNotification(
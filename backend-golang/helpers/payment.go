@@ -0,0 +1,241 @@
+package helpers
+
+import (
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentUpdate adalah data status terbaru dari provider untuk sebuah payment
+type PaymentUpdate struct {
+	Status         string // Status ternormalisasi (models.PaymentStatus*)
+	ProviderStatus string // Status mentah dari provider
+	PaymentType    string
+	TransactionID  string
+	Source         string // notification, status_check, ...
+	Note           string
+	Raw            string // Payload / respons mentah dari provider
+}
+
+// PaymentTransition adalah hasil ApplyPaymentStatus
+type PaymentTransition struct {
+	From                  string
+	To                    string
+	Changed               bool // Status payment berubah
+	RegistrationConfirmed bool // Registrasi baru saja dikonfirmasi karena payment lunas
+	RegistrationRejected  bool // Registrasi ditolak karena payment terakhirnya gagal/expired
+	RegistrationRefunded  bool // Registrasi menjadi refunded karena dana payment dikembalikan
+
+	// Refund pending yang dibuat untuk pelunasan registrasi yang sudah tidak aktif,
+	// harus dieksekusi pemanggil ke provider (transfer manual menunggu bendahara)
+	Refund *models.Refund
+}
+
+// isPaymentFailure bernilai true untuk status akhir yang tidak menghasilkan pembayaran
+func isPaymentFailure(status string) bool {
+	return status == models.PaymentStatusFailed ||
+		status == models.PaymentStatusExpired ||
+		status == models.PaymentStatusCancelled
+}
+
+// CreatePayment menyimpan payment baru beserta riwayat status awalnya di dalam transaksi tx milik
+// pemanggil (misal transaksi yang memegang lock baris registrasi), tanpa membuka transaksi bersarang.
+func CreatePayment(tx *gorm.DB, payment *models.Payment, source string) error {
+	if err := tx.Create(payment).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.PaymentStatusHistory{
+		PaymentID: payment.ID,
+		ToStatus:  payment.Status,
+		Source:    source,
+	}).Error
+}
+
+// isRegistrationInactive bernilai true untuk registrasi yang sudah dibatalkan peserta/panitia
+func isRegistrationInactive(status string) bool {
+	for _, s := range models.InactiveRegistrationStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyPaymentStatus memperbarui status payment dan registrasinya dalam satu transaksi.
+// Payment yang sudah lunas hanya bisa berubah menjadi refunded. Registrasi dikonfirmasi saat
+// payment lunas, dan ditolak hanya jika payment yang gagal adalah percobaan terakhirnya
+// (order lama yang expired tidak membatalkan percobaan yang lebih baru). Registrasi yang sudah
+// dibatalkan tidak dikonfirmasi ulang; pelunasan yang masuk setelahnya (misal settlement terlambat)
+// dicatat sebagai refund penuh yang masih pending di PaymentTransition.Refund. Registrasi yang
+// sudah ditolak (kursinya dilepas) dikonfirmasi ulang hanya jika kuota masih cukup; jika penuh,
+// registrasi tetap ditolak dan pelunasannya juga dicatat sebagai refund penuh. Begitu pula pelunasan
+// kedua untuk registrasi yang sudah lunas lewat payment lain (tagihan ganda). Registrasi yang sudah
+// checked_in tidak pernah diturunkan statusnya.
+func ApplyPaymentStatus(paymentID uint, update PaymentUpdate) (*PaymentTransition, error) {
+	result := &PaymentTransition{}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, paymentID).Error; err != nil {
+			return err
+		}
+		result.From = payment.Status
+		result.To = payment.Status
+
+		fields := map[string]interface{}{
+			"provider_status": update.ProviderStatus,
+			"raw_response":    update.Raw,
+		}
+		if update.PaymentType != "" {
+			fields["payment_type"] = update.PaymentType
+		}
+		if update.TransactionID != "" {
+			fields["transaction_id"] = update.TransactionID
+		}
+
+		allowed := update.Status != payment.Status &&
+			(payment.Status != models.PaymentStatusPaid || update.Status == models.PaymentStatusRefunded)
+		if allowed {
+			now := time.Now()
+			fields["status"] = update.Status
+			if update.Status == models.PaymentStatusPaid {
+				fields["paid_at"] = now
+			}
+			result.To = update.Status
+			result.Changed = true
+		}
+
+		if err := tx.Model(&payment).Updates(fields).Error; err != nil {
+			return err
+		}
+		if !result.Changed {
+			return nil
+		}
+
+		history := models.PaymentStatusHistory{
+			PaymentID:      payment.ID,
+			FromStatus:     result.From,
+			ToStatus:       result.To,
+			ProviderStatus: update.ProviderStatus,
+			Source:         update.Source,
+			Note:           truncate(update.Note, 255),
+			RawPayload:     update.Raw,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+
+		var registration models.Registration
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&registration, payment.RegistrationID).Error; err != nil {
+			return err
+		}
+
+		// Percobaan pembayaran lain yang sudah lunas: pelunasan ini adalah tagihan ganda
+		var otherPaid int64
+		if result.To == models.PaymentStatusPaid || result.To == models.PaymentStatusRefunded {
+			tx.Model(&models.Payment{}).
+				Where("registration_id = ? AND id <> ? AND status = ?", registration.ID, payment.ID, models.PaymentStatusPaid).
+				Count(&otherPaid)
+		}
+
+		switch {
+		case result.To == models.PaymentStatusRefunded && otherPaid == 0 &&
+			registration.Status != "refunded" && registration.Status != "rejected":
+			if err := tx.Model(&registration).Update("status", "refunded").Error; err != nil {
+				return err
+			}
+			if err := SyncGroupMembers(tx, &registration, map[string]interface{}{"status": "refunded"}); err != nil {
+				return err
+			}
+			result.RegistrationRefunded = true
+		case isPaymentFailure(result.To) && registration.Status == "pending":
+			var newer int64
+			tx.Model(&models.Payment{}).
+				Where("registration_id = ? AND id > ?", registration.ID, payment.ID).
+				Count(&newer)
+			if newer > 0 {
+				return nil
+			}
+			if err := tx.Model(&registration).Update("status", "rejected").Error; err != nil {
+				return err
+			}
+			if err := SyncGroupMembers(tx, &registration, map[string]interface{}{"status": "rejected"}); err != nil {
+				return err
+			}
+			result.RegistrationRejected = true
+		case result.To != models.PaymentStatusPaid:
+			// Kasus di bawah hanya untuk pelunasan
+		case isRegistrationInactive(registration.Status):
+			return refundUnusedPayment(tx, payment, result, "Pembayaran diterima setelah registrasi dibatalkan")
+		case otherPaid > 0:
+			return refundUnusedPayment(tx, payment, result, "Pembayaran ganda: registrasi sudah dibayar dengan transaksi lain")
+		case registration.Status == "rejected":
+			// Kursi registrasi yang ditolak sudah dilepas: konfirmasi ulang hanya jika kuota masih ada
+			ids, err := ReclaimSeats(tx, &registration)
+			if err == ErrEventFull || err == ErrTicketSoldOut {
+				return refundUnusedPayment(tx, payment, result, "Pembayaran diterima setelah kuota event penuh")
+			}
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Registration{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+				"status":  "confirmed",
+				"paid_at": time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+			result.RegistrationConfirmed = true
+		case registration.Status == "pending":
+			confirmed := map[string]interface{}{
+				"status":  "confirmed",
+				"paid_at": time.Now(),
+			}
+			if err := tx.Model(&registration).Updates(confirmed).Error; err != nil {
+				return err
+			}
+			if err := SyncGroupMembers(tx, &registration, confirmed); err != nil {
+				return err
+			}
+			result.RegistrationConfirmed = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// refundUnusedPayment mencatat refund penuh (pending) untuk pelunasan yang tidak dipakai registrasinya:
+// registrasi sudah dibatalkan, kuotanya habis, atau sudah lunas lewat payment lain. Refund dieksekusi
+// pemanggil ApplyPaymentStatus lewat PaymentTransition.Refund. Payment yang sudah punya refund dilewati.
+func refundUnusedPayment(tx *gorm.DB, payment models.Payment, result *PaymentTransition, reason string) error {
+	var refunded int64
+	if tx.Model(&models.Refund{}).Where("payment_id = ?", payment.ID).Count(&refunded); refunded > 0 {
+		return nil
+	}
+	refund := &models.Refund{
+		PaymentID:      payment.ID,
+		RegistrationID: payment.RegistrationID,
+		Amount:         payment.Amount,
+		Percentage:     100,
+		Reason:         reason,
+	}
+	if err := createRefund(tx, refund); err != nil {
+		return err
+	}
+	result.Refund = refund
+	return nil
+}
+
+// GetLatestPayment mengambil percobaan pembayaran terbaru sebuah registrasi
+func GetLatestPayment(registrationID uint) (*models.Payment, bool) {
+	var payment models.Payment
+	if err := database.DB.Where("registration_id = ?", registrationID).Order("id DESC").First(&payment).Error; err != nil {
+		return nil, false
+	}
+	return &payment, true
+}
@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strings"
+)
+
+// committeeBasePermissions dimiliki setiap panitia pada event-nya sendiri
+var committeeBasePermissions = []string{
+	"budget.manage",
+	"task.manage",
+	"attendance.manage",
+	"loan.request",
+}
+
+// positionPermissions menambah permission berdasarkan kata kunci di CommitteeMember.Position
+// (dicocokkan tanpa memperhatikan huruf besar/kecil, misal "Ketua Pelaksana", "Bendahara 1")
+var positionPermissions = map[string][]string{
+	"ketua":     {"budget.approve", "payment.refund", "promo.manage"},
+	"bendahara": {"budget.approve", "payment.refund", "payment.verify", "promo.manage"},
+}
+
+// GetUserPermissions mengambil semua kode permission global milik role user.
+// Kosong selama user belum mengaktifkan 2FA yang diwajibkan untuk role-nya.
+func GetUserPermissions(userID uint) []string {
+	var codes []string
+	if TwoFactorSetupPending(userID) {
+		return codes
+	}
+	database.DB.Table("permissions").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN users ON users.role_id = role_permissions.role_id").
+		Where("users.id = ? AND users.deleted_at IS NULL", userID).
+		Order("permissions.code").
+		Pluck("permissions.code", &codes)
+	return codes
+}
+
+// HasPermission mengecek apakah role user memiliki permission (berlaku untuk semua event)
+func HasPermission(userID uint, code string) bool {
+	var count int64
+	if TwoFactorSetupPending(userID) {
+		return false
+	}
+	database.DB.Table("role_permissions").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Joins("JOIN users ON users.role_id = role_permissions.role_id").
+		Where("users.id = ? AND users.deleted_at IS NULL AND permissions.code = ?", userID, code).
+		Count(&count)
+	return count > 0
+}
+
+// PositionPermissions menurunkan permission event dari jabatan panitia
+func PositionPermissions(position string) []string {
+	perms := append([]string{}, committeeBasePermissions...)
+	lower := strings.ToLower(position)
+	for keyword, extra := range positionPermissions {
+		if strings.Contains(lower, keyword) {
+			perms = append(perms, extra...)
+		}
+	}
+	return perms
+}
+
+// GetEventPermissions mengambil permission user pada event tertentu
+// (gabungan permission role global dan permission dari jabatan panitia)
+func GetEventPermissions(userID, eventID uint) []string {
+	seen := map[string]bool{}
+	var perms []string
+	if TwoFactorSetupPending(userID) {
+		return perms
+	}
+	add := func(codes []string) {
+		for _, code := range codes {
+			if !seen[code] {
+				seen[code] = true
+				perms = append(perms, code)
+			}
+		}
+	}
+
+	add(GetUserPermissions(userID))
+
+	var members []models.CommitteeMember
+	database.DB.Where("event_id = ? AND user_id = ?", eventID, userID).Find(&members)
+	for _, m := range members {
+		add(PositionPermissions(m.Position))
+	}
+	return perms
+}
+
+// HasEventPermission mengecek permission user pada suatu event: lolos jika role-nya punya
+// permission tersebut secara global, atau jabatannya di kepanitiaan event memberikannya.
+// Selama 2FA wajib belum diaktifkan, permission dari jabatan panitia juga tidak berlaku.
+func HasEventPermission(userID, eventID uint, code string) bool {
+	if TwoFactorSetupPending(userID) {
+		return false
+	}
+	if HasPermission(userID, code) {
+		return true
+	}
+
+	var members []models.CommitteeMember
+	database.DB.Where("event_id = ? AND user_id = ?", eventID, userID).Find(&members)
+	for _, m := range members {
+		for _, perm := range PositionPermissions(m.Position) {
+			if perm == code {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package helpers
+
+import (
+	"errors"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPromoNotFound      = errors.New("kode promo tidak ditemukan")
+	ErrPromoInactive      = errors.New("kode promo tidak aktif atau di luar masa berlaku")
+	ErrPromoNotApplicable = errors.New("kode promo tidak berlaku untuk event atau tipe tiket ini")
+	ErrPromoExhausted     = errors.New("kuota pemakaian kode promo sudah habis")
+	ErrPromoUserLimit     = errors.New("anda sudah mencapai batas pemakaian kode promo ini")
+	ErrPromoFreeTicket    = errors.New("kode promo tidak bisa dipakai untuk tiket gratis")
+)
+
+// NormalizePromoCode menyeragamkan penulisan kode promo (tanpa spasi, huruf besar)
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CalculatePromoDiscount menghitung potongan promo dari harga (tidak pernah melebihi harga)
+func CalculatePromoDiscount(promo *models.PromoCode, price int64) int64 {
+	discount := promo.DiscountValue
+	if promo.DiscountType == models.PromoDiscountPercentage {
+		discount = price * promo.DiscountValue / 100
+		if promo.MaxDiscount > 0 && discount > promo.MaxDiscount {
+			discount = promo.MaxDiscount
+		}
+	}
+	if discount > price {
+		discount = price
+	}
+	if discount < 0 {
+		discount = 0
+	}
+	return discount
+}
+
+// CountPromoUsages menghitung pemakaian promo oleh registrasi yang masih aktif (userID 0 = semua user)
+func CountPromoUsages(tx *gorm.DB, promoID, userID uint) int64 {
+	query := tx.Model(&models.PromoCodeUsage{}).
+		Joins("INNER JOIN registrations ON registrations.id = promo_code_usages.registration_id").
+		Where("promo_code_usages.promo_code_id = ? AND registrations.status NOT IN ?", promoID, models.ReleasedSeatStatuses)
+	if userID != 0 {
+		query = query.Where("promo_code_usages.user_id = ?", userID)
+	}
+	var count int64
+	query.Count(&count)
+	return count
+}
+
+// ValidatePromoCode mengecek kode promo untuk pendaftaran user pada event/tipe tiket tertentu
+// dan mengembalikan promo beserta potongannya dari harga tiket.
+func ValidatePromoCode(code string, eventID uint, ticketTypeID *uint, userID uint, price int64, at time.Time) (*models.PromoCode, int64, error) {
+	var promo models.PromoCode
+	if err := database.DB.Preload("TicketTypes").Where("code = ?", NormalizePromoCode(code)).First(&promo).Error; err != nil {
+		return nil, 0, ErrPromoNotFound
+	}
+	if err := checkPromoApplicable(database.DB, &promo, eventID, ticketTypeID, userID, price, at); err != nil {
+		return nil, 0, err
+	}
+	return &promo, CalculatePromoDiscount(&promo, price), nil
+}
+
+// RedeemPromoCode mencatat pemakaian promo untuk registrasi di dalam transaksi pendaftaran.
+// Baris promo dikunci supaya batas pemakaian tetap terjaga saat banyak pendaftar bersamaan.
+func RedeemPromoCode(tx *gorm.DB, promoID uint, registration *models.Registration, ticketTypeID *uint, originalPrice int64, at time.Time) (*models.PromoCodeUsage, error) {
+	var promo models.PromoCode
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("TicketTypes").First(&promo, promoID).Error; err != nil {
+		return nil, ErrPromoNotFound
+	}
+	if err := checkPromoApplicable(tx, &promo, registration.EventID, ticketTypeID, registration.UserID, originalPrice, at); err != nil {
+		return nil, err
+	}
+
+	usage := models.PromoCodeUsage{
+		PromoCodeID:    promo.ID,
+		RegistrationID: registration.ID,
+		UserID:         registration.UserID,
+		EventID:        registration.EventID,
+		OriginalPrice:  originalPrice,
+		DiscountAmount: CalculatePromoDiscount(&promo, originalPrice),
+	}
+	if err := tx.Create(&usage).Error; err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func checkPromoApplicable(tx *gorm.DB, promo *models.PromoCode, eventID uint, ticketTypeID *uint, userID uint, price int64, at time.Time) error {
+	if !promo.IsActive ||
+		(promo.ValidFrom != nil && at.Before(*promo.ValidFrom)) ||
+		(promo.ValidUntil != nil && at.After(*promo.ValidUntil)) {
+		return ErrPromoInactive
+	}
+	if promo.EventID != nil && *promo.EventID != eventID {
+		return ErrPromoNotApplicable
+	}
+	if len(promo.TicketTypes) > 0 {
+		allowed := false
+		for _, t := range promo.TicketTypes {
+			if ticketTypeID != nil && t.ID == *ticketTypeID {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return ErrPromoNotApplicable
+		}
+	}
+	if price <= 0 {
+		return ErrPromoFreeTicket
+	}
+	if promo.MaxUses > 0 && CountPromoUsages(tx, promo.ID, 0) >= int64(promo.MaxUses) {
+		return ErrPromoExhausted
+	}
+	if promo.PerUserLimit > 0 && CountPromoUsages(tx, promo.ID, userID) >= int64(promo.PerUserLimit) {
+		return ErrPromoUserLimit
+	}
+	return nil
+}
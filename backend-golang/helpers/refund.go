@@ -0,0 +1,184 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrRegistrationNotCancellable dikembalikan untuk registrasi yang tidak bisa dibatalkan (misal sudah ditolak)
+var ErrRegistrationNotCancellable = errors.New("registrasi dengan status ini tidak bisa dibatalkan")
+
+// RefundPercentageFor mengembalikan persentase refund kebijakan event pada waktu tertentu.
+// Lewat refund_deadline berarti tidak ada refund.
+func RefundPercentageFor(event models.Event, at time.Time) int {
+	if event.RefundDeadline != nil && at.After(*event.RefundDeadline) {
+		return 0
+	}
+	if event.RefundPercentage < 0 {
+		return 0
+	}
+	if event.RefundPercentage > 100 {
+		return 100
+	}
+	return event.RefundPercentage
+}
+
+// CalculateRefundAmount menghitung nominal refund dari nominal payment dan persentasenya (dibulatkan ke bawah)
+func CalculateRefundAmount(amount int64, percentage int) int64 {
+	return amount * int64(percentage) / 100
+}
+
+// CancellationRefund menentukan nominal refund untuk payment lunas saat registrasi dibatalkan
+type CancellationRefund struct {
+	Event       models.Event
+	IsCommittee bool   // Panitia (payment.refund): refund penuh atau sebesar RequestedAmount
+	Requested   *int64 // Nominal refund dari panitia (nil = refund penuh)
+	Reason      string
+	RequestedBy uint
+}
+
+// amountFor menghitung nominal dan persentase refund sebuah payment: kebijakan event untuk peserta,
+// refund penuh atau nominal yang diminta untuk panitia
+func (r CancellationRefund) amountFor(payment models.Payment, at time.Time) (int64, int) {
+	if !r.IsCommittee {
+		percentage := RefundPercentageFor(r.Event, at)
+		return CalculateRefundAmount(payment.Amount, percentage), percentage
+	}
+	if r.Requested == nil || payment.Amount <= 0 {
+		return payment.Amount, 100
+	}
+	return *r.Requested, int(*r.Requested * 100 / payment.Amount)
+}
+
+// MarkRegistrationCancelled mengubah status registrasi menjadi cancelled, menyiapkan refund untuk
+// payment lunasnya, lalu membatalkan payment yang masih pending. Mengembalikan status sebelumnya dan
+// refund yang harus dieksekusi (nil jika tidak ada dana yang dikembalikan). Registrasi yang sudah
+// cancelled/refunded tidak diubah; pembatalan ulang mengembalikan refund yang gagal/pending dengan
+// refund key yang sama untuk dicoba ulang.
+//
+// Refund dibuat di transaksi yang sama dengan baris registrasi dan payment terkunci, sehingga
+// pembatalan bersamaan (atau retry saat permintaan pertama masih berjalan) tidak membuat dua refund.
+func MarkRegistrationCancelled(registrationID uint, note string, policy CancellationRefund) (string, *models.Refund, error) {
+	var previous string
+	var refund *models.Refund
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var registration models.Registration
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&registration, registrationID).Error; err != nil {
+			return err
+		}
+		previous = registration.Status
+		// Registrasi yang ditolak tidak bisa dibatalkan; hanya refund pelunasan terlambatnya
+		// (lihat ApplyPaymentStatus) yang bisa dicoba ulang
+		rejected := registration.Status == "rejected"
+		if !rejected && !isRegistrationInactive(registration.Status) {
+			if err := tx.Model(&registration).Update("status", "cancelled").Error; err != nil {
+				return err
+			}
+			// Pembatalan oleh ketua grup membatalkan seluruh peserta grup (pembayarannya satu)
+			if err := SyncGroupMembers(tx, &registration, map[string]interface{}{"status": "cancelled"}); err != nil {
+				return err
+			}
+		}
+
+		// Refund hanya untuk registrasi yang punya payment lunas
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("registration_id = ? AND status = ?", registrationID, models.PaymentStatusPaid).
+			Order("id DESC").First(&payment).Error; err != nil {
+			if rejected {
+				return ErrRegistrationNotCancellable
+			}
+			return nil
+		}
+
+		var last models.Refund
+		if err := tx.Where("payment_id = ?", payment.ID).First(&last).Error; err == nil {
+			if last.Status != models.RefundStatusSucceeded {
+				refund = &last
+			}
+			return nil
+		}
+		if rejected {
+			return ErrRegistrationNotCancellable
+		}
+
+		amount, percentage := policy.amountFor(payment, time.Now())
+		if amount <= 0 {
+			return nil
+		}
+		refund = &models.Refund{
+			PaymentID:      payment.ID,
+			RegistrationID: payment.RegistrationID,
+			Amount:         amount,
+			Percentage:     percentage,
+			Reason:         truncate(policy.Reason, 255),
+			RequestedByID:  &policy.RequestedBy,
+		}
+		return createRefund(tx, refund)
+	})
+	if err != nil {
+		return previous, nil, err
+	}
+
+	// Tagihan yang belum dibayar tidak berlaku lagi
+	var pending []models.Payment
+	database.DB.Where("registration_id = ? AND status IN ?", registrationID,
+		[]string{models.PaymentStatusPending, models.PaymentStatusChallenge}).Find(&pending)
+	for _, payment := range pending {
+		if _, err := ApplyPaymentStatus(payment.ID, PaymentUpdate{
+			Status:         models.PaymentStatusCancelled,
+			ProviderStatus: payment.ProviderStatus,
+			Source:         "cancellation",
+			Note:           note,
+		}); err != nil {
+			return previous, refund, err
+		}
+	}
+	return previous, refund, nil
+}
+
+// GetPaidPayment mengambil payment lunas (atau yang sudah di-refund) terbaru sebuah registrasi
+func GetPaidPayment(registrationID uint) (*models.Payment, bool) {
+	var payment models.Payment
+	if err := database.DB.
+		Where("registration_id = ? AND status IN ?", registrationID,
+			[]string{models.PaymentStatusPaid, models.PaymentStatusRefunded}).
+		Order("id DESC").First(&payment).Error; err != nil {
+		return nil, false
+	}
+	return &payment, true
+}
+
+// createRefund menyimpan refund pending beserta refund key-nya ("REFUND-{PaymentID}-{RefundID}")
+// di dalam transaksi tx. Pemanggil harus memegang lock baris payment-nya.
+func createRefund(tx *gorm.DB, refund *models.Refund) error {
+	refund.Status = models.RefundStatusPending
+	if err := tx.Create(refund).Error; err != nil {
+		return err
+	}
+	refund.RefundKey = fmt.Sprintf("REFUND-%d-%d", refund.PaymentID, refund.ID)
+	return tx.Model(refund).Update("refund_key", refund.RefundKey).Error
+}
+
+// FinishRefund mencatat hasil refund dari provider (refundErr nil berarti berhasil)
+func FinishRefund(refund *models.Refund, providerStatus, raw string, refundErr error) error {
+	now := time.Now()
+	fields := map[string]interface{}{
+		"status":          models.RefundStatusSucceeded,
+		"provider_status": providerStatus,
+		"raw_response":    raw,
+		"error_message":   "",
+		"processed_at":    now,
+	}
+	if refundErr != nil {
+		fields["status"] = models.RefundStatusFailed
+		fields["error_message"] = truncate(refundErr.Error(), 255)
+	}
+	return database.DB.Model(refund).Updates(fields).Error
+}
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"santrikoding/backend-api/models"
+
+	"gorm.io/gorm"
+)
+
+// SyncGroupMembers menerapkan perubahan status registrasi ketua grup (yang menanggung pembayaran)
+// ke anggota grup lainnya: lunas, ditolak/expired, dibatalkan atau di-refund berlaku untuk seluruh grup.
+// Anggota yang sudah ditolak/dibatalkan sendiri tidak diubah. Tidak melakukan apa-apa untuk registrasi
+// biasa maupun anggota grup yang bukan ketua.
+func SyncGroupMembers(tx *gorm.DB, registration *models.Registration, fields map[string]interface{}) error {
+	if !IsGroupLead(tx, registration) {
+		return nil
+	}
+	return tx.Model(&models.Registration{}).
+		Where("group_id = ? AND id <> ? AND status NOT IN ?", *registration.GroupID, registration.ID, models.ReleasedSeatStatuses).
+		Updates(fields).Error
+}
+
+// IsGroupLead mengecek apakah registrasi adalah ketua grup yang menanggung tiket anggota grupnya
+func IsGroupLead(tx *gorm.DB, registration *models.Registration) bool {
+	if registration.GroupID == nil {
+		return false
+	}
+	var group models.RegistrationGroup
+	if err := tx.First(&group, *registration.GroupID).Error; err != nil {
+		return false
+	}
+	return group.LeadRegistrationID != nil && *group.LeadRegistrationID == registration.ID
+}
+
+// GetGroupMembers mengambil anggota grup selain registrasi ketua (User dan Event di-preload)
+func GetGroupMembers(tx *gorm.DB, lead *models.Registration) []models.Registration {
+	var members []models.Registration
+	if lead.GroupID == nil {
+		return members
+	}
+	tx.Preload("User").Preload("Event").
+		Where("group_id = ? AND id <> ?", *lead.GroupID, lead.ID).
+		Find(&members)
+	return members
+}
+
+// rejectedGroupRegistrationIDs mengambil ID registrasi yang dikonfirmasi ulang bersama registrasi
+// yang ditolak: registrasi itu sendiri ditambah, jika ia ketua grup, anggota grup yang ikut ditolak
+func rejectedGroupRegistrationIDs(tx *gorm.DB, registration *models.Registration) []uint {
+	ids := []uint{registration.ID}
+	if !IsGroupLead(tx, registration) {
+		return ids
+	}
+	var members []uint
+	tx.Model(&models.Registration{}).
+		Where("group_id = ? AND id <> ? AND status = ?", *registration.GroupID, registration.ID, "rejected").
+		Pluck("id", &members)
+	return append(ids, members...)
+}
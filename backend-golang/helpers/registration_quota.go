@@ -0,0 +1,96 @@
+package helpers
+
+import (
+	"errors"
+	"santrikoding/backend-api/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAlreadyRegistered = errors.New("anda sudah terdaftar di event ini")
+	ErrEventFull         = errors.New("kuota event sudah penuh")
+)
+
+// HasActiveRegistration mengecek apakah peserta sudah punya registrasi aktif di event.
+// guestEmail kosong untuk user itu sendiri, atau email tamu yang didaftarkan user tersebut.
+func HasActiveRegistration(tx *gorm.DB, eventID, userID uint, guestEmail string) bool {
+	var count int64
+	tx.Model(&models.Registration{}).
+		Where("event_id = ? AND user_id = ? AND guest_email = ? AND status NOT IN ?",
+			eventID, userID, guestEmail, models.InactiveRegistrationStatuses).
+		Count(&count)
+	return count > 0
+}
+
+// ReserveSeat mengunci baris event lalu mengecek ulang pendaftaran ganda, kuota event dan kuota
+// tipe tiket di dalam transaksi pendaftaran. Pendaftar event yang sama diproses bergantian sampai
+// transaksi selesai, sehingga kuota tidak terlampaui walau banyak yang mendaftar bersamaan.
+// Harus menjadi query pertama di transaksi supaya hitungan membaca data terbaru.
+func ReserveSeat(tx *gorm.DB, eventID, userID uint, ticketTypeID *uint) error {
+	if err := lockEvent(tx, eventID); err != nil {
+		return err
+	}
+	if HasActiveRegistration(tx, eventID, userID, "") {
+		return ErrAlreadyRegistered
+	}
+	return checkSeats(tx, eventID, userID, ticketTypeID, 1)
+}
+
+// ReserveGroupSeats sama seperti ReserveSeat untuk pendaftaran grup: memastikan sisa kuota event dan
+// tipe tiket cukup untuk seats peserta. Pengecekan pendaftaran ganda per peserta dilakukan pemanggil.
+func ReserveGroupSeats(tx *gorm.DB, eventID uint, ticketTypeID *uint, seats int) error {
+	if err := lockEvent(tx, eventID); err != nil {
+		return err
+	}
+	return checkSeats(tx, eventID, 0, ticketTypeID, seats)
+}
+
+// ReclaimSeats mengecek ulang kuota sebelum registrasi yang kursinya sudah dilepas (rejected)
+// dikonfirmasi kembali, misalnya karena pelunasan yang masuk setelah tagihan expired. Baris event
+// dikunci seperti ReserveSeat; ketua grup ikut menghitung anggota grup yang ditolak bersamanya.
+// Mengembalikan ID registrasi yang boleh dikonfirmasi ulang.
+func ReclaimSeats(tx *gorm.DB, registration *models.Registration) ([]uint, error) {
+	if err := lockEvent(tx, registration.EventID); err != nil {
+		return nil, err
+	}
+	ids := rejectedGroupRegistrationIDs(tx, registration)
+	if err := checkSeats(tx, registration.EventID, registration.UserID, registration.TicketTypeID, len(ids)); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func lockEvent(tx *gorm.DB, eventID uint) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Event{}, eventID).Error
+}
+
+// checkSeats mengecek sisa kuota event dan tipe tiket untuk seats kursi baru
+// (tawaran waitlist milik excludeUserID tidak dihitung terpakai)
+func checkSeats(tx *gorm.DB, eventID, excludeUserID uint, ticketTypeID *uint, seats int) error {
+	var event models.Event
+	if err := tx.First(&event, eventID).Error; err != nil {
+		return err
+	}
+	if event.Quota > 0 && int(SeatsTaken(tx, eventID, excludeUserID))+seats > event.Quota {
+		return ErrEventFull
+	}
+
+	if ticketTypeID != nil {
+		var ticketType models.TicketType
+		if err := tx.Where("id = ? AND event_id = ?", *ticketTypeID, eventID).First(&ticketType).Error; err != nil {
+			return ErrTicketTypeNotFound
+		}
+		if ticketType.Quota > 0 {
+			var sold int64
+			tx.Model(&models.Registration{}).
+				Where("ticket_type_id = ? AND status NOT IN ?", ticketType.ID, models.ReleasedSeatStatuses).
+				Count(&sold)
+			if int(sold)+seats > ticketType.Quota {
+				return ErrTicketSoldOut
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	"errors"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTransferNotPending  = errors.New("transfer tiket sudah tidak berlaku")
+	ErrTransferExpired     = errors.New("batas waktu transfer tiket sudah lewat")
+	ErrTicketNotTransfer   = errors.New("tiket ini tidak bisa ditransfer")
+	ErrTransferUnavailable = errors.New("event ini tidak mengizinkan transfer tiket")
+	ErrTransferGroupLead   = errors.New("tiket ketua grup tidak bisa ditransfer")
+)
+
+// TransferDeadlineFor mengembalikan batas waktu transfer tiket event (default: saat event dimulai)
+func TransferDeadlineFor(event models.Event) time.Time {
+	if event.TransferDeadline != nil {
+		return *event.TransferDeadline
+	}
+	return event.StartDate
+}
+
+// CanTransferRegistration mengecek apakah registrasi boleh ditransfer saat ini: event mengizinkan transfer,
+// belum lewat batas waktu, registrasi masih aktif (pending/confirmed), bukan tiket tamu dan belum check-in.
+// Tiket ketua grup tidak bisa ditransfer karena tiket tamu anggota grupnya tetap milik pendaftar.
+func CanTransferRegistration(db *gorm.DB, registration models.Registration, event models.Event, at time.Time) error {
+	if !event.TransferEnabled {
+		return ErrTransferUnavailable
+	}
+	if at.After(TransferDeadlineFor(event)) {
+		return ErrTransferExpired
+	}
+	if registration.IsGuest() || registration.Attendance ||
+		(registration.Status != "pending" && registration.Status != "confirmed") {
+		return ErrTicketNotTransfer
+	}
+	if IsGroupLead(db, &registration) {
+		return ErrTransferGroupLead
+	}
+	return nil
+}
+
+// ExpireRegistrationTransfers menandai transfer pending yang melewati batas waktunya sebagai expired
+func ExpireRegistrationTransfers() {
+	database.DB.Model(&models.RegistrationTransfer{}).
+		Where("status = ? AND expires_at <= ?", models.TransferPending, time.Now()).
+		Update("status", models.TransferExpired)
+}
+
+// AcceptRegistrationTransfer memindahkan registrasi ke penerima dalam satu transaksi: registrasi dan
+// transfer dikunci, syarat transfer dicek ulang, QR code dibuat ulang dan jawaban form diganti dengan
+// jawaban penerima. Mengembalikan registrasi yang sudah berpindah.
+func AcceptRegistrationTransfer(transferID, toUserID uint, answers []models.FormAnswer) (*models.Registration, error) {
+	var registration models.Registration
+	now := time.Now()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var transfer models.RegistrationTransfer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, transferID).Error; err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferPending || transfer.ToUserID != toUserID {
+			return ErrTransferNotPending
+		}
+		if now.After(transfer.ExpiresAt) {
+			return ErrTransferExpired
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&registration, transfer.RegistrationID).Error; err != nil {
+			return err
+		}
+		var event models.Event
+		if err := tx.First(&event, registration.EventID).Error; err != nil {
+			return err
+		}
+		if registration.UserID != transfer.FromUserID {
+			return ErrTicketNotTransfer
+		}
+		if err := CanTransferRegistration(tx, registration, event, now); err != nil {
+			return err
+		}
+		if HasActiveRegistration(tx, registration.EventID, toUserID, "") {
+			return ErrAlreadyRegistered
+		}
+
+		registration.UserID = toUserID
+		if err := tx.Model(&registration).Update("user_id", toUserID).Error; err != nil {
+			if IsDuplicateEntryError(err) {
+				return ErrAlreadyRegistered
+			}
+			return err
+		}
+		// QR baru untuk penerima; QR pemilik lama otomatis tidak berlaku
+		if err := AssignTicketQR(tx, &registration, event); err != nil {
+			return err
+		}
+
+		// Jawaban form pemilik lama diganti dengan jawaban penerima
+		if err := tx.Where("registration_id = ?", registration.ID).Delete(&models.FormAnswer{}).Error; err != nil {
+			return err
+		}
+		for i := range answers {
+			answers[i].RegistrationID = registration.ID
+			if err := tx.Create(&answers[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&transfer).Updates(map[string]interface{}{
+			"status":       models.TransferAccepted,
+			"responded_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
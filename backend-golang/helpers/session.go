@@ -0,0 +1,151 @@
+package helpers
+
+import (
+	"errors"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"time"
+)
+
+// lastSeenInterval membatasi update last_seen_at agar tidak menulis ke DB di setiap request
+const lastSeenInterval = time.Minute
+
+// ErrInvalidRefreshToken dikembalikan jika refresh token tidak dikenal, sudah dicabut, atau kadaluarsa
+var ErrInvalidRefreshToken = errors.New("refresh token tidak valid atau sudah kadaluarsa")
+
+// TokenPair adalah pasangan access + refresh token yang dikirim ke client
+type TokenPair struct {
+	SessionID        uint
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// CreateSession membuat sesi login baru untuk user beserta pasangan tokennya
+func CreateSession(userID uint, deviceName, ipAddress, userAgent string) (*TokenPair, error) {
+	refreshToken, err := GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserID:           userID,
+		RefreshTokenHash: HashToken(refreshToken),
+		DeviceName:       truncate(deviceName, 255),
+		IPAddress:        truncate(ipAddress, 50),
+		UserAgent:        truncate(userAgent, 255),
+		LastSeenAt:       now,
+		ExpiresAt:        now.AddDate(0, 0, GetSettingInt("refresh_token_days", 30)),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	accessToken, accessExpiresAt := GenerateToken(userID, session.ID)
+	return &TokenPair{
+		SessionID:        session.ID,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// RefreshSession menukar refresh token dengan pasangan token baru (rotasi).
+// Refresh token lama langsung tidak berlaku; jika token lama dipakai ulang
+// (indikasi dicuri), sesinya sudah tidak ditemukan sehingga permintaan ditolak.
+func RefreshSession(refreshToken, ipAddress, userAgent string) (*TokenPair, error) {
+	var session models.Session
+	if err := database.DB.Where("refresh_token_hash = ?", HashToken(refreshToken)).First(&session).Error; err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newRefreshToken, err := GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// Update bersyarat pada hash lama supaya dua refresh bersamaan tidak sama-sama berhasil
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, session.RefreshTokenHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": HashToken(newRefreshToken),
+			"ip_address":         truncate(ipAddress, 50),
+			"user_agent":         truncate(userAgent, 255),
+			"last_seen_at":       now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	accessToken, accessExpiresAt := GenerateToken(session.UserID, session.ID)
+	return &TokenPair{
+		SessionID:        session.ID,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     newRefreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// ValidateSession mengecek sesi milik user masih aktif dan memperbarui last_seen_at
+func ValidateSession(sessionID, userID uint) bool {
+	var session models.Session
+	if err := database.DB.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		return false
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return false
+	}
+
+	if now.Sub(session.LastSeenAt) > lastSeenInterval {
+		database.DB.Model(&models.Session{}).Where("id = ?", session.ID).Update("last_seen_at", now)
+	}
+	return true
+}
+
+// RevokeSession mencabut satu sesi milik user. Mengembalikan false jika sesi tidak ditemukan/sudah dicabut.
+func RevokeSession(sessionID, userID uint, reason string) bool {
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// RevokeAllSessions mencabut semua sesi aktif user kecuali exceptSessionID (0 = cabut semua).
+// Mengembalikan jumlah sesi yang dicabut.
+func RevokeAllSessions(userID uint, exceptSessionID uint, reason string) int64 {
+	query := database.DB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptSessionID != 0 {
+		query = query.Where("id <> ?", exceptSessionID)
+	}
+	result := query.Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected
+}
+
+// CleanupExpiredSessions menghapus sesi yang sudah kadaluarsa atau dicabut lebih dari 30 hari lalu
+func CleanupExpiredSessions() error {
+	cutoff := time.Now().AddDate(0, 0, -30)
+	return database.DB.
+		Where("expires_at < ? OR (revoked_at IS NOT NULL AND revoked_at < ?)", cutoff, cutoff).
+		Delete(&models.Session{}).Error
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+package helpers
+
+import (
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strconv"
+)
+
+// GetSetting mengambil nilai system setting berdasarkan key (string kosong jika tidak ada)
+func GetSetting(key string) string {
+	var setting models.SystemSetting
+	if err := database.DB.Where("`key` = ?", key).First(&setting).Error; err != nil {
+		return ""
+	}
+	return setting.Value
+}
+
+// GetSettingInt mengambil system setting integer, fallback jika kosong/tidak valid
+func GetSettingInt(key string, fallback int) int {
+	if i, err := strconv.Atoi(GetSetting(key)); err == nil && i > 0 {
+		return i
+	}
+	return fallback
+}
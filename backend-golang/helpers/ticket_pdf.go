@@ -0,0 +1,255 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"santrikoding/backend-api/models"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Peran yang tercetak di badge
+const (
+	BadgeRoleParticipant = "PESERTA"
+	BadgeRoleCommittee   = "PANITIA"
+)
+
+// BadgeInfo adalah isi satu badge nama di lembar badge event
+type BadgeInfo struct {
+	Name         string
+	Organization string // Universitas / program studi
+	Role         string // BadgeRoleParticipant atau BadgeRoleCommittee
+	Division     string // Divisi panitia (kosong untuk peserta)
+	QRCode       string // QR tiket peserta untuk scan check-in (kosong untuk panitia)
+}
+
+// Ukuran badge pada lembar A4 (2 kolom x 4 baris, mm)
+const (
+	badgeColumns = 2
+	badgeRows    = 4
+	badgeWidth   = 90.0
+	badgeHeight  = 65.0
+)
+
+// UserOrganization menyusun asal instansi user untuk badge: universitas dan program studi
+// (User.University dan User.StudyProgram harus di-preload; fallback ke kolom Major)
+func UserOrganization(user models.User) string {
+	var parts []string
+	if user.University != nil && user.University.Name != "" {
+		parts = append(parts, user.University.Name)
+	}
+	if user.StudyProgram != nil && user.StudyProgram.Name != "" {
+		parts = append(parts, user.StudyProgram.Name)
+	} else if user.Major != "" {
+		parts = append(parts, user.Major)
+	}
+	return strings.Join(parts, " - ")
+}
+
+// registerQRImage membuat gambar QR (PNG) dan mendaftarkannya ke PDF, mengembalikan nama gambarnya
+func registerQRImage(pdf *gofpdf.Fpdf, name, content string) (string, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, 512)
+	if err != nil {
+		return "", err
+	}
+	pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	return name, pdf.Error()
+}
+
+// RenderTicketPDF membuat e-tiket PDF, satu halaman per registrasi.
+// Registrasi harus di-preload dengan Event, User dan TicketType.
+func RenderTicketPDF(registrations []models.Registration) ([]byte, error) {
+	organizer := GetSetting("organizer_name")
+	if organizer == "" {
+		organizer = GetSetting("app_name")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // cp1252, agar karakter non-ASCII tetap tampil
+	pdf.SetTitle("E-Tiket", true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(false, 20)
+
+	for _, reg := range registrations {
+		event := reg.Event
+		pdf.AddPage()
+
+		// Kartu tiket
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.RoundedRect(20, 20, 170, 120, 4, "1234", "D")
+
+		pdf.SetFillColor(30, 64, 175)
+		pdf.Rect(20, 20, 170, 14, "F")
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetXY(26, 23)
+		pdf.CellFormat(100, 8, tr(organizer), "", 0, "L", false, 0, "")
+		pdf.CellFormat(58, 8, "E-TIKET", "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+
+		// Informasi event dan peserta (kiri)
+		pdf.SetXY(26, 40)
+		pdf.SetFont("Helvetica", "B", 15)
+		pdf.MultiCell(100, 7, tr(event.Title), "", "L", false)
+
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.SetTextColor(90, 90, 90)
+		schedule := event.StartDate.Format("02 Jan 2006 15:04")
+		if !event.EndDate.IsZero() {
+			schedule += " - " + event.EndDate.Format("02 Jan 2006 15:04")
+		}
+		location := event.Location
+		if event.EventType == "online" {
+			location = "Online"
+		}
+		for _, line := range []string{schedule, location} {
+			if line != "" {
+				pdf.SetX(26)
+				pdf.MultiCell(100, 5, tr(line), "", "L", false)
+			}
+		}
+		pdf.SetTextColor(0, 0, 0)
+
+		pdf.Ln(4)
+		rows := [][2]string{
+			{"Nama peserta", reg.AttendeeName()},
+			{"Email", reg.AttendeeEmail()},
+		}
+		if reg.TicketType != nil {
+			rows = append(rows, [2]string{"Tipe tiket", reg.TicketType.Name})
+		}
+		rows = append(rows, [2]string{"No. registrasi", fmt.Sprintf("#%06d", reg.ID)})
+		for _, row := range rows {
+			pdf.SetX(26)
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.SetTextColor(120, 120, 120)
+			pdf.CellFormat(100, 4.5, row[0], "", 1, "L", false, 0, "")
+			pdf.SetX(26)
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.SetTextColor(0, 0, 0)
+			pdf.CellFormat(100, 6, tr(row[1]), "", 1, "L", false, 0, "")
+		}
+
+		// QR code check-in (kanan)
+		if reg.QRCode != "" {
+			name, err := registerQRImage(pdf, fmt.Sprintf("qr-ticket-%d", reg.ID), reg.QRCode)
+			if err != nil {
+				return nil, err
+			}
+			pdf.ImageOptions(name, 134, 44, 50, 50, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+			pdf.SetFont("Helvetica", "", 8)
+			pdf.SetTextColor(90, 90, 90)
+			pdf.SetXY(134, 96)
+			pdf.MultiCell(50, 4, "Tunjukkan QR ini kepada panitia saat check-in", "", "C", false)
+			pdf.SetTextColor(0, 0, 0)
+		}
+
+		pdf.SetXY(26, 128)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.MultiCell(158, 4, "Tiket ini bersifat pribadi. QR code hanya berlaku untuk satu peserta dan dapat berubah jika tiket ditransfer.", "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderBadgeSheetPDF membuat lembar badge nama ukuran A4 (grid 2x4, ada garis potong) untuk sebuah event
+func RenderBadgeSheetPDF(event models.Event, badges []BadgeInfo) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle("Badge "+event.Title, true)
+	pdf.SetAutoPageBreak(false, 0)
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	marginX := (pageWidth - badgeColumns*badgeWidth) / 2
+	marginY := (pageHeight - badgeRows*badgeHeight) / 2
+	perPage := badgeColumns * badgeRows
+
+	for i, badge := range badges {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+		slot := i % perPage
+		x := marginX + float64(slot%badgeColumns)*badgeWidth
+		y := marginY + float64(slot/badgeColumns)*badgeHeight
+
+		// Garis potong
+		pdf.SetDrawColor(180, 180, 180)
+		pdf.SetLineWidth(0.2)
+		pdf.Rect(x, y, badgeWidth, badgeHeight, "D")
+
+		// Header: judul event dengan warna per peran
+		if badge.Role == BadgeRoleCommittee {
+			pdf.SetFillColor(185, 28, 28)
+		} else {
+			pdf.SetFillColor(30, 64, 175)
+		}
+		pdf.Rect(x, y, badgeWidth, 11, "F")
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetXY(x+3, y+1.5)
+		pdf.CellFormat(badgeWidth-6, 8, truncateBadgeText(pdf, tr(event.Title), badgeWidth-6), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+
+		textWidth := badgeWidth - 8
+		if badge.QRCode != "" {
+			textWidth = badgeWidth - 36
+		}
+
+		// Nama dan instansi
+		pdf.SetXY(x+4, y+17)
+		pdf.SetFont("Helvetica", "B", 15)
+		pdf.MultiCell(textWidth, 7, tr(badge.Name), "", "L", false)
+		if badge.Organization != "" {
+			pdf.SetX(x + 4)
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.SetTextColor(90, 90, 90)
+			pdf.MultiCell(textWidth, 4.5, tr(badge.Organization), "", "L", false)
+			pdf.SetTextColor(0, 0, 0)
+		}
+
+		if badge.QRCode != "" {
+			name, err := registerQRImage(pdf, fmt.Sprintf("qr-badge-%d", i), badge.QRCode)
+			if err != nil {
+				return nil, err
+			}
+			pdf.ImageOptions(name, x+badgeWidth-32, y+15, 28, 28, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		}
+
+		// Footer: peran (dan divisi panitia)
+		role := badge.Role
+		if badge.Division != "" {
+			role += " - " + badge.Division
+		}
+		pdf.SetFillColor(243, 244, 246)
+		pdf.Rect(x, y+badgeHeight-11, badgeWidth, 11, "F")
+		pdf.SetXY(x+3, y+badgeHeight-9.5)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(badgeWidth-6, 8, tr(strings.ToUpper(role)), "", 0, "C", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// truncateBadgeText memotong teks (sudah diterjemahkan ke cp1252, satu byte per karakter)
+// agar muat dalam satu baris selebar width
+func truncateBadgeText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
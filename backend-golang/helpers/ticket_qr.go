@@ -0,0 +1,225 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"santrikoding/backend-api/config"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Format QR tiket bertanda tangan:
+//
+//	TKT1.{key_id}.{event_id}.{registration_id}.{expires_unix}.{nonce}.{signature}
+//
+// signature = base64url(HMAC-SHA256(kunci key_id, semua bagian sebelum signature)). Scanner bisa
+// memvalidasi keaslian dan masa berlaku QR sebelum mencari registrasinya di database.
+const ticketQRPrefix = "TKT1"
+
+// legacyTicketQRPrefix adalah awalan QR lama (EVT-{event}-USR-{user}-{acak}) yang belum ditandatangani
+const legacyTicketQRPrefix = "EVT-"
+
+var (
+	ErrTicketQRMalformed  = errors.New("format QR tiket tidak dikenali")
+	ErrTicketQRUnknownKey = errors.New("kunci QR tiket tidak dikenal")
+	ErrTicketQRSignature  = errors.New("tanda tangan QR tiket tidak valid")
+	ErrTicketQRExpired    = errors.New("QR tiket sudah kadaluarsa")
+)
+
+// TicketClaims adalah isi QR tiket yang sudah diverifikasi
+type TicketClaims struct {
+	KeyID          string
+	EventID        uint
+	RegistrationID uint
+	ExpiresAt      time.Time
+}
+
+type ticketQRKeyring struct {
+	active string
+	keys   map[string][]byte
+}
+
+var (
+	ticketKeysOnce sync.Once
+	ticketKeys     ticketQRKeyring
+)
+
+// loadTicketQRKeys membaca kunci penandatangan QR dari env TICKET_QR_KEYS ("kid:secret,kid2:secret2").
+// TICKET_QR_ACTIVE_KEY memilih kunci untuk QR baru (default kunci pertama); kunci lain tetap dipakai
+// untuk memverifikasi QR lama selama rotasi. Tanpa konfigurasi dipakai kunci "k0" turunan JWT_SECRET.
+func loadTicketQRKeys() ticketQRKeyring {
+	ticketKeysOnce.Do(func() {
+		ticketKeys.keys = map[string][]byte{}
+		for _, pair := range strings.Split(os.Getenv("TICKET_QR_KEYS"), ",") {
+			kid, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			if !ok || kid == "" || secret == "" || strings.Contains(kid, ".") {
+				continue
+			}
+			ticketKeys.keys[kid] = []byte(secret)
+			if ticketKeys.active == "" {
+				ticketKeys.active = kid
+			}
+		}
+		if active := os.Getenv("TICKET_QR_ACTIVE_KEY"); active != "" {
+			if _, ok := ticketKeys.keys[active]; ok {
+				ticketKeys.active = active
+			} else {
+				log.Printf("TICKET_QR_ACTIVE_KEY %q tidak ada di TICKET_QR_KEYS, memakai %q", active, ticketKeys.active)
+			}
+		}
+		if len(ticketKeys.keys) == 0 {
+			mac := hmac.New(sha256.New, config.JWT_KEY)
+			mac.Write([]byte("ticket-qr"))
+			ticketKeys.keys["k0"] = mac.Sum(nil)
+			ticketKeys.active = "k0"
+		}
+	})
+	return ticketKeys
+}
+
+// ActiveTicketQRKeyID mengembalikan id kunci yang dipakai untuk menandatangani QR baru
+func ActiveTicketQRKeyID() string {
+	return loadTicketQRKeys().active
+}
+
+func signTicketPayload(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignTicketQR membuat QR tiket bertanda tangan dengan kunci aktif
+func SignTicketQR(eventID, registrationID uint, expiresAt time.Time) (string, error) {
+	keyring := loadTicketQRKeys()
+
+	nonce := make([]byte, 4)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	payload := fmt.Sprintf("%s.%s.%d.%d.%d.%s", ticketQRPrefix, keyring.active,
+		eventID, registrationID, expiresAt.Unix(), hex.EncodeToString(nonce))
+	return payload + "." + signTicketPayload(keyring.keys[keyring.active], payload), nil
+}
+
+// VerifyTicketQR memvalidasi tanda tangan dan masa berlaku QR tiket tanpa mengakses database.
+// Masa berlaku di QR mengikuti jadwal event saat QR diterbitkan; ErrTicketQRExpired dikembalikan
+// bersama claims-nya supaya server bisa mengecek ulang terhadap jadwal event terbaru (TicketQRExpiry).
+func VerifyTicketQR(code string, at time.Time) (*TicketClaims, error) {
+	parts := strings.Split(code, ".")
+	if len(parts) != 7 || parts[0] != ticketQRPrefix {
+		return nil, ErrTicketQRMalformed
+	}
+
+	secret, ok := loadTicketQRKeys().keys[parts[1]]
+	if !ok {
+		return nil, ErrTicketQRUnknownKey
+	}
+	payload := strings.Join(parts[:6], ".")
+	if !hmac.Equal([]byte(parts[6]), []byte(signTicketPayload(secret, payload))) {
+		return nil, ErrTicketQRSignature
+	}
+
+	eventID, err1 := strconv.ParseUint(parts[2], 10, 64)
+	registrationID, err2 := strconv.ParseUint(parts[3], 10, 64)
+	expires, err3 := strconv.ParseInt(parts[4], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, ErrTicketQRMalformed
+	}
+
+	claims := &TicketClaims{
+		KeyID:          parts[1],
+		EventID:        uint(eventID),
+		RegistrationID: uint(registrationID),
+		ExpiresAt:      time.Unix(expires, 0),
+	}
+	if at.After(claims.ExpiresAt) {
+		return claims, ErrTicketQRExpired
+	}
+	return claims, nil
+}
+
+// IsLegacyTicketQR bernilai true untuk QR format lama yang belum ditandatangani
+func IsLegacyTicketQR(code string) bool {
+	return strings.HasPrefix(code, legacyTicketQRPrefix)
+}
+
+// LegacyTicketQRAccepted mengecek setting ticket_qr_accept_legacy (default diterima selama masa migrasi)
+func LegacyTicketQRAccepted() bool {
+	return GetSetting("ticket_qr_accept_legacy") != "false"
+}
+
+// TicketQRExpiry menghitung masa berlaku QR tiket: akhir event ditambah ticket_qr_grace_hours
+func TicketQRExpiry(event models.Event) time.Time {
+	end := event.EndDate
+	if end.IsZero() || end.Before(event.StartDate) {
+		end = event.StartDate
+	}
+	return end.Add(time.Duration(GetSettingInt("ticket_qr_grace_hours", 24)) * time.Hour)
+}
+
+// TemporaryTicketCode adalah nilai qr_code sementara saat registrasi dibuat (ID registrasi belum ada);
+// diganti AssignTicketQR di transaksi yang sama
+func TemporaryTicketCode() string {
+	return "TMP-" + uuid.New().String()
+}
+
+// AssignTicketQR menandatangani ulang QR tiket registrasi dan menyimpannya. QR lama otomatis tidak
+// berlaku karena check-in mencocokkan QR dengan yang tersimpan.
+func AssignTicketQR(tx *gorm.DB, registration *models.Registration, event models.Event) error {
+	code, err := SignTicketQR(event.ID, registration.ID, TicketQRExpiry(event))
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(registration).Update("qr_code", code).Error; err != nil {
+		return err
+	}
+	registration.QRCode = code
+	return nil
+}
+
+// ReissueTicketQRCodes menerbitkan ulang QR bertanda tangan untuk registrasi dengan QR format lama.
+// allKeys juga menerbitkan ulang QR yang ditandatangani kunci selain kunci aktif (setelah rotasi kunci).
+func ReissueTicketQRCodes(allKeys bool) (int, error) {
+	activePrefix := ticketQRPrefix + "." + ActiveTicketQRKeyID() + "."
+	reissued := 0
+	lastID := uint(0)
+
+	for {
+		query := database.DB.Preload("Event").Where("id > ?", lastID)
+		if allKeys {
+			query = query.Where("qr_code NOT LIKE ?", activePrefix+"%")
+		} else {
+			query = query.Where("qr_code LIKE ?", legacyTicketQRPrefix+"%")
+		}
+
+		var registrations []models.Registration
+		if err := query.Order("id ASC").Limit(500).Find(&registrations).Error; err != nil {
+			return reissued, err
+		}
+		if len(registrations) == 0 {
+			return reissued, nil
+		}
+
+		for i := range registrations {
+			lastID = registrations[i].ID
+			if err := AssignTicketQR(database.DB, &registrations[i], registrations[i].Event); err != nil {
+				return reissued, err
+			}
+			reissued++
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTicketTypeRequired = errors.New("pilih tipe tiket terlebih dahulu")
+	ErrTicketTypeNotFound = errors.New("tipe tiket tidak ditemukan")
+	ErrTicketNotOnSale    = errors.New("tiket ini sedang tidak dijual")
+	ErrTicketSoldOut      = errors.New("kuota tiket ini sudah habis")
+	ErrTicketAccessCode   = errors.New("kode akses tiket tidak valid")
+)
+
+// GetEventTicketTypes mengambil tipe tiket sebuah event sesuai urutan tampil.
+// publicOnly hanya mengambil tiket dengan visibility public.
+func GetEventTicketTypes(eventID uint, publicOnly bool) []models.TicketType {
+	var ticketTypes []models.TicketType
+	query := database.DB.Where("event_id = ?", eventID)
+	if publicOnly {
+		query = query.Where("visibility = ?", models.TicketVisibilityPublic)
+	}
+	query.Order("sort_order ASC, id ASC").Find(&ticketTypes)
+	return ticketTypes
+}
+
+// accessCodeAlphabet tanpa karakter yang mudah tertukar (0/O, 1/I)
+const accessCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// NormalizeTicketAccessCode menyeragamkan penulisan kode akses tiket (tanpa spasi, huruf besar)
+func NormalizeTicketAccessCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// GenerateTicketAccessCode menghasilkan kode akses acak 10 karakter dari crypto/rand
+func GenerateTicketAccessCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = accessCodeAlphabet[int(b)%len(accessCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// CountTicketsSold menghitung registrasi aktif per tipe tiket sebuah event (ticket_type_id -> jumlah)
+func CountTicketsSold(eventID uint) map[uint]int64 {
+	type row struct {
+		TicketTypeID uint
+		Total        int64
+	}
+	var rows []row
+	database.DB.Model(&models.Registration{}).
+		Select("ticket_type_id, COUNT(*) AS total").
+		Where("event_id = ? AND ticket_type_id IS NOT NULL AND status NOT IN ?", eventID, models.ReleasedSeatStatuses).
+		Group("ticket_type_id").
+		Scan(&rows)
+
+	sold := make(map[uint]int64, len(rows))
+	for _, r := range rows {
+		sold[r.TicketTypeID] = r.Total
+	}
+	return sold
+}
+
+// ResolveTicketType memvalidasi tipe tiket yang dipilih saat mendaftar: wajib dipilih jika event
+// punya tipe tiket, harus milik event, dalam periode penjualan, dan kuotanya belum habis.
+// Tiket hidden hanya bisa dipilih dengan accessCode yang sesuai (ID tiket mudah ditebak).
+// Mengembalikan nil untuk event tanpa tipe tiket.
+func ResolveTicketType(eventID uint, ticketTypeID *uint, accessCode string, at time.Time) (*models.TicketType, error) {
+	var count int64
+	database.DB.Model(&models.TicketType{}).Where("event_id = ?", eventID).Count(&count)
+	if count == 0 {
+		return nil, nil
+	}
+	if ticketTypeID == nil {
+		return nil, ErrTicketTypeRequired
+	}
+
+	var ticketType models.TicketType
+	if err := database.DB.Where("id = ? AND event_id = ?", *ticketTypeID, eventID).First(&ticketType).Error; err != nil {
+		return nil, ErrTicketTypeNotFound
+	}
+	if ticketType.Visibility == models.TicketVisibilityHidden {
+		code := NormalizeTicketAccessCode(accessCode)
+		if ticketType.AccessCode == "" || subtle.ConstantTimeCompare([]byte(code), []byte(ticketType.AccessCode)) != 1 {
+			return nil, ErrTicketAccessCode
+		}
+	}
+	if !ticketType.IsOnSale(at) {
+		return nil, ErrTicketNotOnSale
+	}
+	if ticketType.Quota > 0 {
+		var sold int64
+		database.DB.Model(&models.Registration{}).
+			Where("ticket_type_id = ? AND status NOT IN ?", ticketType.ID, models.ReleasedSeatStatuses).
+			Count(&sold)
+		if int(sold) >= ticketType.Quota {
+			return nil, ErrTicketSoldOut
+		}
+	}
+	return &ticketType, nil
+}
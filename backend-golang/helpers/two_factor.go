@@ -0,0 +1,386 @@
+package helpers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"santrikoding/backend-api/config"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	totpPeriod         = 30 // Detik per time-step (RFC 6238)
+	totpDigits         = 6
+	totpSkew           = 1 // Toleransi selisih jam perangkat (± 1 step)
+	recoveryCodeCount  = 10
+	twoFactorChallenge = "2fa_challenge"
+	twoFactorTTL       = 5 * time.Minute // Masa berlaku token tahap kedua login
+)
+
+var (
+	ErrTwoFactorNotEnabled    = errors.New("autentikasi dua faktor belum aktif")
+	ErrInvalidTwoFactorCode   = errors.New("kode autentikasi tidak valid")
+	ErrInvalidTwoFactorTicket = errors.New("sesi verifikasi dua faktor tidak valid atau sudah kadaluarsa")
+)
+
+// GenerateTOTPSecret membuat secret TOTP acak 160-bit dalam format base32 (tanpa padding)
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// TOTPProvisioningURI membuat URI otpauth:// untuk di-scan aplikasi authenticator
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// totpCode menghitung kode TOTP untuk time-step tertentu (HOTP, RFC 4226)
+func totpCode(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// ValidateTOTP mengecek kode terhadap secret dan mengembalikan time-step yang cocok
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		expected, err := totpCode(secret, current+int64(i))
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return current + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// secretKey menurunkan kunci AES-256 dari JWT_SECRET untuk mengenkripsi secret TOTP
+func secretKey() []byte {
+	sum := sha256.Sum256(append([]byte("totp-secret:"), config.JWT_KEY...))
+	return sum[:]
+}
+
+// EncryptSecret mengenkripsi secret TOTP sebelum disimpan ke database
+func EncryptSecret(plain string) (string, error) {
+	block, err := aes.NewCipher(secretKey())
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret membuka secret TOTP yang tersimpan di database
+func DecryptSecret(encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(secretKey())
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("secret terenkripsi tidak valid")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// GetTwoFactor mengambil konfigurasi 2FA user (aktif maupun yang masih menunggu verifikasi)
+func GetTwoFactor(userID uint) (*models.UserTwoFactor, bool) {
+	var tf models.UserTwoFactor
+	if err := database.DB.Where("user_id = ?", userID).First(&tf).Error; err != nil {
+		return nil, false
+	}
+	return &tf, true
+}
+
+// IsTwoFactorEnabled mengecek apakah user sudah mengaktifkan 2FA
+func IsTwoFactorEnabled(userID uint) bool {
+	var count int64
+	database.DB.Model(&models.UserTwoFactor{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL", userID).
+		Count(&count)
+	return count > 0
+}
+
+// IsTwoFactorRequired mengecek apakah role user wajib memakai 2FA
+// (setting two_factor_required_roles berisi kode role dipisah koma, misal "superadmin,bendahara_umum")
+func IsTwoFactorRequired(userID uint) bool {
+	required := GetSetting("two_factor_required_roles")
+	if strings.TrimSpace(required) == "" {
+		return false
+	}
+
+	var roleCode string
+	database.DB.Table("roles").
+		Joins("JOIN users ON users.role_id = roles.id").
+		Where("users.id = ? AND users.deleted_at IS NULL", userID).
+		Limit(1).
+		Pluck("roles.code", &roleCode)
+	if roleCode == "" {
+		return false
+	}
+
+	for _, code := range strings.Split(required, ",") {
+		if strings.EqualFold(strings.TrimSpace(code), roleCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// TwoFactorSetupPending bernilai true jika role user wajib 2FA tetapi user belum mengaktifkannya.
+// Selama itu permission role dan jabatan panitia tidak berlaku (lihat HasPermission / HasEventPermission).
+func TwoFactorSetupPending(userID uint) bool {
+	return IsTwoFactorRequired(userID) && !IsTwoFactorEnabled(userID)
+}
+
+// VerifyTwoFactorCode memvalidasi kode TOTP user yang 2FA-nya aktif.
+// Time-step yang sudah dipakai ditolak supaya kode yang sama tidak bisa dipakai dua kali.
+func VerifyTwoFactorCode(userID uint, code string) error {
+	tf, ok := GetTwoFactor(userID)
+	if !ok || tf.EnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+	return consumeTOTP(tf, code)
+}
+
+// consumeTOTP memvalidasi kode lalu menandai time-step-nya sudah terpakai
+func consumeTOTP(tf *models.UserTwoFactor, code string) error {
+	secret, err := DecryptSecret(tf.SecretEncrypted)
+	if err != nil {
+		return err
+	}
+	step, ok := ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// Update bersyarat: hanya satu request yang bisa memakai time-step ini
+	result := database.DB.Model(&models.UserTwoFactor{}).
+		Where("id = ? AND last_used_step < ?", tf.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// StartTwoFactorEnrollment membuat secret baru yang belum aktif. Mengembalikan secret
+// dalam bentuk plain (hanya ditampilkan sekali ke user untuk di-scan).
+func StartTwoFactorEnrollment(userID uint) (string, error) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := EncryptSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	tf, exists := GetTwoFactor(userID)
+	if exists {
+		if tf.EnabledAt != nil {
+			return "", errors.New("autentikasi dua faktor sudah aktif")
+		}
+		err = database.DB.Model(tf).Updates(map[string]interface{}{
+			"secret_encrypted": encrypted,
+			"last_used_step":   0,
+		}).Error
+	} else {
+		err = database.DB.Create(&models.UserTwoFactor{UserID: userID, SecretEncrypted: encrypted}).Error
+	}
+	if err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// ConfirmTwoFactorEnrollment mengaktifkan 2FA setelah user memasukkan kode pertama yang valid,
+// lalu membuat recovery code baru
+func ConfirmTwoFactorEnrollment(userID uint, code string) ([]string, error) {
+	tf, ok := GetTwoFactor(userID)
+	if !ok {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if tf.EnabledAt != nil {
+		return nil, errors.New("autentikasi dua faktor sudah aktif")
+	}
+	if err := consumeTOTP(tf, code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(tf).Update("enabled_at", now).Error; err != nil {
+		return nil, err
+	}
+	return RegenerateRecoveryCodes(userID)
+}
+
+// DisableTwoFactor menghapus konfigurasi 2FA beserta recovery code user
+func DisableTwoFactor(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes mengganti semua recovery code user. Kode plain hanya dikembalikan sekali.
+func RegenerateRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(b)) // 8 karakter
+		codes = append(codes, raw[:4]+"-"+raw[4:])
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, code := range codes {
+			rc := models.TwoFactorRecoveryCode{UserID: userID, CodeHash: HashToken(normalizeRecoveryCode(code)), CreatedAt: now}
+			if err := tx.Create(&rc).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode memakai satu recovery code (sekali pakai)
+func UseRecoveryCode(userID uint, code string) error {
+	if !IsTwoFactorEnabled(userID) {
+		return ErrTwoFactorNotEnabled
+	}
+	result := database.DB.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, HashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// CountRecoveryCodes menghitung recovery code yang belum dipakai
+func CountRecoveryCodes(userID uint) int64 {
+	var count int64
+	database.DB.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count)
+	return count
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// GenerateTwoFactorTicket membuat token berumur pendek untuk tahap kedua login.
+// Token ini tidak punya claim "sid" sehingga ditolak AuthMiddleware.
+func GenerateTwoFactorTicket(userID uint) (string, time.Time, error) {
+	expiresAt := time.Now().Add(twoFactorTTL)
+	claims := jwt.MapClaims{
+		"sub": userID,
+		"typ": twoFactorChallenge,
+		"iat": jwt.NewNumericDate(time.Now()),
+		"exp": jwt.NewNumericDate(expiresAt),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(config.JWT_KEY)
+	return token, expiresAt, err
+}
+
+// ParseTwoFactorTicket memvalidasi token tahap kedua login dan mengembalikan ID user
+func ParseTwoFactorTicket(ticket string) (uint, error) {
+	token, err := jwt.Parse(ticket, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return config.JWT_KEY, nil
+	})
+	if err != nil || !token.Valid {
+		return 0, ErrInvalidTwoFactorTicket
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != twoFactorChallenge {
+		return 0, ErrInvalidTwoFactorTicket
+	}
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, ErrInvalidTwoFactorTicket
+	}
+	return uint(sub), nil
+}
@@ -1,127 +1,141 @@
 package helpers
 
 import (
+	"crypto/rand"
+	"errors"
 	"fmt"
-	"math/rand"
-	"sync"
+	"math/big"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-// VerificationData menyimpan data verifikasi sementara
-type VerificationData struct {
-	Code           string
-	Email          string
-	Name           string
-	Username       string
-	Password       string
-	Phone          string
-	UniversityID   *uint
-	FacultyID      *uint
-	StudyProgramID *uint
-	Angkatan       string
-	ExpiresAt      time.Time
-}
+const (
+	verificationCodeTTL        = 5 * time.Minute  // Masa berlaku kode (sesuai isi email)
+	verificationMaxAttempts    = 5                // Maksimal kode salah sebelum dikunci
+	verificationLockout        = 15 * time.Minute // Lama dikunci setelah terlalu banyak kode salah
+	verificationResendCooldown = time.Minute      // Jeda minimal kirim ulang kode
+)
 
-// VerificationStore menyimpan kode verifikasi dengan thread-safe map
 var (
-	verificationStore = make(map[string]*VerificationData)
-	verificationMutex = sync.RWMutex{}
+	ErrVerificationNotFound = errors.New("kode verifikasi tidak ditemukan atau sudah kadaluarsa")
+	ErrVerificationExpired  = errors.New("kode verifikasi sudah kadaluarsa")
+	ErrVerificationLocked   = errors.New("terlalu banyak percobaan kode salah, silakan coba lagi nanti")
 )
 
-// GenerateVerificationCode menghasilkan kode verifikasi 6 digit
-func GenerateVerificationCode() string {
-	return fmt.Sprintf("%06d", rand.Intn(1000000))
+// VerificationCooldownError dikembalikan jika kode diminta ulang sebelum cooldown selesai
+type VerificationCooldownError struct {
+	Remaining time.Duration
 }
 
-// StoreVerificationCode menyimpan kode verifikasi dengan expiry 5 menit
-func StoreVerificationCode(email string, code string, userData map[string]interface{}) {
-	verificationMutex.Lock()
-	defer verificationMutex.Unlock()
+func (e *VerificationCooldownError) Error() string {
+	return fmt.Sprintf("tunggu %d detik sebelum meminta kode verifikasi baru", int(e.Remaining.Seconds())+1)
+}
 
-	// Extract ID fields dengan type assertion
-	var universityID, facultyID, studyProgramID *uint
-	if uniID, ok := userData["university_id"]; ok && uniID != nil {
-		if id, ok := uniID.(*uint); ok {
-			universityID = id
-		}
-	}
-	if facID, ok := userData["faculty_id"]; ok && facID != nil {
-		if id, ok := facID.(*uint); ok {
-			facultyID = id
-		}
-	}
-	if spID, ok := userData["study_program_id"]; ok && spID != nil {
-		if id, ok := spID.(*uint); ok {
-			studyProgramID = id
-		}
+// VerificationMismatchError dikembalikan jika kode salah, beserta sisa percobaan
+type VerificationMismatchError struct {
+	RemainingAttempts int
+}
+
+func (e *VerificationMismatchError) Error() string {
+	return fmt.Sprintf("kode verifikasi tidak sesuai (sisa %d percobaan)", e.RemainingAttempts)
+}
+
+// GenerateVerificationCode menghasilkan kode verifikasi 6 digit dari crypto/rand
+func GenerateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
 	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
 
-	verificationStore[email] = &VerificationData{
-		Code:           code,
-		Email:          email,
-		Name:           userData["name"].(string),
-		Username:       userData["username"].(string),
-		Password:       userData["password"].(string),
-		Phone:          getStringOrEmpty(userData, "phone"),
-		UniversityID:   universityID,
-		FacultyID:      facultyID,
-		StudyProgramID: studyProgramID,
-		Angkatan:       getStringOrEmpty(userData, "angkatan"),
-		ExpiresAt:      time.Now().Add(5 * time.Minute),
+// StoreVerificationCode menyimpan (atau memperbarui) pendaftaran yang menunggu verifikasi.
+// pending.PasswordHash harus sudah di-hash oleh pemanggil. Kode lama otomatis tidak berlaku.
+func StoreVerificationCode(pending *models.PendingRegistration, code string) error {
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return err
 	}
 
-	// Cleanup expired codes periodically
-	go cleanupExpiredCodes()
-}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
 
-// Helper function untuk mengambil string dari map atau empty string
-func getStringOrEmpty(m map[string]interface{}, key string) string {
-	if val, ok := m[key]; ok && val != nil {
-		if str, ok := val.(string); ok {
-			return str
+		var existing models.PendingRegistration
+		err := tx.Where("email = ?", pending.Email).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
 		}
-	}
-	return ""
-}
 
-// VerifyCode memverifikasi kode dan mengembalikan data user jika valid
-func VerifyCode(email string, code string) (*VerificationData, error) {
-	verificationMutex.RLock()
-	defer verificationMutex.RUnlock()
+		if err == nil {
+			// Kirim ulang tidak boleh dipakai untuk melewati lockout atau cooldown
+			if existing.LockedUntil != nil && existing.LockedUntil.After(now) {
+				return ErrVerificationLocked
+			}
+			if wait := verificationResendCooldown - now.Sub(existing.LastSentAt); wait > 0 {
+				return &VerificationCooldownError{Remaining: wait}
+			}
+			pending.ID = existing.ID
+			pending.CreatedAt = existing.CreatedAt
+		}
+
+		pending.CodeHash = string(codeHash)
+		pending.ExpiresAt = now.Add(verificationCodeTTL)
+		pending.Attempts = 0
+		pending.LockedUntil = nil
+		pending.LastSentAt = now
+		return tx.Save(pending).Error
+	})
+}
 
-	data, exists := verificationStore[email]
-	if !exists {
-		return nil, fmt.Errorf("kode verifikasi tidak ditemukan atau sudah kadaluarsa")
+// VerifyCode memverifikasi kode dan mengembalikan data pendaftaran jika valid.
+// Setiap kode salah menambah hitungan percobaan; setelah batas tercapai email dikunci sementara.
+func VerifyCode(email string, code string) (*models.PendingRegistration, error) {
+	var pending models.PendingRegistration
+	if err := database.DB.Where("email = ?", email).First(&pending).Error; err != nil {
+		return nil, ErrVerificationNotFound
 	}
 
-	if data.Code != code {
-		return nil, fmt.Errorf("kode verifikasi tidak sesuai")
+	now := time.Now()
+	if pending.LockedUntil != nil && pending.LockedUntil.After(now) {
+		return nil, ErrVerificationLocked
+	}
+	if now.After(pending.ExpiresAt) {
+		return nil, ErrVerificationExpired
 	}
 
-	if time.Now().After(data.ExpiresAt) {
-		delete(verificationStore, email)
-		return nil, fmt.Errorf("kode verifikasi sudah kadaluarsa")
+	if bcrypt.CompareHashAndPassword([]byte(pending.CodeHash), []byte(code)) != nil {
+		// Increment atomik supaya tebakan paralel tetap terhitung semua
+		database.DB.Model(&models.PendingRegistration{}).
+			Where("id = ?", pending.ID).
+			Update("attempts", gorm.Expr("attempts + 1"))
+		database.DB.Select("attempts").First(&pending, pending.ID)
+
+		if pending.Attempts >= verificationMaxAttempts {
+			database.DB.Model(&models.PendingRegistration{}).
+				Where("id = ?", pending.ID).
+				Update("locked_until", now.Add(verificationLockout))
+			return nil, ErrVerificationLocked
+		}
+		return nil, &VerificationMismatchError{RemainingAttempts: verificationMaxAttempts - pending.Attempts}
 	}
 
-	return data, nil
+	return &pending, nil
 }
 
-// DeleteVerificationCode menghapus kode verifikasi setelah digunakan
+// DeleteVerificationCode menghapus data pendaftaran setelah digunakan
 func DeleteVerificationCode(email string) {
-	verificationMutex.Lock()
-	defer verificationMutex.Unlock()
-	delete(verificationStore, email)
+	database.DB.Where("email = ?", email).Delete(&models.PendingRegistration{})
 }
 
-// cleanupExpiredCodes membersihkan kode yang sudah kadaluarsa
-func cleanupExpiredCodes() {
-	verificationMutex.Lock()
-	defer verificationMutex.Unlock()
-
+// CleanupExpiredVerificationCodes menghapus pendaftaran yang kodenya sudah kadaluarsa
+// dan tidak sedang dikunci. Dijalankan secara berkala oleh scheduler.
+func CleanupExpiredVerificationCodes() error {
 	now := time.Now()
-	for email, data := range verificationStore {
-		if now.After(data.ExpiresAt) {
-			delete(verificationStore, email)
-		}
-	}
+	return database.DB.
+		Where("expires_at < ? AND (locked_until IS NULL OR locked_until < ?)", now, now).
+		Delete(&models.PendingRegistration{}).Error
 }
@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"log"
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SeatsTaken menghitung kursi event yang terpakai: registrasi yang masih memakai kuota ditambah
+// tawaran waitlist yang belum kadaluarsa. Tawaran milik excludeUserID tidak dihitung (0 = hitung semua)
+// supaya peserta yang mendapat tawaran bisa memakai kursinya.
+func SeatsTaken(tx *gorm.DB, eventID, excludeUserID uint) int64 {
+	var registered, offered int64
+	tx.Model(&models.Registration{}).
+		Where("event_id = ? AND status NOT IN ?", eventID, models.ReleasedSeatStatuses).
+		Count(&registered)
+	tx.Model(&models.WaitlistEntry{}).
+		Where("event_id = ? AND status = ? AND offer_expires_at > ? AND user_id <> ?",
+			eventID, models.WaitlistOffered, time.Now(), excludeUserID).
+		Count(&offered)
+	return registered + offered
+}
+
+// GetActiveWaitlistEntry mengambil antrian user yang masih menunggu atau sedang ditawari kursi
+func GetActiveWaitlistEntry(eventID, userID uint) (*models.WaitlistEntry, bool) {
+	var entry models.WaitlistEntry
+	if err := database.DB.
+		Where("event_id = ? AND user_id = ? AND status IN ?", eventID, userID,
+			[]string{models.WaitlistWaiting, models.WaitlistOffered}).
+		Order("id DESC").First(&entry).Error; err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// HasValidWaitlistOffer mengecek apakah user sedang memegang tawaran kursi yang belum kadaluarsa
+func HasValidWaitlistOffer(eventID, userID uint) bool {
+	entry, ok := GetActiveWaitlistEntry(eventID, userID)
+	return ok && entry.Status == models.WaitlistOffered &&
+		entry.OfferExpiresAt != nil && entry.OfferExpiresAt.After(time.Now())
+}
+
+// CountWaitlistWaiting menghitung antrian yang masih menunggu kursi
+func CountWaitlistWaiting(eventID uint) int64 {
+	var count int64
+	database.DB.Model(&models.WaitlistEntry{}).
+		Where("event_id = ? AND status = ?", eventID, models.WaitlistWaiting).
+		Count(&count)
+	return count
+}
+
+// WaitlistPosition mengembalikan nomor antrian (mulai dari 1) untuk entry yang masih menunggu
+func WaitlistPosition(entry *models.WaitlistEntry) int64 {
+	var ahead int64
+	database.DB.Model(&models.WaitlistEntry{}).
+		Where("event_id = ? AND status = ? AND id < ?", entry.EventID, models.WaitlistWaiting, entry.ID).
+		Count(&ahead)
+	return ahead + 1
+}
+
+// ClaimWaitlistOffer menandai tawaran kursi user sudah dipakai untuk registrasi (dalam transaksi pendaftaran)
+func ClaimWaitlistOffer(tx *gorm.DB, eventID, userID, registrationID uint) error {
+	return tx.Model(&models.WaitlistEntry{}).
+		Where("event_id = ? AND user_id = ? AND status IN ?", eventID, userID,
+			[]string{models.WaitlistWaiting, models.WaitlistOffered}).
+		Updates(map[string]interface{}{
+			"status":          models.WaitlistClaimed,
+			"registration_id": registrationID,
+		}).Error
+}
+
+// PromoteWaitlist menawarkan kursi kosong event ke antrian terdepan (FIFO) dengan batas waktu klaim
+// waitlist_claim_hours. Dipanggil setiap kali kursi dilepas; aman dipanggil berulang.
+func PromoteWaitlist(eventID uint) {
+	var promoted []models.WaitlistEntry
+	var event models.Event
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris event supaya dua promosi bersamaan tidak menawarkan kursi yang sama
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+			return err
+		}
+		if !event.WaitlistEnabled || event.Quota <= 0 {
+			return nil
+		}
+
+		free := int64(event.Quota) - SeatsTaken(tx, eventID, 0)
+		if free <= 0 {
+			return nil
+		}
+
+		if err := tx.Where("event_id = ? AND status = ?", eventID, models.WaitlistWaiting).
+			Order("id ASC").Limit(int(free)).
+			Find(&promoted).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		expiresAt := now.Add(time.Duration(GetSettingInt("waitlist_claim_hours", 24)) * time.Hour)
+		for i := range promoted {
+			promoted[i].Status = models.WaitlistOffered
+			promoted[i].OfferedAt = &now
+			promoted[i].OfferExpiresAt = &expiresAt
+			if err := tx.Model(&promoted[i]).Updates(map[string]interface{}{
+				"status":           models.WaitlistOffered,
+				"offered_at":       now,
+				"offer_expires_at": expiresAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error promoting waitlist for event %d: %v", eventID, err)
+		return
+	}
+
+	for _, entry := range promoted {
+		NotifyWaitlistOffer(entry.UserID, event.Title, event.Slug, *entry.OfferExpiresAt)
+	}
+}
+
+// ProcessWaitlistOffers mengexpire tawaran kursi yang tidak diklaim lalu menawarkan kursinya ke antrian
+// berikutnya. Juga mempromosikan antrian event yang punya kursi kosong. Dijalankan oleh scheduler.
+func ProcessWaitlistOffers() error {
+	var expired []models.WaitlistEntry
+	if err := database.DB.
+		Where("status = ? AND offer_expires_at <= ?", models.WaitlistOffered, time.Now()).
+		Find(&expired).Error; err != nil {
+		return err
+	}
+
+	for _, entry := range expired {
+		result := database.DB.Model(&models.WaitlistEntry{}).
+			Where("id = ? AND status = ?", entry.ID, models.WaitlistOffered).
+			Update("status", models.WaitlistExpired)
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		var event models.Event
+		if err := database.DB.First(&event, entry.EventID).Error; err == nil {
+			NotifyWaitlistExpired(entry.UserID, event.Title)
+		}
+	}
+
+	var eventIDs []uint
+	if err := database.DB.Model(&models.WaitlistEntry{}).
+		Where("status = ?", models.WaitlistWaiting).
+		Distinct().Pluck("event_id", &eventIDs).Error; err != nil {
+		return err
+	}
+	for _, eventID := range eventIDs {
+		PromoteWaitlist(eventID)
+	}
+
+	if len(expired) > 0 {
+		log.Printf("Waitlist: %d tawaran kursi kadaluarsa", len(expired))
+	}
+	return nil
+}
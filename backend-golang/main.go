@@ -3,11 +3,15 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 
-	// "os" // <-- Hapus ini kalau tidak dipakai (karena kita hardcode port)
 	// "santrikoding/backend-api/config" <-- HAPUS INI (Penyebab Crash)
+	"santrikoding/backend-api/controllers"
 	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/helpers"
 	"santrikoding/backend-api/routes"
+	"santrikoding/backend-api/scheduler"
 )
 
 func main() {
@@ -16,13 +20,31 @@ func main() {
 	// Di Cloud, Environment Variable otomatis terbaca dari sistem,
 	// tidak perlu load file .env lagi. Kalau dipaksa, malah error.
 
-	// 1. Inisialisasi database
+	// Perintah migrasi: go run . migrate up|down [n]|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// Perintah tiket: go run . tickets reissue-qr [--all]
+	if len(os.Args) > 1 && os.Args[1] == "tickets" {
+		runTickets(os.Args[2:])
+		return
+	}
+
+	// 1. Inisialisasi database (migrasi otomatis dijalankan, server batal start jika gagal)
 	database.InitDB()
 
-	// 2. Setup router
+	// 2. Jalankan background job (bisa dimatikan per replica dengan SCHEDULER_ENABLED=false)
+	registerJobs()
+	if os.Getenv("SCHEDULER_ENABLED") != "false" {
+		scheduler.Start()
+	}
+
+	// 3. Setup router
 	r := routes.SetupRouter()
 
-	// 3. Mulai server (LANGSUNG TEMBAK PORT 8080)
+	// 4. Mulai server (LANGSUNG TEMBAK PORT 8080)
 	// Kita hardcode biar DigitalOcean senang dan tidak salah sambung lagi.
 	port := "8080"
 
@@ -35,3 +57,127 @@ func main() {
 		log.Fatal("Gagal menjalankan server:", err)
 	}
 }
+
+// registerJobs mendaftarkan semua background job ke scheduler
+func registerJobs() {
+	scheduler.Register(scheduler.Job{
+		Name:        "auto_complete_events",
+		Description: "Ubah status event yang sudah lewat end_date menjadi done",
+		Spec:        "*/15 * * * *",
+		Run:         controllers.AutoCompleteEvents,
+	})
+	scheduler.Register(scheduler.Job{
+		Name:        "event_reminders",
+		Description: "Kirim reminder H-1 ke peserta terkonfirmasi",
+		Spec:        "0 * * * *",
+		Run:         controllers.SendEventReminders,
+	})
+	scheduler.Register(scheduler.Job{
+		Name:        "task_deadline_reminders",
+		Description: "Kirim notifikasi tugas yang deadline-nya kurang dari 24 jam",
+		Spec:        "30 * * * *",
+		Run:         controllers.SendTaskDeadlineReminders,
+	})
+	scheduler.Register(scheduler.Job{
+		Name:        "verification_code_cleanup",
+		Description: "Hapus pendaftaran dengan kode verifikasi yang sudah kadaluarsa",
+		Spec:        "*/5 * * * *",
+		Run:         helpers.CleanupExpiredVerificationCodes,
+	})
+	scheduler.Register(scheduler.Job{
+		Name:        "session_cleanup",
+		Description: "Hapus sesi login yang sudah kadaluarsa atau dicabut lebih dari 30 hari",
+		Spec:        "15 3 * * *",
+		Run:         helpers.CleanupExpiredSessions,
+	})
+	scheduler.Register(scheduler.Job{
+		Name:        "login_throttle_cleanup",
+		Description: "Hapus catatan login gagal dan lockout yang sudah lewat",
+		Spec:        "45 3 * * *",
+		Run:         helpers.CleanupLoginThrottles,
+	})
+	scheduler.Register(scheduler.Job{
+		Name:        "password_reset_cleanup",
+		Description: "Hapus token reset password yang sudah kadaluarsa",
+		Spec:        "0 4 * * *",
+		Run:         controllers.CleanupPasswordResetTokens,
+	})
+	scheduler.Register(scheduler.Job{
+		Name:        controllers.PaymentReconciliationJob,
+		Description: "Cek ulang order pending ke payment gateway dan expire registrasi yang tidak dibayar",
+		Spec:        "*/10 * * * *",
+		Run:         controllers.ReconcilePayments,
+	})
+	scheduler.Register(scheduler.Job{
+		Name:        "waitlist_offers",
+		Description: "Expire tawaran kursi waitlist yang tidak diklaim dan tawarkan ke antrian berikutnya",
+		Spec:        "*/5 * * * *",
+		Run:         helpers.ProcessWaitlistOffers,
+	})
+}
+
+// runMigrate menjalankan perintah migrasi dari command line lalu keluar
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal("Penggunaan: migrate up | migrate down [jumlah] | migrate status")
+	}
+
+	database.Connect()
+
+	switch args[0] {
+	case "up":
+		if err := database.MigrateUp(); err != nil {
+			log.Fatal("Migrasi gagal: ", err)
+		}
+		fmt.Println("Semua migrasi sudah diterapkan")
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatal("Jumlah langkah rollback tidak valid: ", args[1])
+			}
+			steps = n
+		}
+		rolledBack, err := database.MigrateDown(steps)
+		if err != nil {
+			log.Fatalf("Rollback gagal setelah %d migrasi dibatalkan: %v", rolledBack, err)
+		}
+		fmt.Printf("Rollback %d migrasi selesai\n", rolledBack)
+
+	case "status":
+		status, err := database.GetMigrationStatus()
+		if err != nil {
+			log.Fatal("Gagal membaca status migrasi: ", err)
+		}
+		for _, m := range status {
+			applied := "pending"
+			if m.AppliedAt != nil {
+				applied = m.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", m.Version, m.Name, applied)
+		}
+
+	default:
+		log.Fatal("Perintah migrate tidak dikenal: ", args[0])
+	}
+}
+
+// runTickets menjalankan perintah pemeliharaan QR tiket.
+// reissue-qr menandatangani ulang QR format lama; --all juga QR yang ditandatangani kunci non-aktif
+// (jalankan setelah rotasi TICKET_QR_ACTIVE_KEY sebelum kunci lama dihapus dari TICKET_QR_KEYS).
+func runTickets(args []string) {
+	if len(args) == 0 || args[0] != "reissue-qr" {
+		log.Fatal("Penggunaan: tickets reissue-qr [--all]")
+	}
+	allKeys := len(args) > 1 && args[1] == "--all"
+
+	database.Connect()
+
+	count, err := helpers.ReissueTicketQRCodes(allKeys)
+	if err != nil {
+		log.Fatalf("Gagal menerbitkan ulang QR tiket (%d selesai): %v", count, err)
+	}
+	fmt.Printf("%d QR tiket diterbitkan ulang dengan kunci %s\n", count, helpers.ActiveTicketQRKeyID())
+}
@@ -3,6 +3,7 @@ package middlewares
 import (
 	"net/http"
 	"santrikoding/backend-api/config" // PENTING: Import Config Global
+	"santrikoding/backend-api/helpers"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -46,22 +47,36 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 5. Ambil Data "sub" (ID User) dari Claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// JWT menyimpan angka sebagai float64 secara default
-			// Kita harus ubah ke uint agar bisa dipakai database
-			if sub, ok := claims["sub"].(float64); ok {
-				// SUKSES: Simpan ID ke Context dengan nama "id"
-				c.Set("id", uint(sub))
-			} else {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
-				return
-			}
-		} else {
+		// 5. Ambil Data "sub" (ID User) dan "sid" (ID Sesi) dari Claims
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			return
 		}
 
+		// JWT menyimpan angka sebagai float64 secara default
+		// Kita harus ubah ke uint agar bisa dipakai database
+		sub, ok := claims["sub"].(float64)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
+			return
+		}
+		sid, ok := claims["sid"].(float64)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid session in token"})
+			return
+		}
+
+		// 6. Cek sesi di database (ditolak jika sudah logout / dicabut)
+		if !helpers.ValidateSession(uint(sid), uint(sub)) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked or expired"})
+			return
+		}
+
+		// SUKSES: Simpan ID ke Context dengan nama "id" dan "session_id"
+		c.Set("id", uint(sub))
+		c.Set("session_id", uint(sid))
+
 		c.Next()
 	}
 }
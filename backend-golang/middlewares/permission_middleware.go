@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"net/http"
+	"santrikoding/backend-api/helpers"
+	"santrikoding/backend-api/structs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission - hanya user yang role-nya memiliki permission tersebut yang bisa akses.
+// Dipasang setelah AuthMiddleware.
+func RequirePermission(code string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("id")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, structs.ErrorResponse{
+				Success: false,
+				Message: "Unauthorized",
+				Errors:  map[string]string{"error": "User ID not found in token"},
+			})
+			return
+		}
+
+		if !helpers.HasPermission(userID.(uint), code) {
+			c.AbortWithStatusJSON(http.StatusForbidden, structs.ErrorResponse{
+				Success: false,
+				Message: "Akses ditolak",
+				Errors:  map[string]string{"error": "Permission " + code + " diperlukan"},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
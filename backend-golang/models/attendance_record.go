@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Cara peserta tercatat hadir di sebuah sesi
+const (
+	AttendanceMethodScan        = "scan"         // Scan QR oleh panitia
+	AttendanceMethodSelf        = "self"         // Self check-in peserta (event online)
+	AttendanceMethodOfflineSync = "offline_sync" // Scan offline yang disinkronkan belakangan
+	AttendanceMethodManual      = "manual"       // Ditandai manual oleh panitia
+)
+
+// AttendanceRecord mencatat kehadiran satu registrasi di satu sesi event (check-in dan check-out)
+type AttendanceRecord struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	RegistrationID uint       `json:"registration_id" gorm:"not null;uniqueIndex:idx_attendance_records_registration_session"`
+	EventSessionID uint       `json:"event_session_id" gorm:"not null;uniqueIndex:idx_attendance_records_registration_session;index"`
+	EventID        uint       `json:"event_id" gorm:"not null;index"`
+	CheckInAt      time.Time  `json:"check_in_at"`
+	CheckOutAt     *time.Time `json:"check_out_at"`
+	Method         string     `json:"method" gorm:"size:20"`
+	RecordedByID   *uint      `json:"recorded_by_id"` // Panitia yang mencatat (kosong untuk self check-in)
+	ProofURL       string     `json:"proof_url" gorm:"type:text"`
+
+	EventSession *EventSession `json:"event_session,omitempty" gorm:"foreignKey:EventSessionID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
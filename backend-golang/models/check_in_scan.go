@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Hasil pemrosesan satu scan QR dari perangkat scanner
+const (
+	ScanResultCheckedIn   = "checked_in"   // Check-in berhasil
+	ScanResultDuplicate   = "duplicate"    // Peserta sudah check-in (scan ganda, bisa dari perangkat lain)
+	ScanResultInvalid     = "invalid"      // QR tidak valid / tidak ditemukan
+	ScanResultWrongEvent  = "wrong_event"  // QR milik event lain
+	ScanResultNotEligible = "not_eligible" // Registrasi belum lunas/dikonfirmasi atau sudah dibatalkan
+)
+
+// CheckInScan mencatat scan QR yang dikirim perangkat scanner lewat sinkronisasi offline.
+// (device_id, client_scan_id) unik sehingga batch yang dikirim ulang tidak diproses dua kali.
+type CheckInScan struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	EventID        uint      `json:"event_id" gorm:"not null;index"`
+	RegistrationID *uint     `json:"registration_id" gorm:"index"`
+	EventSessionID *uint     `json:"event_session_id"` // Sesi yang di-scan (event multi-sesi)
+	DeviceID       string    `json:"device_id" gorm:"size:100;not null;uniqueIndex:idx_check_in_scans_device_scan"`
+	ClientScanID   string    `json:"client_scan_id" gorm:"size:100;not null;uniqueIndex:idx_check_in_scans_device_scan"`
+	QRHash         string    `json:"qr_hash" gorm:"size:64"`
+	ScannedAt      time.Time `json:"scanned_at"`
+	ScannedByID    uint      `json:"scanned_by_id"`
+	Result         string    `json:"result" gorm:"size:20;not null"`
+	Message        string    `json:"message" gorm:"size:255"`
+	CreatedAt      time.Time `json:"created_at"` // Waktu scan diterima server
+}
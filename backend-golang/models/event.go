@@ -5,6 +5,12 @@ import (
 	// "gorm.io/gorm" <-- Hapus baris ini
 )
 
+// Metode pembayaran event berbayar
+const (
+	EventPaymentGateway        = "gateway"         // Lewat payment provider (Midtrans / fake)
+	EventPaymentManualTransfer = "manual_transfer" // Transfer bank dengan upload bukti, diverifikasi bendahara
+)
+
 type Event struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
 	Title       string    `json:"title" gorm:"size:255;not null"`
@@ -17,6 +23,16 @@ type Event struct {
 
 	RegistrationDeadline *time.Time `json:"registration_deadline"` // Tenggat waktu pendaftaran (nullable)
 
+	// Kebijakan refund saat peserta membatalkan pendaftaran
+	RefundDeadline   *time.Time `json:"refund_deadline"`                    // Batas waktu pembatalan dengan refund (nullable = sampai event dimulai)
+	RefundPercentage int        `json:"refund_percentage" gorm:"default:0"` // Persentase harga yang dikembalikan (0-100)
+
+	// Metode pembayaran event berbayar: "gateway" (payment provider) atau "manual_transfer"
+	PaymentMethod         string `json:"payment_method" gorm:"size:30;default:gateway"`
+	TransferBankName      string `json:"transfer_bank_name" gorm:"size:100"` // Rekening tujuan transfer manual
+	TransferAccountNumber string `json:"transfer_account_number" gorm:"size:50"`
+	TransferAccountName   string `json:"transfer_account_name" gorm:"size:255"`
+
 	Status    string `json:"status" gorm:"default:'draft'"`
 	EventType string `json:"event_type" gorm:"default:'offline'"` // "offline", "online" (webinar/zoom), atau "hybrid" (keduanya)
 	Quota     int    `json:"quota"`
@@ -24,6 +40,17 @@ type Event struct {
 	Price     int64  `json:"price" gorm:"default:0"`    // Harga event dalam rupiah
 	Speakers  string `json:"speakers" gorm:"type:text"` // JSON array of speakers (name, title, photo)
 
+	WaitlistEnabled bool `json:"waitlist_enabled"` // Terima antrian (waitlist) saat kuota penuh
+
+	// Transfer tiket ke user lain oleh peserta yang berhalangan hadir
+	TransferEnabled  bool       `json:"transfer_enabled"`
+	TransferDeadline *time.Time `json:"transfer_deadline"` // Batas waktu transfer tiket (nullable = sampai event dimulai)
+
+	// Syarat sertifikat: persentase minimal sesi yang dihadiri (0 = tanpa syarat kehadiran)
+	CertificateMinAttendance int `json:"certificate_min_attendance" gorm:"default:0"`
+
+	ReminderSentAt *time.Time `json:"reminder_sent_at"` // Diisi job reminder H-1 agar tidak terkirim dua kali
+
 	CreatedByID uint `json:"created_by_id"`
 	CreatedBy   User `json:"created_by" gorm:"foreignKey:CreatedByID"`
 
@@ -32,6 +59,7 @@ type Event struct {
 	Tasks      []Task            `json:"tasks" gorm:"foreignKey:EventID"`
 	Budgets    []Budget          `json:"budgets" gorm:"foreignKey:EventID"`
 	Loans      []Loan            `json:"loans" gorm:"foreignKey:EventID"`
+	Sessions   []EventSession    `json:"sessions" gorm:"foreignKey:EventID"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// EventSession adalah satu sesi/hari dalam event multi-sesi (workshop beberapa sesi, event beberapa hari).
+// Event tanpa sesi tetap memakai check-in tunggal di Registration.
+type EventSession struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	EventID     uint      `json:"event_id" gorm:"not null;index"`
+	Title       string    `json:"title" gorm:"size:255;not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	Location    string    `json:"location" gorm:"size:255"` // Kosong = mengikuti lokasi event
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	SortOrder   int       `json:"sort_order" gorm:"default:0"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
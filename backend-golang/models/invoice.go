@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Invoice adalah kuitansi pembayaran sebuah registrasi berbayar.
+// Data pembeli, event dan penyelenggara disalin saat invoice dibuat supaya PDF yang
+// diunduh ulang tetap sama walaupun data aslinya berubah.
+type Invoice struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	Number         string    `json:"number" gorm:"size:50;uniqueIndex;not null"` // Contoh: INV/2026/000001
+	Year           int       `json:"year" gorm:"not null"`
+	Sequence       uint      `json:"sequence" gorm:"not null"` // Nomor urut dalam tahun berjalan
+	RegistrationID uint      `json:"registration_id" gorm:"uniqueIndex;not null"`
+	PaymentID      uint      `json:"payment_id" gorm:"index"`
+	UserID         uint      `json:"user_id" gorm:"index"`
+	EventID        uint      `json:"event_id"`
+	BilledName     string    `json:"billed_name" gorm:"size:255"`
+	BilledEmail    string    `json:"billed_email" gorm:"size:255"`
+	EventTitle     string    `json:"event_title" gorm:"size:255"`
+	OrderID        string    `json:"order_id" gorm:"size:100"`
+	Amount         int64     `json:"amount"`
+	PaymentMethod  string    `json:"payment_method" gorm:"size:50"`
+	PaidAt         time.Time `json:"paid_at"`
+
+	OrganizerName    string `json:"organizer_name" gorm:"size:255"`
+	OrganizerAddress string `json:"organizer_address" gorm:"size:500"`
+	OrganizerEmail   string `json:"organizer_email" gorm:"size:255"`
+	OrganizerPhone   string `json:"organizer_phone" gorm:"size:50"`
+
+	EmailSentAt *time.Time `json:"email_sent_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// InvoiceCounter menyimpan nomor urut invoice terakhir per tahun
+type InvoiceCounter struct {
+	Year       int  `json:"year" gorm:"primaryKey;autoIncrement:false"`
+	LastNumber uint `json:"last_number"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// JobLock adalah baris kunci per job agar satu jadwal hanya dijalankan oleh satu replica
+type JobLock struct {
+	Name        string     `json:"name" gorm:"primaryKey;size:100"`
+	LockedBy    string     `json:"locked_by" gorm:"size:255"` // hostname-pid instance yang memegang kunci
+	LockedUntil *time.Time `json:"locked_until"`              // Kunci otomatis lepas setelah waktu ini
+	LastTickAt  *time.Time `json:"last_tick_at"`              // Jadwal (menit) terakhir yang sudah diambil
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// JobRun menyimpan riwayat eksekusi job
+type JobRun struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	JobName     string     `json:"job_name" gorm:"size:100;index;not null"`
+	Trigger     string     `json:"trigger" gorm:"size:20;not null"` // schedule, manual
+	TriggeredBy *uint      `json:"triggered_by"`                    // User yang menjalankan manual (nullable)
+	Instance    string     `json:"instance" gorm:"size:255"`
+	Status      string     `json:"status" gorm:"size:20;not null"` // running, success, failed
+	Error       string     `json:"error" gorm:"type:text"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DurationMs  int64      `json:"duration_ms"`
+}
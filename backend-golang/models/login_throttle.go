@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LoginThrottle mencatat percobaan login gagal per akun atau per IP
+type LoginThrottle struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Scope        string     `json:"scope" gorm:"size:20;not null;uniqueIndex:idx_login_throttles_scope_key"` // account, ip
+	Key          string     `json:"key" gorm:"size:191;not null;uniqueIndex:idx_login_throttles_scope_key"`  // id:<user id>, name:<identifier>, atau alamat IP
+	UserID       *uint      `json:"user_id"`                                                                 // Diisi jika scope account dan user dikenal
+	FailedCount  int        `json:"failed_count" gorm:"default:0"`
+	LastFailedAt *time.Time `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -18,6 +18,14 @@ const (
 	NotifCertificateReady      NotificationType = "certificate_ready"      // Sertifikat siap download
 	NotifPaymentSuccess        NotificationType = "payment_success"        // Pembayaran berhasil
 	NotifPaymentPending        NotificationType = "payment_pending"        // Menunggu pembayaran
+	NotifRegistrationCancelled NotificationType = "registration_cancelled" // Pendaftaran dibatalkan
+	NotifRefundProcessed       NotificationType = "refund_processed"       // Dana tiket dikembalikan
+	NotifPaymentProofRejected  NotificationType = "payment_proof_rejected" // Bukti transfer ditolak
+	NotifWaitlistOffer         NotificationType = "waitlist_offer"         // Kursi dari waitlist tersedia
+	NotifWaitlistExpired       NotificationType = "waitlist_expired"       // Batas waktu klaim kursi waitlist lewat
+	NotifTransferRequested     NotificationType = "transfer_requested"     // Ditawari transfer tiket dari peserta lain
+	NotifTransferAccepted      NotificationType = "transfer_accepted"      // Transfer tiket diterima
+	NotifTransferDeclined      NotificationType = "transfer_declined"      // Transfer tiket ditolak penerima
 
 	// Notifikasi untuk Panitia
 	NotifNewRegistration NotificationType = "new_registration" // Ada pendaftar baru
@@ -30,6 +38,8 @@ const (
 	NotifBudgetApproval  NotificationType = "budget_approval"  // Request approval anggaran
 	NotifCommitteeAdded  NotificationType = "committee_added"  // Ditambahkan sebagai panitia
 	NotifEventPublished  NotificationType = "event_published"  // Event dipublish
+	NotifPaymentProof    NotificationType = "payment_proof"    // Bukti transfer menunggu verifikasi
+	NotifTicketTransfer  NotificationType = "ticket_transfer"  // Tiket peserta dipindahkan ke user lain
 )
 
 // Notification menyimpan notifikasi untuk user
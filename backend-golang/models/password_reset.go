@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PasswordResetToken adalah token sekali pakai untuk reset password lewat email.
+// Yang disimpan hanya hash SHA-256 dari token yang dikirim di link.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"` // Diisi saat dipakai atau dibatalkan oleh token yang lebih baru
+	IPAddress string     `json:"ip_address" gorm:"size:50"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Status pembayaran (dinormalisasi dari status masing-masing provider)
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusChallenge = "challenge" // Ditahan fraud detection, butuh verifikasi manual
+	PaymentStatusPaid      = "paid"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusExpired   = "expired"
+	PaymentStatusCancelled = "cancelled"
+	PaymentStatusRefunded  = "refunded"
+)
+
+// PaymentProviderManual adalah nilai payments.provider untuk transfer bank manual
+// (tidak lewat payment gateway, diverifikasi bendahara dari bukti transfer)
+const PaymentProviderManual = "manual"
+
+// Payment adalah satu percobaan pembayaran untuk sebuah registrasi.
+// Satu registrasi bisa punya banyak payment (retry, expired, gagal) sehingga riwayatnya tidak hilang.
+type Payment struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	RegistrationID uint         `json:"registration_id" gorm:"not null;index"`
+	Registration   Registration `json:"-" gorm:"foreignKey:RegistrationID"`
+	Provider       string       `json:"provider" gorm:"size:30;not null;default:midtrans"`
+	OrderID        string       `json:"order_id" gorm:"size:100;uniqueIndex;not null"`
+	Amount         int64        `json:"amount"` // Nominal dalam rupiah
+	Status         string       `json:"status" gorm:"size:20;not null;default:pending;index"`
+	ProviderStatus string       `json:"provider_status" gorm:"size:30"` // Status mentah dari provider (settlement, expire, ...)
+	PaymentType    string       `json:"payment_type" gorm:"size:50"`
+	TransactionID  string       `json:"transaction_id" gorm:"size:100"`
+	SnapToken      string       `json:"snap_token" gorm:"size:255"`
+	RedirectURL    string       `json:"redirect_url" gorm:"size:255"`
+	RawResponse    string       `json:"-" gorm:"type:text"` // Respons/notifikasi terakhir dari provider
+	PaidAt         *time.Time   `json:"paid_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+
+	StatusHistory []PaymentStatusHistory `json:"status_history,omitempty" gorm:"foreignKey:PaymentID"`
+}
+
+// PaymentStatusHistory mencatat setiap perubahan status payment beserta sumbernya
+type PaymentStatusHistory struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	PaymentID      uint      `json:"payment_id" gorm:"not null;index"`
+	FromStatus     string    `json:"from_status" gorm:"size:20"`
+	ToStatus       string    `json:"to_status" gorm:"size:20"`
+	ProviderStatus string    `json:"provider_status" gorm:"size:30"`
+	Source         string    `json:"source" gorm:"size:30"` // initiate, notification, status_check
+	Note           string    `json:"note" gorm:"size:255"`
+	RawPayload     string    `json:"-" gorm:"type:text"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PaymentNotification menyimpan setiap payload webhook dari payment gateway apa adanya,
+// termasuk yang ditolak, untuk audit dan investigasi.
+type PaymentNotification struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	Provider          string     `json:"provider" gorm:"size:30;not null;default:midtrans"`
+	PaymentID         *uint      `json:"payment_id" gorm:"index"` // Diisi jika order_id cocok dengan payment
+	OrderID           string     `json:"order_id" gorm:"size:100;index"`
+	TransactionID     string     `json:"transaction_id" gorm:"size:100"`
+	TransactionStatus string     `json:"transaction_status" gorm:"size:30"`
+	FraudStatus       string     `json:"fraud_status" gorm:"size:30"`
+	StatusCode        string     `json:"status_code" gorm:"size:10"`
+	GrossAmount       string     `json:"gross_amount" gorm:"size:30"`
+	SignatureValid    bool       `json:"signature_valid" gorm:"default:false"`
+	DedupeKey         *string    `json:"dedupe_key" gorm:"size:150;uniqueIndex"` // order_id:transaction_status, hanya diisi untuk notifikasi valid yang diproses
+	Result            string     `json:"result" gorm:"size:30"`                  // received, processed, duplicate, invalid_signature, amount_mismatch, invalid_payload, error
+	Message           string     `json:"message" gorm:"size:255"`
+	RawPayload        string     `json:"raw_payload" gorm:"type:text"`
+	IPAddress         string     `json:"ip_address" gorm:"size:50"`
+	ProcessedAt       *time.Time `json:"processed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
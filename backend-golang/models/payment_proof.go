@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Status bukti transfer
+const (
+	PaymentProofPending  = "pending"
+	PaymentProofApproved = "approved"
+	PaymentProofRejected = "rejected"
+)
+
+// PaymentProof adalah bukti transfer yang diupload peserta untuk payment manual.
+// Bukti yang ditolak tetap disimpan sebagai riwayat, peserta bisa mengupload bukti baru.
+type PaymentProof struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	PaymentID      uint         `json:"payment_id" gorm:"not null;index"`
+	RegistrationID uint         `json:"registration_id" gorm:"not null;index"`
+	Registration   Registration `json:"registration,omitempty" gorm:"foreignKey:RegistrationID"`
+	FilePath       string       `json:"-" gorm:"size:255;not null"` // Disimpan di luar folder public, diunduh lewat endpoint
+	OriginalName   string       `json:"original_name" gorm:"size:255"`
+	SenderName     string       `json:"sender_name" gorm:"size:255"` // Nama pemilik rekening pengirim
+	SenderBank     string       `json:"sender_bank" gorm:"size:100"`
+	Amount         int64        `json:"amount"` // Nominal yang ditransfer menurut peserta
+	Note           string       `json:"note" gorm:"size:255"`
+	Status         string       `json:"status" gorm:"size:20;not null;default:pending;index"`
+	RejectReason   string       `json:"reject_reason" gorm:"size:255"`
+	ReviewedByID   *uint        `json:"reviewed_by_id"`
+	ReviewedAt     *time.Time   `json:"reviewed_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// PaymentReconciliationRun adalah ringkasan satu kali eksekusi job rekonsiliasi pembayaran
+type PaymentReconciliationRun struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Checked    int        `json:"checked"`    // Jumlah payment yang dicek ke provider
+	Updated    int        `json:"updated"`    // Payment yang statusnya berubah
+	Confirmed  int        `json:"confirmed"`  // Registrasi yang terkonfirmasi karena webhook-nya hilang
+	Expired    int        `json:"expired"`    // Payment / registrasi yang kadaluarsa karena tidak dibayar
+	Mismatched int        `json:"mismatched"` // Nominal dari provider tidak sesuai tagihan
+	Failed     int        `json:"failed"`     // Gagal dicek (error provider / database)
+
+	Items []PaymentReconciliationItem `json:"items,omitempty" gorm:"foreignKey:RunID"`
+}
+
+// PaymentReconciliationItem mencatat hasil rekonsiliasi untuk satu payment / registrasi
+type PaymentReconciliationItem struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	RunID          uint      `json:"run_id" gorm:"not null;index"`
+	PaymentID      *uint     `json:"payment_id" gorm:"index"` // Kosong untuk registrasi yang belum punya payment
+	RegistrationID uint      `json:"registration_id"`
+	OrderID        string    `json:"order_id" gorm:"size:100"`
+	Action         string    `json:"action" gorm:"size:30;index"` // unchanged, updated, expired, registration_expired, amount_mismatch, error
+	FromStatus     string    `json:"from_status" gorm:"size:20"`
+	ToStatus       string    `json:"to_status" gorm:"size:20"`
+	ProviderStatus string    `json:"provider_status" gorm:"size:30"`
+	Message        string    `json:"message" gorm:"size:255"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PendingRegistration menyimpan data pendaftaran yang menunggu verifikasi kode email.
+// Password dan kode verifikasi hanya disimpan dalam bentuk hash.
+type PendingRegistration struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Email          string     `json:"email" gorm:"size:191;uniqueIndex;not null"`
+	Name           string     `json:"name" gorm:"size:255"`
+	Username       string     `json:"username" gorm:"size:191"`
+	PasswordHash   string     `json:"-" gorm:"size:255"`
+	Phone          string     `json:"phone" gorm:"size:50"`
+	UniversityID   *uint      `json:"university_id"`
+	FacultyID      *uint      `json:"faculty_id"`
+	StudyProgramID *uint      `json:"study_program_id"`
+	Angkatan       string     `json:"angkatan" gorm:"size:20"`
+	CodeHash       string     `json:"-" gorm:"size:255;not null"` // bcrypt dari kode 6 digit
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Attempts       int        `json:"attempts" gorm:"default:0"` // Percobaan kode salah untuk kode saat ini
+	LockedUntil    *time.Time `json:"locked_until"`              // Diisi jika terlalu banyak kode salah
+	LastSentAt     time.Time  `json:"last_sent_at"`              // Untuk cooldown kirim ulang
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Permission adalah hak akses yang bisa diberikan ke role, misal "budget.approve"
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Code        string    `json:"code" gorm:"size:100;uniqueIndex;not null"`
+	Name        string    `json:"name" gorm:"size:255;not null"`
+	Description string    `json:"description" gorm:"size:255"`
+	Category    string    `json:"category" gorm:"size:50"` // system, users, master, event, inventory
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RolePermission menghubungkan role dengan permission yang dimilikinya
+type RolePermission struct {
+	RoleID       uint       `json:"role_id" gorm:"primaryKey"`
+	PermissionID uint       `json:"permission_id" gorm:"primaryKey"`
+	Role         Role       `json:"-" gorm:"foreignKey:RoleID"`
+	Permission   Permission `json:"permission" gorm:"foreignKey:PermissionID"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
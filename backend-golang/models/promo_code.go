@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Jenis potongan promo
+const (
+	PromoDiscountPercentage = "percentage"
+	PromoDiscountFixed      = "fixed"
+)
+
+// PromoCode adalah kode diskon untuk event berbayar (misal potongan anggota organisasi mahasiswa).
+// Bisa dibatasi ke satu event dan/atau tipe tiket tertentu.
+type PromoCode struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	Code          string       `json:"code" gorm:"size:50;not null;uniqueIndex"` // Selalu huruf besar
+	Description   string       `json:"description" gorm:"size:255"`
+	DiscountType  string       `json:"discount_type" gorm:"size:20;not null"`
+	DiscountValue int64        `json:"discount_value"`                // Persen (1-100) atau nominal rupiah
+	MaxDiscount   int64        `json:"max_discount" gorm:"default:0"` // Batas potongan untuk tipe persentase, 0 = tanpa batas
+	MaxUses       int          `json:"max_uses" gorm:"default:0"`     // 0 = tanpa batas
+	PerUserLimit  int          `json:"per_user_limit"`                // 0 = tanpa batas
+	ValidFrom     *time.Time   `json:"valid_from"`
+	ValidUntil    *time.Time   `json:"valid_until"`
+	EventID       *uint        `json:"event_id" gorm:"index"` // Kosong = berlaku untuk semua event berbayar
+	Event         *Event       `json:"event,omitempty" gorm:"foreignKey:EventID"`
+	TicketTypes   []TicketType `json:"ticket_types" gorm:"many2many:promo_code_ticket_types"` // Kosong = semua tipe tiket
+	IsActive      bool         `json:"is_active"`
+	CreatedByID   *uint        `json:"created_by_id"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// PromoCodeUsage mencatat pemakaian kode promo oleh sebuah registrasi.
+// Pemakaian oleh registrasi yang dibatalkan/ditolak tidak dihitung ke batas pemakaian.
+type PromoCodeUsage struct {
+	ID             uint          `json:"id" gorm:"primaryKey"`
+	PromoCodeID    uint          `json:"promo_code_id" gorm:"not null;index"`
+	RegistrationID uint          `json:"registration_id" gorm:"not null;uniqueIndex"`
+	Registration   *Registration `json:"registration,omitempty" gorm:"foreignKey:RegistrationID"`
+	UserID         uint          `json:"user_id" gorm:"not null;index"`
+	EventID        uint          `json:"event_id" gorm:"not null;index"`
+	OriginalPrice  int64         `json:"original_price"`
+	DiscountAmount int64         `json:"discount_amount"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
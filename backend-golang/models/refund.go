@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Status refund
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+// Refund adalah permintaan pengembalian dana untuk payment yang sudah lunas (satu per payment).
+// RefundKey dikirim ke provider sebagai kunci idempoten, sehingga retry refund yang gagal
+// memakai key yang sama dan tidak mengembalikan dana dua kali.
+type Refund struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	PaymentID      uint       `json:"payment_id" gorm:"not null;uniqueIndex"`
+	RegistrationID uint       `json:"registration_id" gorm:"not null;index"`
+	Amount         int64      `json:"amount"`     // Nominal refund dalam rupiah
+	Percentage     int        `json:"percentage"` // Persentase dari nominal payment
+	Reason         string     `json:"reason" gorm:"size:255"`
+	Status         string     `json:"status" gorm:"size:20;not null;default:pending;index"`
+	RefundKey      string     `json:"refund_key" gorm:"size:100;uniqueIndex"`
+	ProviderStatus string     `json:"provider_status" gorm:"size:30"`
+	RawResponse    string     `json:"-" gorm:"type:text"`
+	ErrorMessage   string     `json:"error_message" gorm:"size:255"`
+	RequestedByID  *uint      `json:"requested_by_id"`
+	ProcessedAt    *time.Time `json:"processed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -2,34 +2,84 @@ package models
 
 import "time"
 
+// InactiveRegistrationStatuses adalah status registrasi yang sudah dibatalkan,
+// tidak dihitung ke kuota dan boleh didaftarkan ulang
+var InactiveRegistrationStatuses = []string{"cancelled", "refunded"}
+
+// ReleasedSeatStatuses adalah status registrasi yang tidak lagi memakai kuota event/tiket
+// (ditolak, termasuk karena pembayaran kadaluarsa, atau dibatalkan)
+var ReleasedSeatStatuses = append([]string{"rejected"}, InactiveRegistrationStatuses...)
+
 // Sesuai tabel `registrations` di db_golang.sql
 type Registration struct {
 	ID      uint `json:"id" gorm:"primaryKey"`
 	EventID uint `json:"event_id"`
 	UserID  uint `json:"user_id"`
 
-	Status             string `json:"status" gorm:"default:pending"` // pending, confirmed, rejected
+	Status             string `json:"status" gorm:"default:pending"` // pending, confirmed, rejected, cancelled, refunded
 	Attendance         bool   `json:"attendance" gorm:"default:false"`
 	AttendanceType     string `json:"attendance_type"`                // "offline" (scan QR) atau "online" (self check-in) - untuk event hybrid
 	AttendanceProofURL string `json:"attendance_proof_url"`           // URL bukti kehadiran (screenshot zoom, dll)
 	QRCode             string `json:"qr_code" gorm:"size:191;unique"` // Menyimpan kode unik tiket
 	CertificateURL     string `json:"certificate_url"`
 
-	// Payment fields
-	PaymentToken string    `json:"payment_token"` // Untuk Snap Token
-	PaymentURL   string    `json:"payment_url"`   // URL Redirect (Opsional)
-	OrderID      string    `json:"order_id"`      // Order ID dari Midtrans
-	PaidAt       time.Time `*json:"paid_at"`      // Waktu pembayaran sukses
+	// Tipe tiket yang dipilih (kosong untuk event tanpa tipe tiket) dan harga yang harus dibayar saat mendaftar
+	TicketTypeID *uint `json:"ticket_type_id" gorm:"index"`
+	Price        int64 `json:"price" gorm:"default:0"`
+
+	// Pendaftaran grup: satu pendaftar mendaftarkan beberapa peserta sekaligus. Peserta tamu (tanpa akun)
+	// dicatat atas nama pendaftar (UserID) dengan nama/email tamu; kosong untuk peserta biasa.
+	GroupID    *uint  `json:"group_id" gorm:"index"`
+	GuestName  string `json:"guest_name" gorm:"size:100"`
+	GuestEmail string `json:"guest_email" gorm:"size:191;not null;default:''"`
+
+	// Waktu check-in pertama (scan online maupun hasil sinkronisasi scan offline)
+	CheckedInAt *time.Time `json:"checked_in_at"`
+
+	// Ringkasan pembayaran; detail tiap percobaan ada di tabel payments
+	PaidAt time.Time `*json:"paid_at"` // Waktu pembayaran sukses
 
 	// Relasi
-	Event   Event        `json:"event" gorm:"foreignKey:EventID"`
-	User    User         `json:"user" gorm:"foreignKey:UserID"`
-	Answers []FormAnswer `json:"answers" gorm:"foreignKey:RegistrationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Event      Event        `json:"event" gorm:"foreignKey:EventID"`
+	User       User         `json:"user" gorm:"foreignKey:UserID"`
+	TicketType *TicketType  `json:"ticket_type,omitempty" gorm:"foreignKey:TicketTypeID"`
+	Answers    []FormAnswer `json:"answers" gorm:"foreignKey:RegistrationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Payments   []Payment    `json:"payments,omitempty" gorm:"foreignKey:RegistrationID"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// IsGuest bernilai true untuk peserta tamu yang didaftarkan lewat pendaftaran grup
+func (r Registration) IsGuest() bool {
+	return r.GuestEmail != ""
+}
+
+// AttendeeName mengembalikan nama peserta tiket (nama tamu atau nama user; User harus di-preload)
+func (r Registration) AttendeeName() string {
+	if r.IsGuest() {
+		return r.GuestName
+	}
+	return r.User.Name
+}
+
+// CheckInTime mengembalikan waktu check-in peserta. Registrasi yang check-in sebelum kolom
+// checked_in_at ada memakai waktu update terakhir.
+func (r Registration) CheckInTime() time.Time {
+	if r.CheckedInAt != nil {
+		return *r.CheckedInAt
+	}
+	return r.UpdatedAt
+}
+
+// AttendeeEmail mengembalikan email peserta tiket (email tamu atau email user; User harus di-preload)
+func (r Registration) AttendeeEmail() string {
+	if r.IsGuest() {
+		return r.GuestEmail
+	}
+	return r.User.Email
+}
+
 // Sesuai tabel `form_answers` di db_golang.sql
 type FormAnswer struct {
 	ID             uint `json:"id" gorm:"primaryKey"`
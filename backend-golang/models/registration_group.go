@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// RegistrationGroup adalah pendaftaran beberapa peserta sekaligus oleh satu pendaftar (misal delegasi
+// organisasi). Setiap peserta tetap punya registrasi dan QR tiket sendiri; tagihan seluruh grup
+// dibayar sekali lewat registrasi ketua (LeadRegistrationID).
+type RegistrationGroup struct {
+	ID                 uint   `json:"id" gorm:"primaryKey"`
+	EventID            uint   `json:"event_id" gorm:"not null;index"`
+	Event              Event  `json:"event,omitempty" gorm:"foreignKey:EventID"`
+	RegistrantID       uint   `json:"registrant_id" gorm:"not null;index"`
+	Registrant         User   `json:"registrant,omitempty" gorm:"foreignKey:RegistrantID"`
+	Name               string `json:"name" gorm:"size:150"` // Nama organisasi/delegasi (opsional)
+	TicketTypeID       *uint  `json:"ticket_type_id"`
+	Quantity           int    `json:"quantity"`
+	TotalPrice         int64  `json:"total_price"`
+	LeadRegistrationID *uint  `json:"lead_registration_id"` // Registrasi yang menanggung pembayaran grup
+
+	Registrations []Registration `json:"registrations,omitempty" gorm:"foreignKey:GroupID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Status transfer tiket
+const (
+	TransferPending   = "pending"   // Menunggu penerima menerima/menolak
+	TransferAccepted  = "accepted"  // Tiket sudah pindah ke penerima
+	TransferDeclined  = "declined"  // Ditolak penerima
+	TransferCancelled = "cancelled" // Dibatalkan pemilik tiket
+	TransferExpired   = "expired"   // Tidak direspon sampai batas waktu transfer event
+)
+
+// RegistrationTransfer adalah permintaan pemilik tiket untuk memindahkan registrasinya ke user lain.
+// Saat diterima, registrasi berpindah ke penerima dengan QR code baru dan jawaban form dari penerima.
+type RegistrationTransfer struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	RegistrationID uint         `json:"registration_id" gorm:"not null;index"`
+	Registration   Registration `json:"registration,omitempty" gorm:"foreignKey:RegistrationID"`
+	EventID        uint         `json:"event_id" gorm:"not null;index"`
+	Event          Event        `json:"event,omitempty" gorm:"foreignKey:EventID"`
+	FromUserID     uint         `json:"from_user_id" gorm:"not null;index"`
+	FromUser       User         `json:"from_user,omitempty" gorm:"foreignKey:FromUserID"`
+	ToUserID       uint         `json:"to_user_id" gorm:"not null;index"`
+	ToUser         User         `json:"to_user,omitempty" gorm:"foreignKey:ToUserID"`
+	Status         string       `json:"status" gorm:"size:20;not null;default:pending;index"`
+	Note           string       `json:"note" gorm:"size:255"` // Pesan dari pemilik tiket
+	ExpiresAt      time.Time    `json:"expires_at"`
+	RespondedAt    *time.Time   `json:"responded_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Session adalah sesi login per perangkat. Access token membawa ID sesi (claim "sid"),
+// sehingga sesi yang dicabut langsung ditolak oleh AuthMiddleware.
+type Session struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	UserID           uint       `json:"user_id" gorm:"not null;index"`
+	User             User       `json:"-" gorm:"foreignKey:UserID"`
+	RefreshTokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"` // SHA-256 dari refresh token
+	DeviceName       string     `json:"device_name" gorm:"size:255"`
+	IPAddress        string     `json:"ip_address" gorm:"size:50"`
+	UserAgent        string     `json:"user_agent" gorm:"size:255"`
+	LastSeenAt       time.Time  `json:"last_seen_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`                    // Batas refresh token
+	RevokedAt        *time.Time `json:"revoked_at"`                    // Diisi saat logout / dicabut
+	RevokedReason    string     `json:"revoked_reason" gorm:"size:50"` // logout, logout_all, revoked, password_changed, password_reset, 2fa_reset
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
@@ -7,7 +7,7 @@ type SystemSetting struct {
 	Key         string    `json:"key" gorm:"size:100;uniqueIndex;not null"`
 	Value       string    `json:"value" gorm:"type:text"`
 	Type        string    `json:"type" gorm:"size:50;default:'string'"` // string, number, boolean, json
-	Category    string    `json:"category" gorm:"size:50"`              // general, email, notification, security
+	Category    string    `json:"category" gorm:"size:50"`              // general, email, notification, security, payment
 	Description string    `json:"description" gorm:"size:255"`
 	IsPublic    bool      `json:"is_public" gorm:"default:false"` // Apakah bisa diakses tanpa login
 	UpdatedBy   *uint     `json:"updated_by"`                     // Nullable untuk default settings
@@ -37,6 +37,26 @@ var DefaultSettings = []SystemSetting{
 
 	// Security
 	{Key: "jwt_expiry_hours", Value: "24", Type: "number", Category: "security", Description: "Durasi token JWT (jam)"},
+	{Key: "refresh_token_days", Value: "30", Type: "number", Category: "security", Description: "Durasi sesi login / refresh token (hari)"},
 	{Key: "max_login_attempts", Value: "5", Type: "number", Category: "security", Description: "Maksimal percobaan login"},
+	{Key: "max_login_attempts_per_ip", Value: "20", Type: "number", Category: "security", Description: "Maksimal percobaan login gagal per IP"},
 	{Key: "lockout_duration_minutes", Value: "30", Type: "number", Category: "security", Description: "Durasi lockout (menit)"},
+	{Key: "two_factor_required_roles", Value: "", Type: "string", Category: "security", Description: "Kode role yang wajib memakai 2FA, dipisah koma (misal: superadmin,bendahara_umum)"},
+	{Key: "password_reset_expiry_minutes", Value: "60", Type: "number", Category: "security", Description: "Masa berlaku link reset password (menit)"},
+
+	// Payment
+	{Key: "payment_reconcile_after_minutes", Value: "15", Type: "number", Category: "payment", Description: "Order pending lebih lama dari ini dicek ulang ke payment gateway (menit)"},
+	{Key: "payment_expiry_hours", Value: "24", Type: "number", Category: "payment", Description: "Registrasi berbayar yang belum dibayar setelah ini dianggap kadaluarsa (jam)"},
+	{Key: "invoice_prefix", Value: "INV", Type: "string", Category: "payment", Description: "Prefix nomor invoice (INV/2026/000001)"},
+	{Key: "organizer_name", Value: "", Type: "string", Category: "payment", Description: "Nama penyelenggara pada invoice (default: app_name)"},
+	{Key: "organizer_address", Value: "", Type: "string", Category: "payment", Description: "Alamat penyelenggara pada invoice"},
+	{Key: "organizer_email", Value: "", Type: "string", Category: "payment", Description: "Email penyelenggara pada invoice"},
+	{Key: "organizer_phone", Value: "", Type: "string", Category: "payment", Description: "Nomor telepon penyelenggara pada invoice"},
+
+	// Registration
+	{Key: "waitlist_claim_hours", Value: "24", Type: "number", Category: "registration", Description: "Batas waktu peserta waitlist mendaftar setelah mendapat kursi (jam)"},
+	{Key: "ticket_qr_grace_hours", Value: "24", Type: "number", Category: "registration", Description: "Masa berlaku QR tiket setelah event selesai (jam)"},
+	{Key: "ticket_qr_accept_legacy", Value: "true", Type: "boolean", Category: "registration", Description: "Terima QR tiket format lama (belum ditandatangani) saat check-in"},
+	{Key: "group_registration_max_size", Value: "20", Type: "number", Category: "registration", Description: "Jumlah peserta maksimal dalam satu pendaftaran grup"},
+	{Key: "session_checkin_open_minutes", Value: "30", Type: "number", Category: "registration", Description: "Check-in sesi dibuka sekian menit sebelum sesi dimulai"},
 }
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Visibilitas tipe tiket
+const (
+	TicketVisibilityPublic = "public" // Ditampilkan di halaman event
+	TicketVisibilityHidden = "hidden" // Tidak ditampilkan, hanya bisa dipilih dengan kode akses (misal tiket internal mahasiswa)
+)
+
+// TicketType adalah tingkatan harga tiket sebuah event (early bird, reguler, internal, dll).
+// Event tanpa tipe tiket tetap memakai Price dan Quota milik event.
+type TicketType struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	EventID     uint       `json:"event_id" gorm:"not null;index"`
+	Name        string     `json:"name" gorm:"size:100;not null"`
+	Description string     `json:"description" gorm:"type:text"`
+	Price       int64      `json:"price" gorm:"default:0"`
+	Quota       int        `json:"quota" gorm:"default:0"` // 0 = mengikuti kuota event
+	SalesStart  *time.Time `json:"sales_start"`            // Kosong = langsung dijual
+	SalesEnd    *time.Time `json:"sales_end"`              // Kosong = sampai pendaftaran ditutup
+	Visibility  string     `json:"visibility" gorm:"size:20;default:public"`
+	AccessCode  string     `json:"access_code,omitempty" gorm:"size:50"` // Wajib untuk tiket hidden, dimasukkan peserta saat mendaftar
+	SortOrder   int        `json:"sort_order" gorm:"default:0"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsOnSale mengecek apakah tiket berada dalam periode penjualan pada waktu tertentu
+func (t TicketType) IsOnSale(at time.Time) bool {
+	if t.SalesStart != nil && at.Before(*t.SalesStart) {
+		return false
+	}
+	if t.SalesEnd != nil && at.After(*t.SalesEnd) {
+		return false
+	}
+	return true
+}
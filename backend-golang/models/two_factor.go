@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// UserTwoFactor menyimpan secret TOTP milik user. Secret disimpan terenkripsi (AES-GCM)
+// dan baru berlaku setelah user memverifikasi kode pertamanya (EnabledAt terisi).
+type UserTwoFactor struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"uniqueIndex;not null"`
+	User            User       `json:"-" gorm:"foreignKey:UserID"`
+	SecretEncrypted string     `json:"-" gorm:"size:255;not null"`
+	EnabledAt       *time.Time `json:"enabled_at"`
+	LastUsedStep    int64      `json:"-" gorm:"default:0"` // Time-step TOTP terakhir yang dipakai, mencegah kode dipakai ulang
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TwoFactorRecoveryCode adalah kode cadangan sekali pakai jika perangkat authenticator hilang.
+// Yang disimpan hanya hash SHA-256 dari kode.
+type TwoFactorRecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID"`
+	CodeHash  string     `json:"-" gorm:"size:64;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
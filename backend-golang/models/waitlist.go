@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Status antrian waitlist
+const (
+	WaitlistWaiting   = "waiting"   // Menunggu kursi kosong
+	WaitlistOffered   = "offered"   // Mendapat kursi, harus mendaftar sebelum OfferExpiresAt
+	WaitlistClaimed   = "claimed"   // Sudah mendaftar memakai kursi yang ditawarkan
+	WaitlistExpired   = "expired"   // Tidak mendaftar dalam batas waktu klaim
+	WaitlistCancelled = "cancelled" // Keluar dari waitlist
+)
+
+// WaitlistEntry adalah antrian peserta untuk event yang kuotanya penuh (urutan FIFO berdasarkan ID).
+// Kursi yang dilepas (ditolak, dibatalkan, pembayaran kadaluarsa) ditawarkan ke antrian terdepan.
+type WaitlistEntry struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	EventID        uint       `json:"event_id" gorm:"not null;index"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	User           User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Status         string     `json:"status" gorm:"size:20;not null;default:waiting;index"`
+	OfferedAt      *time.Time `json:"offered_at"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at"`
+	RegistrationID *uint      `json:"registration_id"` // Registrasi hasil klaim
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
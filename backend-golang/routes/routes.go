@@ -40,6 +40,25 @@ func SetupRouter() *gin.Engine {
 
 	// route login
 	router.POST("/api/login", controllers.Login)
+	router.POST("/api/login/2fa", controllers.LoginTwoFactor) // Tahap kedua login jika 2FA aktif
+
+	// route lupa password
+	router.POST("/api/password/forgot", controllers.ForgotPassword)
+	router.POST("/api/password/reset", controllers.ResetPassword)
+
+	// route session (refresh token, logout, perangkat aktif)
+	router.POST("/api/auth/refresh", controllers.RefreshToken)
+	router.POST("/api/logout", middlewares.AuthMiddleware(), controllers.Logout)
+	router.POST("/api/logout-all", middlewares.AuthMiddleware(), controllers.LogoutAll)
+	router.GET("/api/sessions", middlewares.AuthMiddleware(), controllers.GetMySessions)
+	router.DELETE("/api/sessions/:id", middlewares.AuthMiddleware(), controllers.RevokeMySession)
+
+	// route autentikasi dua faktor (TOTP)
+	router.GET("/api/2fa", middlewares.AuthMiddleware(), controllers.GetTwoFactorStatus)
+	router.POST("/api/2fa/enroll", middlewares.AuthMiddleware(), controllers.EnrollTwoFactor)
+	router.POST("/api/2fa/confirm", middlewares.AuthMiddleware(), controllers.ConfirmTwoFactor)
+	router.POST("/api/2fa/recovery-codes", middlewares.AuthMiddleware(), controllers.RegenerateRecoveryCodes)
+	router.POST("/api/2fa/disable", middlewares.AuthMiddleware(), controllers.DisableTwoFactor)
 
 	// route users
 	router.GET("/api/users", middlewares.AuthMiddleware(), controllers.FindUsers)
@@ -75,6 +94,15 @@ func SetupRouter() *gin.Engine {
 	router.PUT("/api/events/:id/publish", middlewares.AuthMiddleware(), controllers.PublishEvent)
 	router.GET("/api/events/:id/report", middlewares.AuthMiddleware(), controllers.GetEventReport)
 	router.GET("/api/events/:id/performance", middlewares.AuthMiddleware(), controllers.GetEventPerformance)
+	router.GET("/api/events/:id/waitlist", middlewares.AuthMiddleware(), controllers.GetEventWaitlist)
+	router.GET("/api/events/:id/checkin-manifest", middlewares.AuthMiddleware(), controllers.GetCheckInManifest) // Manifest scanner offline
+	router.GET("/api/events/:id/attendance", middlewares.AuthMiddleware(), controllers.GetEventAttendance)       // Rekap kehadiran & kelayakan sertifikat
+	router.GET("/api/events/:id/badges", middlewares.AuthMiddleware(), controllers.DownloadEventBadges)
+	router.POST("/api/events/:id/waitlist", middlewares.AuthMiddleware(), controllers.JoinWaitlist)
+	router.POST("/api/events/:id/register-group", middlewares.AuthMiddleware(), controllers.RegisterGroup)
+	router.GET("/api/registration-groups/:id", middlewares.AuthMiddleware(), controllers.GetRegistrationGroup)
+	router.GET("/api/registration-groups/:id/tickets", middlewares.AuthMiddleware(), controllers.DownloadGroupTickets)
+	router.DELETE("/api/events/:id/waitlist", middlewares.AuthMiddleware(), controllers.LeaveWaitlist)
 	router.PUT("/api/events/:id", middlewares.AuthMiddleware(), controllers.UpdateEvent)
 	router.DELETE("/api/events/:id", middlewares.AuthMiddleware(), controllers.DeleteEvent)
 	// Route slug dengan path berbeda untuk menghindari konflik dengan :id
@@ -84,18 +112,53 @@ func SetupRouter() *gin.Engine {
 	router.POST("/api/forms/event/:id", middlewares.AuthMiddleware(), controllers.CreateFormField)
 	router.DELETE("/api/form-fields/:id", middlewares.AuthMiddleware(), controllers.DeleteFormField)
 
+	// Tipe tiket per event (pembuat event / panitia)
+	router.GET("/api/ticket-types/event/:event_id", middlewares.AuthMiddleware(), controllers.GetTicketTypes)
+	router.POST("/api/ticket-types/event/:event_id", middlewares.AuthMiddleware(), controllers.CreateTicketType)
+	router.PUT("/api/ticket-types/:id", middlewares.AuthMiddleware(), controllers.UpdateTicketType)
+	router.DELETE("/api/ticket-types/:id", middlewares.AuthMiddleware(), controllers.DeleteTicketType)
+
+	// route sesi event (event multi-sesi / multi-hari)
+	router.GET("/api/events/:id/sessions", middlewares.AuthMiddleware(), controllers.GetEventSessions)
+	router.POST("/api/events/:id/sessions", middlewares.AuthMiddleware(), controllers.CreateEventSession)
+	router.PUT("/api/event-sessions/:id", middlewares.AuthMiddleware(), controllers.UpdateEventSession)
+	router.DELETE("/api/event-sessions/:id", middlewares.AuthMiddleware(), controllers.DeleteEventSession)
+
+	// Kode promo (promo.manage global atau per event, dicek di controller)
+	router.GET("/api/promo-codes", middlewares.AuthMiddleware(), controllers.GetPromoCodes)
+	router.POST("/api/promo-codes", middlewares.AuthMiddleware(), controllers.CreatePromoCode)
+	router.POST("/api/promo-codes/check", middlewares.AuthMiddleware(), controllers.CheckPromoCode) // Preview diskon sebelum mendaftar
+	router.PUT("/api/promo-codes/:id", middlewares.AuthMiddleware(), controllers.UpdatePromoCode)
+	router.DELETE("/api/promo-codes/:id", middlewares.AuthMiddleware(), controllers.DeletePromoCode)
+	router.GET("/api/promo-codes/:id/usages", middlewares.AuthMiddleware(), controllers.GetPromoCodeUsages)
+
 	//route registration
 	router.POST("/api/participant/event/:id/register", middlewares.AuthMiddleware(), controllers.RegisterEvent)
 	router.GET("/api/participants/event/:id", middlewares.AuthMiddleware(), controllers.GetParticipants)
 	router.GET("/api/user/registrations", middlewares.AuthMiddleware(), controllers.GetUserRegistrations)
 	router.GET("/api/registration-status/event/:id", middlewares.AuthMiddleware(), controllers.GetRegistrationStatus)
-	router.POST("/api/scan/check-in", middlewares.AuthMiddleware(), controllers.VerifyCheckIn) // Scan QR Code (Event Offline)
-	router.POST("/api/check-in/self", middlewares.AuthMiddleware(), controllers.SelfCheckIn)   // Self Check-in (Event Online)
+	router.POST("/api/registrations/:id/cancel", middlewares.AuthMiddleware(), controllers.CancelRegistration)        // Pembatalan oleh peserta / refund oleh panitia
+	router.GET("/api/registrations/:id/receipt", middlewares.AuthMiddleware(), controllers.DownloadReceipt)           // Invoice PDF registrasi berbayar
+	router.POST("/api/registrations/:id/payment-proof", middlewares.AuthMiddleware(), controllers.UploadPaymentProof) // Bukti transfer manual
+	router.POST("/api/registrations/:id/transfer", middlewares.AuthMiddleware(), controllers.RequestTransfer)         // Transfer tiket ke user lain
+	router.POST("/api/scan/check-in", middlewares.AuthMiddleware(), controllers.VerifyCheckIn)                        // Scan QR Code (Event Offline)
+	router.POST("/api/scan/sync", middlewares.AuthMiddleware(), controllers.SyncOfflineScans)                         // Sinkronisasi batch scan offline
+	router.POST("/api/scan/check-out", middlewares.AuthMiddleware(), controllers.VerifyCheckOut)                      // Check-out sesi (Event multi-sesi)
+	router.POST("/api/check-in/self", middlewares.AuthMiddleware(), controllers.SelfCheckIn)                          // Self Check-in (Event Online)
+	router.GET("/api/checkin-manifest/public-key", middlewares.AuthMiddleware(), controllers.GetCheckInManifestPublicKey)
 	router.PUT("/api/participants/:id/status", middlewares.AuthMiddleware(), controllers.UpdateRegistrationStatus)
 	router.PUT("/api/participants/:id/attendance", middlewares.AuthMiddleware(), controllers.UpdateAttendance) // Manual Update Attendance (Panitia)
+	router.GET("/api/registrations/:id/attendance", middlewares.AuthMiddleware(), controllers.GetRegistrationAttendance)
+	router.GET("/api/registrations/:id/ticket", middlewares.AuthMiddleware(), controllers.DownloadTicket)
 	router.POST("/api/participants/bulk-update-status", middlewares.AuthMiddleware(), controllers.BulkUpdateRegistrationStatus)
 	router.GET("/api/export/event/:id/participants", middlewares.AuthMiddleware(), controllers.ExportEventParticipants)
 
+	// route transfer tiket
+	router.GET("/api/registration-transfers", middlewares.AuthMiddleware(), controllers.GetMyTransfers)
+	router.POST("/api/registration-transfers/:id/accept", middlewares.AuthMiddleware(), controllers.AcceptTransfer)
+	router.POST("/api/registration-transfers/:id/decline", middlewares.AuthMiddleware(), controllers.DeclineTransfer)
+	router.DELETE("/api/registration-transfers/:id", middlewares.AuthMiddleware(), controllers.CancelTransfer)
+
 	//route certificates
 	router.POST("/api/certificates/registration/:registration_id/upload", middlewares.AuthMiddleware(), controllers.UploadCertificate)
 	router.POST("/api/certificates/event/:event_id/upload-bulk", middlewares.AuthMiddleware(), controllers.UploadCertificatesBulk) // Bulk upload berdasarkan email
@@ -107,8 +170,21 @@ func SetupRouter() *gin.Engine {
 	//route payment
 	router.POST("/api/payment/initiate/:id", middlewares.AuthMiddleware(), controllers.InitiatePayment)
 	router.POST("/api/payment/notification", controllers.HandleNotification)                            // Public untuk webhook
+	router.POST("/api/payment/fake/simulate", controllers.SimulatePaymentNotification)                  // Hanya aktif jika PAYMENT_PROVIDER=fake
 	router.GET("/api/payment/status/:id", middlewares.AuthMiddleware(), controllers.CheckPaymentStatus) // Check payment status
 
+	// Rekonsiliasi pembayaran (bendahara)
+	router.GET("/api/payments/reconciliation", middlewares.AuthMiddleware(), middlewares.RequirePermission("payment.report"), controllers.GetPaymentReconciliationReport)
+	router.GET("/api/payments/reconciliation/runs/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission("payment.report"), controllers.GetPaymentReconciliationRun)
+	router.POST("/api/payments/reconciliation/run", middlewares.AuthMiddleware(), middlewares.RequirePermission("payment.report"), controllers.TriggerPaymentReconciliation)
+
+	// Verifikasi transfer manual (bendahara, permission payment.verify dicek per event di controller)
+	router.GET("/api/payments/proofs", middlewares.AuthMiddleware(), controllers.GetPaymentProofs)
+	router.GET("/api/payments/proofs/:id/file", middlewares.AuthMiddleware(), controllers.DownloadPaymentProof)
+	router.POST("/api/payments/proofs/:id/approve", middlewares.AuthMiddleware(), controllers.ApprovePaymentProof)
+	router.POST("/api/payments/proofs/:id/reject", middlewares.AuthMiddleware(), controllers.RejectPaymentProof)
+	router.POST("/api/refunds/:id/complete", middlewares.AuthMiddleware(), controllers.CompleteManualRefund) // Refund transfer manual sudah ditransfer balik
+
 	//route committees
 	router.GET("/api/committees/event/:event_id", middlewares.AuthMiddleware(), controllers.GetCommitteeMembers)
 	router.POST("/api/committees/event/:event_id", middlewares.AuthMiddleware(), controllers.AddCommitteeMember)
@@ -155,31 +231,46 @@ func SetupRouter() *gin.Engine {
 	router.DELETE("/api/notifications/:id", middlewares.AuthMiddleware(), controllers.DeleteNotification)
 	router.DELETE("/api/notifications/clear-all", middlewares.AuthMiddleware(), controllers.ClearAllNotifications)
 
-	// ============= SUPERADMIN ONLY ROUTES =============
+	// ============= ADMIN ROUTES (berdasarkan permission) =============
 	// Audit Logs
-	router.GET("/api/admin/audit-logs", middlewares.AuthMiddleware(), controllers.GetAuditLogs)
-	router.GET("/api/admin/audit-logs/stats", middlewares.AuthMiddleware(), controllers.GetAuditLogStats)
+	router.GET("/api/admin/audit-logs", middlewares.AuthMiddleware(), middlewares.RequirePermission("audit.view"), controllers.GetAuditLogs)
+	router.GET("/api/admin/audit-logs/stats", middlewares.AuthMiddleware(), middlewares.RequirePermission("audit.view"), controllers.GetAuditLogStats)
 
 	// System Settings
-	router.GET("/api/admin/settings", middlewares.AuthMiddleware(), controllers.GetSystemSettings)
-	router.PUT("/api/admin/settings/:id", middlewares.AuthMiddleware(), controllers.UpdateSystemSetting)
-	router.PUT("/api/admin/settings/bulk", middlewares.AuthMiddleware(), controllers.BulkUpdateSystemSettings)
+	router.GET("/api/admin/settings", middlewares.AuthMiddleware(), middlewares.RequirePermission("settings.manage"), controllers.GetSystemSettings)
+	router.PUT("/api/admin/settings/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission("settings.manage"), controllers.UpdateSystemSetting)
+	router.PUT("/api/admin/settings/bulk", middlewares.AuthMiddleware(), middlewares.RequirePermission("settings.manage"), controllers.BulkUpdateSystemSettings)
 	router.GET("/api/public/settings", controllers.GetPublicSettings) // Public settings tanpa auth
 
 	// Broadcasts
-	router.GET("/api/admin/broadcasts", middlewares.AuthMiddleware(), controllers.GetBroadcasts)
-	router.POST("/api/admin/broadcasts", middlewares.AuthMiddleware(), controllers.CreateBroadcast)
-	router.DELETE("/api/admin/broadcasts/:id", middlewares.AuthMiddleware(), controllers.DeleteBroadcast)
-	router.GET("/api/admin/broadcasts/stats", middlewares.AuthMiddleware(), controllers.GetBroadcastStats)
+	router.GET("/api/admin/broadcasts", middlewares.AuthMiddleware(), middlewares.RequirePermission("broadcast.manage"), controllers.GetBroadcasts)
+	router.POST("/api/admin/broadcasts", middlewares.AuthMiddleware(), middlewares.RequirePermission("broadcast.manage"), controllers.CreateBroadcast)
+	router.DELETE("/api/admin/broadcasts/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission("broadcast.manage"), controllers.DeleteBroadcast)
+	router.GET("/api/admin/broadcasts/stats", middlewares.AuthMiddleware(), middlewares.RequirePermission("broadcast.manage"), controllers.GetBroadcastStats)
 	router.GET("/api/broadcasts", middlewares.AuthMiddleware(), controllers.GetUserBroadcasts)          // User broadcasts
 	router.PUT("/api/broadcasts/:id/read", middlewares.AuthMiddleware(), controllers.MarkBroadcastRead) // Mark as read
 
+	// Login Lockout
+	router.POST("/api/admin/users/:id/unlock", middlewares.AuthMiddleware(), middlewares.RequirePermission("users.unlock"), controllers.UnlockUser)
+	router.POST("/api/admin/users/:id/2fa/reset", middlewares.AuthMiddleware(), middlewares.RequirePermission("users.unlock"), controllers.ResetUserTwoFactor)
+
+	// Roles & Permissions
+	router.GET("/api/admin/permissions", middlewares.AuthMiddleware(), middlewares.RequirePermission("roles.manage"), controllers.GetPermissions)
+	router.GET("/api/admin/roles/:id/permissions", middlewares.AuthMiddleware(), middlewares.RequirePermission("roles.manage"), controllers.GetRolePermissions)
+	router.PUT("/api/admin/roles/:id/permissions", middlewares.AuthMiddleware(), middlewares.RequirePermission("roles.manage"), controllers.UpdateRolePermissions)
+	router.GET("/api/me/permissions", middlewares.AuthMiddleware(), controllers.GetMyPermissions)
+
 	// All Activities (superadmin can see all)
-	router.GET("/api/admin/activities", middlewares.AuthMiddleware(), controllers.GetAllActivities)
+	router.GET("/api/admin/activities", middlewares.AuthMiddleware(), middlewares.RequirePermission("activity.view_all"), controllers.GetAllActivities)
+
+	// Background Jobs
+	router.GET("/api/admin/jobs", middlewares.AuthMiddleware(), middlewares.RequirePermission("jobs.manage"), controllers.GetJobs)
+	router.GET("/api/admin/jobs/:name/runs", middlewares.AuthMiddleware(), middlewares.RequirePermission("jobs.manage"), controllers.GetJobRuns)
+	router.POST("/api/admin/jobs/:name/trigger", middlewares.AuthMiddleware(), middlewares.RequirePermission("jobs.manage"), controllers.TriggerJob)
 
-	// ============= MASTER DATA ROUTES (Superadmin & Ketua Himpunan Only) =============
+	// ============= MASTER DATA ROUTES (permission master.manage) =============
 	masterGroup := router.Group("/api/master")
-	masterGroup.Use(middlewares.AuthMiddleware(), middlewares.MasterAccessMiddleware())
+	masterGroup.Use(middlewares.AuthMiddleware(), middlewares.RequirePermission("master.manage"))
 	{
 		// Universities
 		masterGroup.GET("/universities", controllers.GetUniversities)
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule adalah jadwal cron 5 field: menit jam tanggal bulan hari
+// Contoh: "*/15 * * * *" (tiap 15 menit), "0 8 * * *" (tiap hari jam 08:00)
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+type fieldRange struct {
+	min, max int
+}
+
+var fieldRanges = []fieldRange{
+	{0, 59}, // menit
+	{0, 23}, // jam
+	{1, 31}, // tanggal
+	{1, 12}, // bulan
+	{0, 6},  // hari (0 = Minggu)
+}
+
+// ParseSchedule mem-parsing ekspresi cron. Mendukung *, */n, a-b, a-b/n dan daftar dipisah koma.
+func ParseSchedule(spec string) (*Schedule, error) {
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily":
+		spec = "0 0 * * *"
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("jadwal cron harus 5 field, didapat %d: %q", len(fields), spec)
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseField(field, fieldRanges[i])
+		if err != nil {
+			return nil, fmt.Errorf("field ke-%d (%q) tidak valid: %v", i+1, field, err)
+		}
+		bits[i] = b
+	}
+
+	return &Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+func parseField(field string, r fieldRange) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("step tidak valid")
+			}
+			step = s
+			part = part[:idx]
+		}
+
+		lo, hi := r.min, r.max
+		if part != "*" {
+			if idx := strings.Index(part, "-"); idx >= 0 {
+				var err error
+				if lo, err = strconv.Atoi(part[:idx]); err != nil {
+					return 0, err
+				}
+				if hi, err = strconv.Atoi(part[idx+1:]); err != nil {
+					return 0, err
+				}
+			} else {
+				v, err := strconv.Atoi(part)
+				if err != nil {
+					return 0, err
+				}
+				lo = v
+				hi = v
+				if step > 1 {
+					hi = r.max
+				}
+			}
+		}
+
+		if lo < r.min || hi > r.max || lo > hi {
+			return 0, fmt.Errorf("nilai di luar rentang %d-%d", r.min, r.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Matches mengecek apakah waktu t (presisi menit) cocok dengan jadwal
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 ||
+		s.hour&(1<<uint(t.Hour())) == 0 ||
+		s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	// Aturan cron standar: jika tanggal dan hari sama-sama dibatasi, cukup salah satu yang cocok
+	if !s.domAny && !s.dowAny {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// Next menghitung waktu jalan berikutnya setelah t (maksimal pencarian 1 tahun)
+func (s *Schedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.AddDate(1, 0, 0)
+	for next.Before(limit) {
+		if s.Matches(next) {
+			return next
+		}
+		next = next.Add(time.Minute)
+	}
+	return time.Time{}
+}
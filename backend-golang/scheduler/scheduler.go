@@ -0,0 +1,280 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"santrikoding/backend-api/database"
+	"santrikoding/backend-api/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// lockTTL adalah masa berlaku kunci sejak terakhir diperpanjang. Selama job berjalan kunci
+// diperpanjang setiap lockRenewInterval, jadi hanya instance yang crash yang kuncinya kedaluwarsa.
+const (
+	lockTTL           = 5 * time.Minute
+	lockRenewInterval = time.Minute
+)
+
+// ErrJobNotFound dikembalikan jika nama job tidak terdaftar
+var ErrJobNotFound = errors.New("job tidak ditemukan")
+
+// ErrJobRunning dikembalikan jika job sedang dijalankan oleh instance lain
+var ErrJobRunning = errors.New("job sedang berjalan")
+
+// Job mendefinisikan satu pekerjaan terjadwal
+type Job struct {
+	Name        string
+	Description string
+	Spec        string       // Ekspresi cron, lihat ParseSchedule
+	Run         func() error // Fungsi yang dijalankan
+	PerInstance bool         // true = jalan di setiap replica (tanpa kunci DB), untuk state in-memory
+
+	schedule *Schedule
+}
+
+// NextRun menghitung jadwal berikutnya dari sekarang
+func (j *Job) NextRun() time.Time {
+	return j.schedule.Next(time.Now())
+}
+
+var (
+	jobs     = map[string]*Job{}
+	jobsMu   sync.RWMutex
+	instance = instanceName()
+)
+
+func instanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Register mendaftarkan job ke scheduler. Panic jika jadwal tidak valid (kesalahan konfigurasi saat startup).
+func Register(job Job) {
+	schedule, err := ParseSchedule(job.Spec)
+	if err != nil {
+		panic(fmt.Sprintf("scheduler: job %s: %v", job.Name, err))
+	}
+	job.schedule = schedule
+
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	jobs[job.Name] = &job
+}
+
+// Jobs mengembalikan semua job terdaftar, urut berdasarkan nama
+func Jobs() []*Job {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+
+	list := make([]*Job, 0, len(jobs))
+	for _, j := range jobs {
+		list = append(list, j)
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	return list
+}
+
+// Get mengambil job berdasarkan nama
+func Get(name string) (*Job, bool) {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+	j, ok := jobs[name]
+	return j, ok
+}
+
+// Start menjalankan loop scheduler di background. Setiap pergantian menit,
+// job yang jadwalnya cocok akan dijalankan.
+func Start() {
+	log.Printf("Scheduler berjalan (%d job, instance %s)", len(Jobs()), instance)
+
+	go func() {
+		for {
+			now := time.Now()
+			next := now.Truncate(time.Minute).Add(time.Minute)
+			time.Sleep(time.Until(next))
+
+			for _, job := range Jobs() {
+				if job.schedule.Matches(next) {
+					tick := next
+					go execute(job, tick)
+				}
+			}
+		}
+	}()
+}
+
+// Trigger menjalankan job secara manual. Run dicatat lalu job dieksekusi di background.
+func Trigger(name string, triggeredBy uint) (*models.JobRun, error) {
+	job, ok := Get(name)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	if !job.PerInstance {
+		acquired, err := acquire(job.Name, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			return nil, ErrJobRunning
+		}
+	}
+
+	run, err := startRun(job, "manual", &triggeredBy)
+	if err != nil {
+		if !job.PerInstance {
+			release(job.Name)
+		}
+		return nil, err
+	}
+
+	// Kembalikan salinan karena run akan diubah oleh goroutine job
+	snapshot := *run
+	go finish(job, run)
+	return &snapshot, nil
+}
+
+// execute dipanggil oleh loop scheduler untuk satu tick
+func execute(job *Job, tick time.Time) {
+	if !job.PerInstance {
+		acquired, err := acquire(job.Name, &tick)
+		if err != nil {
+			log.Printf("Scheduler: gagal mengambil kunci %s: %v", job.Name, err)
+			return
+		}
+		if !acquired {
+			return
+		}
+	}
+
+	run, err := startRun(job, "schedule", nil)
+	if err != nil {
+		log.Printf("Scheduler: gagal mencatat run %s: %v", job.Name, err)
+		if !job.PerInstance {
+			release(job.Name)
+		}
+		return
+	}
+
+	finish(job, run)
+}
+
+func startRun(job *Job, trigger string, triggeredBy *uint) (*models.JobRun, error) {
+	run := models.JobRun{
+		JobName:     job.Name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Instance:    instance,
+		Status:      "running",
+		StartedAt:   time.Now(),
+	}
+	if err := database.DB.Create(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func finish(job *Job, run *models.JobRun) {
+	if !job.PerInstance {
+		defer release(job.Name)
+		stop := keepAlive(job.Name)
+		defer stop()
+	}
+
+	err := safeRun(job)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = finishedAt.Sub(run.StartedAt).Milliseconds()
+	run.Status = "success"
+	if err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+		log.Printf("Scheduler: job %s gagal: %v", job.Name, err)
+	}
+
+	if err := database.DB.Save(run).Error; err != nil {
+		log.Printf("Scheduler: gagal menyimpan hasil run %s: %v", job.Name, err)
+	}
+}
+
+// safeRun menjalankan job dan mengubah panic menjadi error
+func safeRun(job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run()
+}
+
+// acquire mengambil kunci job di database. Jika tick diisi, tick yang sama
+// tidak akan diambil dua kali walaupun kunci sudah dilepas (mencegah double-fire antar replica).
+// Mengembalikan false tanpa error jika kunci sedang dipegang instance lain.
+func acquire(name string, tick *time.Time) (bool, error) {
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.JobLock{Name: name}).Error; err != nil {
+		return false, fmt.Errorf("gagal membuat baris kunci: %w", err)
+	}
+
+	now := time.Now()
+	query := database.DB.Model(&models.JobLock{}).
+		Where("name = ?", name).
+		Where("locked_until IS NULL OR locked_until < ?", now)
+
+	updates := map[string]interface{}{
+		"locked_by":    instance,
+		"locked_until": now.Add(lockTTL),
+	}
+	if tick != nil {
+		query = query.Where("last_tick_at IS NULL OR last_tick_at < ?", *tick)
+		updates["last_tick_at"] = *tick
+	}
+
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("gagal mengambil kunci: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// keepAlive memperpanjang kunci job secara berkala selama job berjalan, supaya job yang lebih
+// lama dari lockTTL tidak diambil alih instance lain. Fungsi yang dikembalikan menghentikannya.
+func keepAlive(name string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lockRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				result := database.DB.Model(&models.JobLock{}).
+					Where("name = ? AND locked_by = ?", name, instance).
+					Update("locked_until", time.Now().Add(lockTTL))
+				if result.Error != nil {
+					log.Printf("Scheduler: gagal memperpanjang kunci %s: %v", name, result.Error)
+				} else if result.RowsAffected == 0 {
+					log.Printf("Scheduler: kunci %s sudah tidak dipegang instance ini", name)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func release(name string) {
+	database.DB.Model(&models.JobLock{}).
+		Where("name = ? AND locked_by = ?", name, instance).
+		Update("locked_until", nil)
+}
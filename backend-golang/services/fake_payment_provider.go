@@ -0,0 +1,176 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"santrikoding/backend-api/config"
+	"sync"
+)
+
+// FakeProvider adalah payment provider lokal untuk development dan pengujian offline.
+// Tagihan disimpan di memori, dan webhook disimulasikan lewat BuildNotification dengan
+// format dan signature yang sama seperti Midtrans (memakai FAKE_PAYMENT_SERVER_KEY).
+type FakeProvider struct {
+	serverKey string
+
+	mu     sync.Mutex
+	orders map[string]*fakeOrder
+}
+
+type fakeOrder struct {
+	Amount            int64
+	TransactionStatus string
+	TransactionID     string
+	Refunds           map[string]int64 // refund_key -> amount
+}
+
+// NewFakeProvider membuat fake provider
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		serverKey: config.GetEnv("FAKE_PAYMENT_SERVER_KEY", "fake-server-key"),
+		orders:    map[string]*fakeOrder{},
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// CreateCharge mencatat tagihan baru dengan status pending
+func (p *FakeProvider) CreateCharge(req ChargeRequest) (*ChargeResult, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("nominal tagihan harus lebih dari 0")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.orders[req.OrderID]; exists {
+		return nil, fmt.Errorf("order %s sudah ada", req.OrderID)
+	}
+	p.orders[req.OrderID] = &fakeOrder{
+		Amount:            req.Amount,
+		TransactionStatus: "pending",
+		TransactionID:     "FAKE-TRX-" + req.OrderID,
+		Refunds:           map[string]int64{},
+	}
+
+	result := &ChargeResult{
+		Token:       "FAKE-" + req.OrderID,
+		RedirectURL: "fake://checkout/" + req.OrderID, // Bayar lewat POST /api/payment/fake/simulate
+	}
+	result.Raw, _ = json.Marshal(map[string]string{"token": result.Token, "redirect_url": result.RedirectURL})
+	return result, nil
+}
+
+// GetStatus mengembalikan status order yang tersimpan di memori
+func (p *FakeProvider) GetStatus(orderID string) (*PaymentStatus, error) {
+	p.mu.Lock()
+	order, ok := p.orders[orderID]
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("order %s tidak ditemukan di fake provider", orderID)
+	}
+
+	n := p.notificationFor(orderID, order, order.TransactionStatus, "", fmt.Sprintf("%d.00", order.Amount))
+	raw, _ := json.Marshal(n)
+	return p.toStatus(n, raw), nil
+}
+
+// ParseNotification memverifikasi signature payload dengan skema Midtrans
+func (p *FakeProvider) ParseNotification(body []byte) (*PaymentStatus, error) {
+	var n MidtransNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, fmt.Errorf("payload bukan JSON yang valid: %v", err)
+	}
+	if !verifySHA512Signature(n, p.serverKey) {
+		return p.toStatus(n, body), ErrInvalidSignature
+	}
+	return p.toStatus(n, body), nil
+}
+
+// Refund menandai order lunas sebagai refund. Refund key yang sama bersifat idempoten.
+func (p *FakeProvider) Refund(req RefundRequest) (*RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	order, ok := p.orders[req.OrderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s tidak ditemukan di fake provider", req.OrderID)
+	}
+	if _, done := order.Refunds[req.RefundKey]; !done {
+		if order.TransactionStatus != "settlement" && order.TransactionStatus != "partial_refund" {
+			return nil, fmt.Errorf("order %s belum lunas (status %s)", req.OrderID, order.TransactionStatus)
+		}
+		var refunded int64
+		for _, amount := range order.Refunds {
+			refunded += amount
+		}
+		if refunded+req.Amount > order.Amount {
+			return nil, fmt.Errorf("nominal refund melebihi pembayaran")
+		}
+		order.Refunds[req.RefundKey] = req.Amount
+		order.TransactionStatus = "partial_refund"
+		if refunded+req.Amount == order.Amount {
+			order.TransactionStatus = "refund"
+		}
+	}
+
+	raw, _ := json.Marshal(map[string]interface{}{
+		"order_id":           req.OrderID,
+		"refund_key":         req.RefundKey,
+		"refund_amount":      req.Amount,
+		"transaction_status": order.TransactionStatus,
+	})
+	return &RefundResult{RefundKey: req.RefundKey, Status: order.TransactionStatus, Raw: raw}, nil
+}
+
+// BuildNotification mensimulasikan webhook: status order diperbarui lalu payload bertanda tangan
+// dikembalikan untuk diproses handler webhook. grossAmount kosong berarti sesuai nominal tagihan.
+func (p *FakeProvider) BuildNotification(orderID, transactionStatus, fraudStatus, grossAmount string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	order, ok := p.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s tidak ditemukan di fake provider", orderID)
+	}
+	order.TransactionStatus = transactionStatus
+	if grossAmount == "" {
+		grossAmount = fmt.Sprintf("%d.00", order.Amount)
+	}
+
+	n := p.notificationFor(orderID, order, transactionStatus, fraudStatus, grossAmount)
+	return json.Marshal(n)
+}
+
+func (p *FakeProvider) notificationFor(orderID string, order *fakeOrder, transactionStatus, fraudStatus, grossAmount string) MidtransNotification {
+	n := MidtransNotification{
+		OrderID:           orderID,
+		TransactionID:     order.TransactionID,
+		TransactionStatus: transactionStatus,
+		FraudStatus:       fraudStatus,
+		StatusCode:        "200",
+		GrossAmount:       grossAmount,
+		PaymentType:       "fake",
+	}
+	if transactionStatus == "pending" {
+		n.StatusCode = "201"
+	}
+	n.SignatureKey = signMidtransNotification(n, p.serverKey)
+	return n
+}
+
+func (p *FakeProvider) toStatus(n MidtransNotification, raw []byte) *PaymentStatus {
+	return &PaymentStatus{
+		OrderID:           n.OrderID,
+		TransactionID:     n.TransactionID,
+		TransactionStatus: n.TransactionStatus,
+		FraudStatus:       n.FraudStatus,
+		StatusCode:        n.StatusCode,
+		GrossAmount:       n.GrossAmount,
+		PaymentType:       n.PaymentType,
+		Status:            mapTransactionStatus(n.TransactionStatus, n.FraudStatus),
+		Raw:               raw,
+	}
+}
@@ -1,148 +1,224 @@
 package services
 
 import (
+	"bytes"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"strconv"
-	"time"
 	"santrikoding/backend-api/config"
-	"santrikoding/backend-api/models"
+	"strings"
+	"time"
 
 	"github.com/midtrans/midtrans-go"
 	"github.com/midtrans/midtrans-go/snap"
 )
 
-// GetSnapToken menghasilkan snap token untuk pembayaran
-// Returns: token, redirectURL, orderID, error
-func GetSnapToken(registration models.Registration, user models.User, event models.Event) (string, string, string, error) {
-	// Init snap client dengan Server Key
-	serverKey := config.GetEnv("MIDTRANS_SERVER_KEY", "")
-	if serverKey == "" {
-		return "", "", "", fmt.Errorf("MIDTRANS_SERVER_KEY belum dikonfigurasi")
+// MidtransProvider adalah implementasi PaymentProvider untuk Midtrans Snap.
+// Mode sandbox/production diatur lewat env MIDTRANS_ENV (default sandbox).
+type MidtransProvider struct {
+	serverKey  string
+	env        midtrans.EnvironmentType
+	apiBaseURL string
+	httpClient *http.Client
+}
+
+// NewMidtransProvider membuat provider Midtrans dari konfigurasi environment
+func NewMidtransProvider() *MidtransProvider {
+	p := &MidtransProvider{
+		serverKey:  config.GetEnv("MIDTRANS_SERVER_KEY", ""),
+		env:        midtrans.Sandbox,
+		apiBaseURL: "https://api.sandbox.midtrans.com",
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	if strings.EqualFold(config.GetEnv("MIDTRANS_ENV", "sandbox"), "production") {
+		p.env = midtrans.Production
+		p.apiBaseURL = "https://api.midtrans.com"
 	}
+	return p
+}
 
-	var snapClient snap.Client
-	snapClient.New(serverKey, midtrans.Sandbox) // Mode sandbox
+// MidtransNotification adalah field payload webhook / status Midtrans yang dipakai backend
+type MidtransNotification struct {
+	OrderID           string `json:"order_id"`
+	TransactionID     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	PaymentType       string `json:"payment_type"`
+	StatusMessage     string `json:"status_message"`
+}
+
+func (p *MidtransProvider) Name() string {
+	return "midtrans"
+}
 
-	// Tentukan harga event (harus > 0, jika tidak berarti event gratis dan tidak perlu payment)
-	price := event.Price
-	if price <= 0 {
-		return "", "", "", fmt.Errorf("event ini gratis, tidak memerlukan pembayaran")
+func (p *MidtransProvider) checkConfigured() error {
+	if p.serverKey == "" {
+		return fmt.Errorf("MIDTRANS_SERVER_KEY belum dikonfigurasi")
 	}
+	return nil
+}
 
-	// Buat Order ID
-	orderID := fmt.Sprintf("ORDER-%d-%d", registration.ID, time.Now().Unix())
+// CreateCharge membuat transaksi Snap dan mengembalikan snap token + redirect URL
+func (p *MidtransProvider) CreateCharge(req ChargeRequest) (*ChargeResult, error) {
+	if err := p.checkConfigured(); err != nil {
+		return nil, err
+	}
 
-	// Buat request Snap
-	req := &snap.Request{
+	var snapClient snap.Client
+	snapClient.New(p.serverKey, p.env)
+
+	snapReq := &snap.Request{
 		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  orderID,
-			GrossAmt: int64(price),
+			OrderID:  req.OrderID,
+			GrossAmt: req.Amount,
 		},
 		CustomerDetail: &midtrans.CustomerDetails{
-			FName: user.Name,
-			Email: user.Email,
+			FName: req.CustomerName,
+			Email: req.CustomerEmail,
 		},
 		Items: &[]midtrans.ItemDetails{
 			{
-				ID:    fmt.Sprintf("EVENT-%d", event.ID),
-				Price: int64(price),
+				ID:    req.ItemID,
+				Price: req.Amount,
 				Qty:   1,
-				Name:  event.Title,
+				Name:  req.ItemName,
 			},
 		},
 	}
 
-	// Generate snap token
-	snapResp, err := snapClient.CreateTransaction(req)
+	snapResp, err := snapClient.CreateTransaction(snapReq)
 	if err != nil {
-		return "", "", "", fmt.Errorf("gagal membuat snap token: %v", err)
+		return nil, fmt.Errorf("gagal membuat snap token: %v", err)
 	}
 
-	return snapResp.Token, snapResp.RedirectURL, orderID, nil
+	raw, _ := json.Marshal(snapResp)
+	return &ChargeResult{Token: snapResp.Token, RedirectURL: snapResp.RedirectURL, Raw: raw}, nil
 }
 
-// ParseOrderID untuk mengambil registration ID dari order ID
-func ParseOrderID(orderID string) (uint, error) {
-	// Format: "ORDER-{RegistrationID}-{UnixTime}"
-	// Contoh: "ORDER-123-1704067200"
-	
-	// Hapus prefix "ORDER-"
-	if len(orderID) < 6 || orderID[:6] != "ORDER-" {
-		return 0, fmt.Errorf("format order ID tidak valid")
-	}
-	
-	orderID = orderID[6:] // Hapus "ORDER-"
-	
-	// Cari posisi dash kedua (pemisah registration ID dan unix time)
-	var registrationIDStr string
-	for i, char := range orderID {
-		if char == '-' {
-			registrationIDStr = orderID[:i]
-			break
-		}
-	}
-	
-	if registrationIDStr == "" {
-		return 0, fmt.Errorf("format order ID tidak valid: tidak ada registration ID")
-	}
-	
-	registrationID, err := strconv.ParseUint(registrationIDStr, 10, 32)
+// GetStatus mengecek status transaksi lewat Midtrans API /v2/{order_id}/status
+func (p *MidtransProvider) GetStatus(orderID string) (*PaymentStatus, error) {
+	body, err := p.call(http.MethodGet, "/v2/"+orderID+"/status", nil)
 	if err != nil {
-		return 0, fmt.Errorf("gagal parsing registration ID: %v", err)
+		return nil, err
+	}
+
+	var n MidtransNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, fmt.Errorf("gagal parse response: %v", err)
+	}
+	if n.TransactionStatus == "" {
+		return nil, fmt.Errorf("transaction_status tidak ditemukan dalam response: %s", n.StatusMessage)
 	}
-	
-	return uint(registrationID), nil
+	return p.toStatus(n, body), nil
 }
 
-// CheckPaymentStatus mengecek status pembayaran dari Midtrans berdasarkan order ID
-func CheckPaymentStatus(orderID string) (string, error) {
-	serverKey := config.GetEnv("MIDTRANS_SERVER_KEY", "")
-	if serverKey == "" {
-		return "", fmt.Errorf("MIDTRANS_SERVER_KEY belum dikonfigurasi")
+// ParseNotification memverifikasi signature_key webhook:
+// SHA512(order_id + status_code + gross_amount + server_key)
+func (p *MidtransProvider) ParseNotification(body []byte) (*PaymentStatus, error) {
+	if err := p.checkConfigured(); err != nil {
+		return nil, err
+	}
+
+	var n MidtransNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, fmt.Errorf("payload bukan JSON yang valid: %v", err)
+	}
+
+	if !verifySHA512Signature(n, p.serverKey) {
+		return p.toStatus(n, body), ErrInvalidSignature
 	}
+	return p.toStatus(n, body), nil
+}
 
-	// Get transaction status dari Midtrans API
-	client := &http.Client{Timeout: 10 * time.Second}
-	
-	url := fmt.Sprintf("https://api.sandbox.midtrans.com/v2/%s/status", orderID)
-	req, err := http.NewRequest("GET", url, nil)
+// Refund mengembalikan dana lewat Midtrans API /v2/{order_id}/refund
+func (p *MidtransProvider) Refund(req RefundRequest) (*RefundResult, error) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"refund_key": req.RefundKey,
+		"amount":     req.Amount,
+		"reason":     req.Reason,
+	})
+	body, err := p.call(http.MethodPost, "/v2/"+req.OrderID+"/refund", payload)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	var result struct {
+		StatusCode        string `json:"status_code"`
+		StatusMessage     string `json:"status_message"`
+		TransactionStatus string `json:"transaction_status"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("gagal parse response: %v", err)
 	}
+	if result.StatusCode != "200" {
+		return nil, fmt.Errorf("refund ditolak Midtrans: %s %s", result.StatusCode, result.StatusMessage)
+	}
+	return &RefundResult{RefundKey: req.RefundKey, Status: result.TransactionStatus, Raw: body}, nil
+}
 
-	// Set header dengan Basic Auth (Server Key)
-	req.SetBasicAuth(serverKey, "")
+// call mengirim request ke Midtrans Core API dengan Basic Auth server key
+func (p *MidtransProvider) call(method, path string, payload []byte) ([]byte, error) {
+	if err := p.checkConfigured(); err != nil {
+		return nil, err
+	}
+
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, p.apiBaseURL+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(p.serverKey, "")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
+	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("gagal menghubungi Midtrans API: %v", err)
+		return nil, fmt.Errorf("gagal menghubungi Midtrans API: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes := make([]byte, 1024)
-		resp.Body.Read(bodyBytes)
-		return "", fmt.Errorf("Midtrans API error: status %d, response: %s", resp.StatusCode, string(bodyBytes))
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca response: %v", err)
 	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("gagal parse response: %v", err)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Midtrans API error: status %d, response: %s", resp.StatusCode, string(body))
 	}
+	return body, nil
+}
 
-	transactionStatus, exists := result["transaction_status"]
-	if !exists {
-		return "", fmt.Errorf("transaction_status tidak ditemukan dalam response")
+func (p *MidtransProvider) toStatus(n MidtransNotification, raw []byte) *PaymentStatus {
+	return &PaymentStatus{
+		OrderID:           n.OrderID,
+		TransactionID:     n.TransactionID,
+		TransactionStatus: n.TransactionStatus,
+		FraudStatus:       n.FraudStatus,
+		StatusCode:        n.StatusCode,
+		GrossAmount:       n.GrossAmount,
+		PaymentType:       n.PaymentType,
+		Status:            mapTransactionStatus(n.TransactionStatus, n.FraudStatus),
+		Raw:               raw,
 	}
+}
 
-	statusStr, ok := transactionStatus.(string)
-	if !ok {
-		return "", fmt.Errorf("transaction_status bukan string")
-	}
+// signMidtransNotification menghitung signature_key format Midtrans
+func signMidtransNotification(n MidtransNotification, serverKey string) string {
+	sum := sha512.Sum512([]byte(n.OrderID + n.StatusCode + n.GrossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
 
-	return statusStr, nil
+func verifySHA512Signature(n MidtransNotification, serverKey string) bool {
+	expected := signMidtransNotification(n, serverKey)
+	given := strings.ToLower(strings.TrimSpace(n.SignatureKey))
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(given)) == 1
 }
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"santrikoding/backend-api/config"
+	"santrikoding/backend-api/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSignature dikembalikan saat payload webhook tidak lolos verifikasi provider
+var ErrInvalidSignature = errors.New("signature webhook tidak valid")
+
+// ChargeRequest adalah data tagihan yang dikirim ke payment provider
+type ChargeRequest struct {
+	OrderID       string
+	Amount        int64 // Rupiah
+	CustomerName  string
+	CustomerEmail string
+	ItemID        string
+	ItemName      string
+}
+
+// ChargeResult adalah hasil pembuatan tagihan (token/URL untuk halaman pembayaran)
+type ChargeResult struct {
+	Token       string
+	RedirectURL string
+	Raw         []byte
+}
+
+// PaymentStatus adalah status transaksi dari provider (webhook maupun query status)
+type PaymentStatus struct {
+	OrderID           string
+	TransactionID     string
+	TransactionStatus string // Status mentah dari provider
+	FraudStatus       string
+	StatusCode        string
+	GrossAmount       string
+	PaymentType       string
+	Status            string // Status ternormalisasi (models.PaymentStatus*)
+	Raw               []byte
+}
+
+// RefundRequest adalah permintaan refund untuk sebuah order
+type RefundRequest struct {
+	OrderID   string
+	RefundKey string // Kunci idempoten, refund dengan key yang sama tidak diproses dua kali
+	Amount    int64
+	Reason    string
+}
+
+// RefundResult adalah hasil refund dari provider
+type RefundResult struct {
+	RefundKey string
+	Status    string // Status mentah dari provider
+	Raw       []byte
+}
+
+// PaymentProvider adalah kontrak payment gateway yang dipakai controller pembayaran
+type PaymentProvider interface {
+	// Name mengembalikan kode provider yang disimpan di payments.provider
+	Name() string
+	// CreateCharge membuat tagihan baru
+	CreateCharge(req ChargeRequest) (*ChargeResult, error)
+	// GetStatus mengambil status terbaru sebuah order langsung dari provider
+	GetStatus(orderID string) (*PaymentStatus, error)
+	// ParseNotification memverifikasi dan membaca payload webhook (ErrInvalidSignature jika palsu)
+	ParseNotification(body []byte) (*PaymentStatus, error)
+	// Refund mengembalikan dana sebuah order yang sudah lunas
+	Refund(req RefundRequest) (*RefundResult, error)
+}
+
+var (
+	providersMu sync.Mutex
+	providers   = map[string]PaymentProvider{}
+)
+
+// GetPaymentProvider mengambil provider berdasarkan nama (midtrans, fake)
+func GetPaymentProvider(name string) (PaymentProvider, error) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if p, ok := providers[name]; ok {
+		return p, nil
+	}
+
+	var p PaymentProvider
+	switch name {
+	case "midtrans":
+		p = NewMidtransProvider()
+	case "fake":
+		p = NewFakeProvider()
+	default:
+		return nil, fmt.Errorf("payment provider %q tidak dikenal", name)
+	}
+	providers[name] = p
+	return p, nil
+}
+
+// DefaultPaymentProvider mengembalikan provider untuk tagihan baru (env PAYMENT_PROVIDER, default midtrans)
+func DefaultPaymentProvider() (PaymentProvider, error) {
+	return GetPaymentProvider(DefaultPaymentProviderName())
+}
+
+// DefaultPaymentProviderName mengembalikan nama provider aktif dari env PAYMENT_PROVIDER
+func DefaultPaymentProviderName() string {
+	return strings.ToLower(config.GetEnv("PAYMENT_PROVIDER", "midtrans"))
+}
+
+// NewOrderID membuat order ID unik untuk percobaan pembayaran sebuah registrasi
+// Format: "ORDER-{RegistrationID}-{UnixMilli}" (milidetik, supaya percobaan ulang dalam detik yang sama tetap unik)
+func NewOrderID(registrationID uint) string {
+	return fmt.Sprintf("ORDER-%d-%d", registrationID, time.Now().UnixMilli())
+}
+
+// GrossAmountMatches mengecek gross_amount provider (misal "150000.00") sama dengan nominal rupiah
+func GrossAmountMatches(grossAmount string, expected int64) bool {
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(grossAmount), ".")
+	if strings.Trim(fraction, "0") != "" {
+		return false
+	}
+	amount, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return false
+	}
+	return amount == expected
+}
+
+// mapTransactionStatus menerjemahkan transaction_status + fraud_status (format Midtrans,
+// juga dipakai fake provider) ke status payment
+func mapTransactionStatus(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "settlement", "capture":
+		// Di sandbox, biasanya langsung settlement tanpa fraud_status
+		if fraudStatus == "" || fraudStatus == "accept" {
+			return models.PaymentStatusPaid
+		}
+		if fraudStatus == "challenge" {
+			return models.PaymentStatusChallenge
+		}
+		return models.PaymentStatusFailed
+	case "deny", "failure":
+		return models.PaymentStatusFailed
+	case "expire":
+		return models.PaymentStatusExpired
+	case "cancel":
+		return models.PaymentStatusCancelled
+	case "refund", "partial_refund":
+		return models.PaymentStatusRefunded
+	default:
+		return models.PaymentStatusPending
+	}
+}
@@ -12,6 +12,15 @@ type UserResponse struct {
 	CreatedAt string  `json:"created_at"`
 	UpdatedAt string  `json:"updated_at"`
 	Token     *string `json:"token,omitempty"`
+
+	// Diisi saat login
+	TokenExpiresAt   *string  `json:"token_expires_at,omitempty"`
+	RefreshToken     *string  `json:"refresh_token,omitempty"`
+	RefreshExpiresAt *string  `json:"refresh_expires_at,omitempty"`
+	Permissions      []string `json:"permissions,omitempty"`
+
+	// Role wajib 2FA tetapi user belum mengaktifkannya (permission role ditahan)
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
 }
 
 // Struct ini digunakan untuk menerima data saat proses create user
@@ -32,6 +41,42 @@ type UserUpdateRequest struct {
 
 // Struct ini digunakan saat user melakukan proses login
 type UserLoginRequest struct {
-	Username string `json:"username" binding:"required"`
+	Username   string `json:"username" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name"` // Opsional, ditampilkan di daftar sesi
+}
+
+// Struct ini digunakan untuk memperbarui access token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Struct ini digunakan untuk meminta link reset password
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// Struct ini digunakan untuk mengganti password dengan token dari email
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// Struct ini digunakan untuk tahap kedua login (kode TOTP atau recovery code)
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"two_factor_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+	DeviceName     string `json:"device_name"`
+}
+
+// Struct ini digunakan untuk mengaktifkan 2FA dan membuat ulang recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Struct ini digunakan untuk menonaktifkan 2FA
+type TwoFactorDisableRequest struct {
 	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // Kode TOTP atau recovery code
 }